@@ -0,0 +1,529 @@
+package appointments
+
+import (
+	"acme/audit"
+	"acme/events"
+	"acme/notifications"
+	"acme/resources"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	defaultAbsenceSearchDays = 7
+	maxAbsenceSearchDays     = 31
+)
+
+// HandleAbsence clears an absent specialist's pending and confirmed
+// appointments. Each one is handed to a free qualified colleague at the
+// same time if possible, otherwise moved to the first free slot found from
+// its own date up to SearchDays past the absence. A dry run only returns
+// the plan; otherwise the plan is worked out again and, if it still matches
+// the previewed PlanHash, applied in one transaction. Every change is
+// audited and the client is told where their appointment went.
+func (s *AppointmentService) HandleAbsence(employeeID string, req AbsenceRequest, dryRun bool) (*AbsencePlan, error) {
+	if _, err := s.employeeService.GetEmployeeByID(employeeID); err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format, use YYYY-MM-DD: %w", err)
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date must be after start date")
+	}
+
+	searchDays := defaultAbsenceSearchDays
+	if req.SearchDays != nil {
+		searchDays = *req.SearchDays
+		if searchDays < 0 || searchDays > maxAbsenceSearchDays {
+			return nil, fmt.Errorf("search_days must be between 0 and %d", maxAbsenceSearchDays)
+		}
+	}
+
+	affected, err := s.repo.GetMovableAppointments(employeeID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.GetEmployeeSessions(employeeID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &absencePlanner{
+		service:     s,
+		absentID:    employeeID,
+		startDate:   startDate,
+		endDate:     endDate,
+		searchUntil: endDate.AddDate(0, 0, searchDays),
+		now:         s.clock.Now(),
+		names:       make(map[string]string),
+		qualified:   make(map[string][]string),
+		shifts:      make(map[string]map[time.Weekday]agendaShift),
+		claimed:     make(map[string][]busySegment),
+		held:        make(map[string][]resourceBooking),
+	}
+
+	plan := &AbsencePlan{
+		EmployeeID:        employeeID,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		DryRun:            dryRun,
+		Outcomes:          []AbsenceOutcome{},
+		UnhandledSessions: sessions,
+	}
+
+	var moves []rescheduleMove
+	for i := range affected {
+		appointment := &affected[i]
+
+		outcome, move, err := planner.plan(appointment)
+		if err != nil {
+			return nil, err
+		}
+		plan.Outcomes = append(plan.Outcomes, outcome)
+
+		if move != nil {
+			move.Record.RequestedBy = req.RequestedBy
+			move.Record.RequestedByType = string(CancelledByEmployee)
+			move.Record.Reason = req.Reason
+			moves = append(moves, *move)
+		}
+	}
+
+	plan.PlanHash, err = absencePlanHash(plan.Outcomes)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return plan, nil
+	}
+
+	if req.PlanHash == "" {
+		return nil, fmt.Errorf("plan_hash from the preview is required to apply an absence plan")
+	}
+
+	if req.PlanHash != plan.PlanHash {
+		return plan, fmt.Errorf("the plan has changed since the preview, review the new plan and apply it again")
+	}
+
+	if len(moves) == 0 {
+		return plan, nil
+	}
+
+	if err := s.repo.RescheduleAppointments(moves); err != nil {
+		return plan, fmt.Errorf("the plan could not be applied, nothing was changed: %w", err)
+	}
+
+	for i := range plan.Outcomes {
+		if plan.Outcomes[i].Action != string(AbsenceUnresolved) {
+			plan.Outcomes[i].Applied = true
+		}
+	}
+
+	for _, move := range moves {
+		s.auditAbsenceMove(move.Record)
+		s.publishByID(events.AppointmentUpdated, move.Record.AppointmentID, &Appointment{
+			AppointmentDate: move.Record.FromDate,
+			AttendedBy:      move.Record.FromAttendedBy,
+		})
+	}
+
+	// Outcomes follow the order of the affected appointments
+	for i, outcome := range plan.Outcomes {
+		if outcome.Applied {
+			s.notifyAbsenceMove(affected[i].ClientID, outcome)
+		}
+	}
+
+	return plan, nil
+}
+
+// absencePlanHash fingerprints the outcomes of a plan.
+func absencePlanHash(outcomes []AbsenceOutcome) (string, error) {
+	data, err := json.Marshal(outcomes)
+	if err != nil {
+		return "", fmt.Errorf("error hashing absence plan: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// notifyAbsenceMove tells the client their appointment was handed to a
+// colleague or moved because the specialist is absent.
+func (s *AppointmentService) notifyAbsenceMove(clientID string, outcome AbsenceOutcome) {
+	when := fmt.Sprintf("%s at %s", outcome.FromDate.Format("2006-01-02"), outcome.FromStartTime[:5])
+
+	message := fmt.Sprintf("Your specialist is unavailable, so your %s appointment on %s will be attended by %s.",
+		outcome.ServiceName, when, *outcome.ToEmployeeName)
+	if outcome.Action == string(AbsenceMove) {
+		message = fmt.Sprintf("Your specialist is unavailable, so your %s appointment on %s has been moved to %s at %s with %s.",
+			outcome.ServiceName, when, outcome.ToDate.Format("2006-01-02"), (*outcome.ToStartTime)[:5], *outcome.ToEmployeeName)
+	}
+
+	appointmentID := outcome.AppointmentID
+	_, err := s.notificationService.Notify(notifications.NotifyRequest{
+		ClientID:      clientID,
+		AppointmentID: &appointmentID,
+		Kind:          notifications.KindAppointmentMoved,
+		Subject:       "Your appointment has changed",
+		Message:       message,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to notify client of absence change for appointment %s: %v", outcome.AppointmentID, err)
+	}
+}
+
+func (s *AppointmentService) auditAbsenceMove(record *RescheduleRecord) {
+	auditReq := audit.CreateAuditLogRequest{
+		TableName: "appointments",
+		RecordID:  record.AppointmentID,
+		Action:    audit.ActionUpdate,
+		OldValues: map[string]interface{}{
+			"appointment_date": record.FromDate,
+			"start_time":       record.FromStartTime,
+			"attended_by":      record.FromAttendedBy,
+		},
+		NewValues: map[string]interface{}{
+			"appointment_date":  record.ToDate,
+			"start_time":        record.ToStartTime,
+			"attended_by":       record.ToAttendedBy,
+			"specialist_absent": true,
+		},
+		ChangedBy:     record.RequestedBy,
+		ChangedByType: audit.ChangedByEmployee,
+		Reason:        record.Reason,
+	}
+	if err := s.auditService.LogAction(auditReq); err != nil {
+		log.Printf("Warning: Failed to log absence reschedule for appointment %s: %v", record.AppointmentID, err)
+	}
+}
+
+// absencePlanner remembers the slots it has already promised, so two
+// appointments are never planned into the same specialist's time or past a
+// resource's capacity.
+type absencePlanner struct {
+	service     *AppointmentService
+	absentID    string
+	startDate   time.Time
+	endDate     time.Time
+	searchUntil time.Time
+	now         time.Time
+
+	names     map[string]string                       // employee id to name
+	qualified map[string][]string                     // service id to employee ids
+	shifts    map[string]map[time.Weekday]agendaShift // employee id to weekly shifts
+	claimed   map[string][]busySegment                // date|employee to planned slots
+	held      map[string][]resourceBooking            // date|resource to planned use
+}
+
+func (p *absencePlanner) plan(appointment *AppointmentWithDetails) (AbsenceOutcome, *rescheduleMove, error) {
+	outcome := AbsenceOutcome{
+		AppointmentID: appointment.ID,
+		ClientName:    appointment.ClientName,
+		ServiceName:   appointment.ServiceName,
+		FromDate:      appointment.AppointmentDate,
+		FromStartTime: appointment.StartTime[:5],
+		Action:        string(AbsenceUnresolved),
+	}
+
+	from, err := appointmentRange(appointment.StartTime, appointment.EndTime)
+	if err != nil {
+		return outcome, nil, err
+	}
+
+	startsAt, err := p.service.instant(appointment.AppointmentDate, appointment.StartTime)
+	if err != nil {
+		return outcome, nil, err
+	}
+	if !startsAt.After(p.now) {
+		outcome.Reason = "the appointment has already started"
+		return outcome, nil, nil
+	}
+
+	employeeIDs, err := p.qualifiedFor(appointment.ServiceID)
+	if err != nil {
+		return outcome, nil, err
+	}
+
+	requirements, err := p.service.resourceService.GetServiceRequirements(appointment.ServiceID)
+	if err != nil {
+		return outcome, nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	rule, err := p.service.repo.GetConcurrencyRule(appointment.ServiceID)
+	if err != nil {
+		return outcome, nil, err
+	}
+
+	date, slot, attendedBy, err := p.reassign(appointment, rule, from, employeeIDs)
+	if err != nil {
+		return outcome, nil, err
+	}
+	outcome.Action = string(AbsenceReassign)
+
+	if attendedBy == "" {
+		date, slot, attendedBy, err = p.findSlot(appointment, rule, from.End-from.Start, employeeIDs, requirements)
+		if err != nil {
+			return outcome, nil, err
+		}
+		outcome.Action = string(AbsenceMove)
+	}
+
+	if attendedBy == "" {
+		outcome.Action = string(AbsenceUnresolved)
+		outcome.Reason = "no qualified colleague or free slot was found"
+		return outcome, nil, nil
+	}
+
+	p.claim(date, attendedBy, rule.segments(slot))
+	if outcome.Action == string(AbsenceMove) {
+		for _, requirement := range requirements {
+			key := date.Format("2006-01-02") + "|" + requirement.ResourceID
+			p.held[key] = append(p.held[key], resourceBooking{timeRange: slot, Quantity: requirement.Quantity})
+		}
+	}
+
+	startTime := formatClock(slot.Start)
+	name := p.names[attendedBy]
+	outcome.ToDate = &date
+	outcome.ToStartTime = &startTime
+	outcome.ToAttendedBy = &attendedBy
+	outcome.ToEmployeeName = &name
+
+	moved := appointment.Appointment
+	moved.AppointmentDate = date
+	moved.StartTime = startTime
+	moved.EndTime = formatClock(slot.End)
+	moved.AttendedBy = &attendedBy
+	if err := p.service.setInstants(&moved); err != nil {
+		return outcome, nil, err
+	}
+
+	move := &rescheduleMove{
+		Appointment:  &moved,
+		Requirements: requirements,
+		Record: &RescheduleRecord{
+			AppointmentID:  appointment.ID,
+			FromDate:       appointment.AppointmentDate,
+			FromStartTime:  appointment.StartTime,
+			FromAttendedBy: appointment.AttendedBy,
+			ToDate:         moved.AppointmentDate,
+			ToStartTime:    moved.StartTime,
+			ToAttendedBy:   moved.AttendedBy,
+		},
+	}
+
+	return outcome, move, nil
+}
+
+// reassign looks for a colleague who is on shift and free at the
+// appointment's own slot.
+func (p *absencePlanner) reassign(appointment *AppointmentWithDetails, rule concurrencyRule, slot timeRange, employeeIDs []string) (time.Time, timeRange, string, error) {
+	date := appointment.AppointmentDate
+
+	busy, err := p.service.repo.GetBusySegments(date, employeeIDs)
+	if err != nil {
+		return date, slot, "", fmt.Errorf("error checking availability: %w", err)
+	}
+
+	var free []string
+	for _, employeeID := range employeeIDs {
+		if employeeID == p.absentID {
+			continue
+		}
+
+		onShift, err := p.onShift(employeeID, date, slot)
+		if err != nil {
+			return date, slot, "", err
+		}
+		if onShift && p.available(date, employeeID, rule.segments(slot), busy[employeeID]) {
+			free = append(free, employeeID)
+		}
+	}
+
+	attendedBy, err := p.pick(free, date, appointment)
+	return date, slot, attendedBy, err
+}
+
+// findSlot walks the days from the appointment's date and returns the first
+// slot where the service's resources and a qualified specialist are free.
+// The absent specialist is only considered after the absence.
+func (p *absencePlanner) findSlot(appointment *AppointmentWithDetails, rule concurrencyRule, duration int, employeeIDs []string, requirements []resources.Requirement) (time.Time, timeRange, string, error) {
+	config := p.service.config.Scheduling
+
+	opening, err := parseClock(config.OpeningTime)
+	if err != nil {
+		return time.Time{}, timeRange{}, "", fmt.Errorf("invalid opening time configuration: %w", err)
+	}
+
+	closing, err := parseClock(config.ClosingTime)
+	if err != nil {
+		return time.Time{}, timeRange{}, "", fmt.Errorf("invalid closing time configuration: %w", err)
+	}
+
+	step := config.SlotIntervalMinutes
+	if step <= 0 {
+		step = duration
+	}
+
+	resourceIDs := make([]string, len(requirements))
+	for i, requirement := range requirements {
+		resourceIDs[i] = requirement.ResourceID
+	}
+
+	for date := appointment.AppointmentDate; !date.After(p.searchUntil); date = date.AddDate(0, 0, 1) {
+		absent := !date.Before(p.startDate) && !date.After(p.endDate)
+
+		var candidates []string
+		for _, employeeID := range employeeIDs {
+			if employeeID != p.absentID || !absent {
+				candidates = append(candidates, employeeID)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		busy, err := p.service.repo.GetBusySegments(date, candidates)
+		if err != nil {
+			return date, timeRange{}, "", err
+		}
+
+		bookings, err := p.service.repo.GetResourceBookings(date, resourceIDs)
+		if err != nil {
+			return date, timeRange{}, "", err
+		}
+
+		for start := opening; start+duration <= closing; start += step {
+			slot := timeRange{Start: start, End: start + duration}
+
+			startsAt, err := p.service.instant(date, formatClock(start))
+			if err != nil {
+				return date, slot, "", err
+			}
+			if !startsAt.After(p.now) {
+				continue
+			}
+
+			fits := true
+			for _, requirement := range requirements {
+				used := append(bookings[requirement.ResourceID], p.held[date.Format("2006-01-02")+"|"+requirement.ResourceID]...)
+				if peakUsage(slot, used)+requirement.Quantity > requirement.Capacity {
+					fits = false
+					break
+				}
+			}
+			if !fits {
+				continue
+			}
+
+			var free []string
+			for _, employeeID := range candidates {
+				onShift, err := p.onShift(employeeID, date, slot)
+				if err != nil {
+					return date, slot, "", err
+				}
+				if onShift && p.available(date, employeeID, rule.segments(slot), busy[employeeID]) {
+					free = append(free, employeeID)
+				}
+			}
+
+			attendedBy, err := p.pick(free, date, appointment)
+			if err != nil || attendedBy != "" {
+				return date, slot, attendedBy, err
+			}
+		}
+	}
+
+	return time.Time{}, timeRange{}, "", nil
+}
+
+// pick chooses among free specialists with the configured assignment
+// strategy.
+func (p *absencePlanner) pick(free []string, date time.Time, appointment *AppointmentWithDetails) (string, error) {
+	if len(free) == 0 {
+		return "", nil
+	}
+
+	candidates, err := p.service.repo.GetAssignmentCandidates(free, date, appointment.ServiceID, appointment.ClientID)
+	if err != nil {
+		return "", err
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	return p.service.assignment.Select(candidates), nil
+}
+
+func (p *absencePlanner) qualifiedFor(serviceID string) ([]string, error) {
+	if ids, ok := p.qualified[serviceID]; ok {
+		return ids, nil
+	}
+
+	qualified, err := p.service.employeeService.GetQualifiedEmployees(serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting qualified employees: %w", err)
+	}
+
+	ids := []string{}
+	for _, employee := range qualified {
+		ids = append(ids, employee.ID)
+		p.names[employee.ID] = employee.FullName()
+	}
+	p.qualified[serviceID] = ids
+
+	return ids, nil
+}
+
+// onShift reports whether the slot falls inside the specialist's shift on
+// that day and outside their breaks.
+func (p *absencePlanner) onShift(employeeID string, date time.Time, slot timeRange) (bool, error) {
+	shifts, ok := p.shifts[employeeID]
+	if !ok {
+		schedule, err := p.service.employeeService.GetWorkSchedule(employeeID)
+		if err != nil {
+			return false, err
+		}
+
+		shifts, err = p.service.weeklyShifts(schedule)
+		if err != nil {
+			return false, err
+		}
+		p.shifts[employeeID] = shifts
+	}
+
+	shift, ok := shifts[date.Weekday()]
+	return ok && shift.covers(slot), nil
+}
+
+// available reports whether the segments fit around the specialist's
+// bookings and the moves already planned for them.
+func (p *absencePlanner) available(date time.Time, employeeID string, segments, busy []busySegment) bool {
+	planned := p.claimed[claimKey(date, employeeID)]
+	return fits(segments, append(append([]busySegment{}, busy...), planned...))
+}
+
+func (p *absencePlanner) claim(date time.Time, employeeID string, segments []busySegment) {
+	key := claimKey(date, employeeID)
+	p.claimed[key] = append(p.claimed[key], segments...)
+}
+
+func claimKey(date time.Time, employeeID string) string {
+	return date.Format("2006-01-02") + "|" + employeeID
+}
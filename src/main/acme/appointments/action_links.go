@@ -0,0 +1,265 @@
+package appointments
+
+import (
+	"acme/audit"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// linkSecret returns the key action links are signed with. Without a
+// configured secret a random one is used, so links stop working on restart.
+func linkSecret(configured string) []byte {
+	if configured != "" {
+		return []byte(configured)
+	}
+
+	log.Printf("Warning: ACTION_LINK_SECRET is not set, confirm and cancel links will not survive a restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate an action link secret: %v", err)
+	}
+	return secret
+}
+
+// actionToken is the signed content of an action link:
+// appointment.action.expiry.id.signature.
+type actionToken struct {
+	ID            string
+	AppointmentID string
+	Action        LinkAction
+	ExpiresAt     time.Time
+}
+
+func (s *AppointmentService) signToken(t actionToken) string {
+	payload := strings.Join([]string{t.AppointmentID, string(t.Action), strconv.FormatInt(t.ExpiresAt.Unix(), 10), t.ID}, ".")
+	return payload + "." + s.tokenSignature(payload)
+}
+
+func (s *AppointmentService) tokenSignature(payload string) string {
+	mac := hmac.New(sha256.New, s.linkSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parseToken checks the token's signature and expiry. It does not check
+// whether the token has been used.
+func (s *AppointmentService) parseToken(token string) (*actionToken, error) {
+	invalid := fmt.Errorf("invalid or expired link")
+
+	cut := strings.LastIndex(token, ".")
+	if cut < 0 || !hmac.Equal([]byte(token[cut+1:]), []byte(s.tokenSignature(token[:cut]))) {
+		return nil, invalid
+	}
+
+	parts := strings.Split(token[:cut], ".")
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	t := &actionToken{
+		AppointmentID: parts[0],
+		Action:        LinkAction(parts[1]),
+		ExpiresAt:     time.Unix(expiresAt, 0),
+		ID:            parts[3],
+	}
+	if !s.clock.Now().Before(t.ExpiresAt) {
+		return nil, invalid
+	}
+
+	return t, nil
+}
+
+// CreateActionLinks issues a cancel link for a pending or confirmed
+// appointment, and a confirm link if it is still pending. The links expire
+// after ACTION_LINK_VALID_HOURS or when the appointment starts, whichever
+// comes first.
+func (s *AppointmentService) CreateActionLinks(appointmentID string) (*ActionLinks, error) {
+	appointment, err := s.repo.GetAppointmentByID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := AppointmentStatus(appointment.Status)
+	if status != StatusPending && status != StatusConfirmed {
+		return nil, fmt.Errorf("cannot create links for a %s appointment", appointment.Status)
+	}
+
+	now := s.clock.Now()
+	if !now.Before(appointment.StartsAt) {
+		return nil, fmt.Errorf("cannot create links for an appointment that has already started")
+	}
+
+	expiresAt := now.Add(time.Duration(s.config.ActionLinks.ValidHours) * time.Hour)
+	if appointment.StartsAt.Before(expiresAt) {
+		expiresAt = appointment.StartsAt
+	}
+	// Tokens carry whole seconds
+	expiresAt = expiresAt.Truncate(time.Second)
+
+	links := &ActionLinks{AppointmentID: appointmentID, ExpiresAt: expiresAt}
+
+	if status == StatusPending {
+		url, err := s.issueActionLink(appointmentID, LinkConfirm, expiresAt)
+		if err != nil {
+			return nil, err
+		}
+		links.ConfirmURL = &url
+	}
+
+	links.CancelURL, err = s.issueActionLink(appointmentID, LinkCancel, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+func (s *AppointmentService) issueActionLink(appointmentID string, action LinkAction, expiresAt time.Time) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("error generating action link: %w", err)
+	}
+
+	t := actionToken{
+		ID:            base64.RawURLEncoding.EncodeToString(id),
+		AppointmentID: appointmentID,
+		Action:        action,
+		ExpiresAt:     expiresAt,
+	}
+
+	if err := s.repo.CreateActionToken(t.ID, appointmentID, action, expiresAt); err != nil {
+		return "", err
+	}
+
+	return s.config.ActionLinks.BaseURL + s.signToken(t), nil
+}
+
+// PreviewActionLink describes a valid, unused link without using it, so
+// link previews in chat apps cannot confirm or cancel anything.
+func (s *AppointmentService) PreviewActionLink(token string) (*ActionLinkPreview, error) {
+	t, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := s.repo.IsActionTokenUsed(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, fmt.Errorf("link has already been used")
+	}
+
+	appointment, err := s.repo.GetAppointmentWithDetails(t.AppointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ActionLinkPreview{
+		Action:          string(t.Action),
+		ExpiresAt:       t.ExpiresAt,
+		AppointmentID:   appointment.ID,
+		ServiceName:     appointment.ServiceName,
+		AppointmentDate: appointment.AppointmentDate,
+		StartTime:       appointment.StartTime,
+		EmployeeName:    appointment.EmployeeName,
+		Status:          appointment.Status,
+	}, nil
+}
+
+// ConfirmByLink confirms the appointment of a confirm link.
+func (s *AppointmentService) ConfirmByLink(token, source string) (*Appointment, error) {
+	return s.useActionLink(token, LinkConfirm, "", source, func(appointment *AppointmentWithDetails) error {
+		_, err := s.TransitionStatus(appointment.ID, StatusConfirmed, StatusChangeRequest{
+			ChangedBy:     appointment.ClientDNI,
+			ChangedByType: string(audit.ChangedByClient),
+		}, nil)
+		return err
+	})
+}
+
+// CancelByLink cancels the appointment of a cancel link as the client, so
+// the service's cancellation policy applies.
+func (s *AppointmentService) CancelByLink(token, reason, source string) (*Appointment, error) {
+	return s.useActionLink(token, LinkCancel, reason, source, func(appointment *AppointmentWithDetails) error {
+		return s.CancelByClient(appointment.ID, appointment.ClientDNI, reason, nil)
+	})
+}
+
+// useActionLink marks the link as used and runs its action. If the action
+// fails, the link can be used again. Every attempt with a valid link is
+// recorded in the audit log.
+func (s *AppointmentService) useActionLink(token string, action LinkAction, reason, source string, run func(*AppointmentWithDetails) error) (*Appointment, error) {
+	t, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if t.Action != action {
+		return nil, fmt.Errorf("this link cannot be used to %s the appointment", action)
+	}
+
+	appointment, err := s.repo.GetAppointmentWithDetails(t.AppointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UseActionToken(t.ID, t.AppointmentID, action, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	runErr := run(appointment)
+	if runErr != nil {
+		if err := s.repo.ReleaseActionToken(t.ID); err != nil {
+			log.Printf("Warning: Failed to release action link %s: %v", t.ID, err)
+		}
+	}
+
+	s.auditActionLink(t, appointment, reason, source, runErr)
+
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	return s.repo.GetAppointmentByID(t.AppointmentID)
+}
+
+func (s *AppointmentService) auditActionLink(t *actionToken, appointment *AppointmentWithDetails, reason, source string, runErr error) {
+	values := map[string]interface{}{
+		"action_link": t.ID,
+		"action":      t.Action,
+		"source":      source,
+		"succeeded":   runErr == nil,
+	}
+	if runErr != nil {
+		values["error"] = runErr.Error()
+	}
+
+	auditReq := audit.CreateAuditLogRequest{
+		TableName:     "appointments",
+		RecordID:      t.AppointmentID,
+		Action:        audit.ActionUpdate,
+		OldValues:     map[string]interface{}{"status": appointment.Status},
+		NewValues:     values,
+		ChangedBy:     appointment.ClientDNI,
+		ChangedByType: audit.ChangedByClient,
+	}
+	if reason != "" {
+		auditReq.Reason = &reason
+	}
+
+	if err := s.auditService.LogAction(auditReq); err != nil {
+		log.Printf("Warning: Failed to log use of action link %s: %v", t.ID, err)
+	}
+}
@@ -0,0 +1,218 @@
+package appointments
+
+import (
+	"acme/employees"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GetDayAgenda returns the specialist's agenda for a date, today when none
+// is given.
+func (s *AppointmentService) GetDayAgenda(employeeID, date string) (*EmployeeAgenda, error) {
+	day, err := s.agendaDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildAgenda(employeeID, day, 1)
+}
+
+// GetWeekAgenda returns the specialist's agenda for the Monday to Sunday
+// week containing the date, this week when none is given.
+func (s *AppointmentService) GetWeekAgenda(employeeID, date string) (*EmployeeAgenda, error) {
+	day, err := s.agendaDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	return s.buildAgenda(employeeID, monday, 7)
+}
+
+func (s *AppointmentService) agendaDate(date string) (time.Time, error) {
+	if date == "" {
+		return s.today(), nil
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+	}
+
+	return day, nil
+}
+
+// buildAgenda reads the bookings live, so a cancelled appointment leaves the
+// agenda, and frees its time, as soon as it is cancelled.
+func (s *AppointmentService) buildAgenda(employeeID string, startDate time.Time, days int) (*EmployeeAgenda, error) {
+	employee, err := s.employeeService.GetEmployeeByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.employeeService.GetWorkSchedule(employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts, err := s.weeklyShifts(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	endDate := startDate.AddDate(0, 0, days-1)
+
+	appointments, err := s.repo.GetEmployeeAppointments(employeeID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.repo.GetEmployeeSessions(employeeID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	agenda := &EmployeeAgenda{
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.FullName(),
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Days:         make([]AgendaDay, days),
+	}
+
+	for i := range agenda.Days {
+		date := startDate.AddDate(0, 0, i)
+		day := AgendaDay{
+			Date:         date,
+			Breaks:       []employees.Break{},
+			Appointments: []AppointmentWithDetails{},
+			Sessions:     []Session{},
+			FreeGaps:     []AgendaInterval{},
+		}
+
+		var busy []timeRange
+		for _, appointment := range appointments {
+			if appointment.AppointmentDate.Equal(date) {
+				day.Appointments = append(day.Appointments, appointment)
+				interval, err := appointmentRange(appointment.StartTime, appointment.EndTime)
+				if err != nil {
+					return nil, err
+				}
+				busy = append(busy, interval)
+			}
+		}
+		for _, session := range sessions {
+			if session.SessionDate.Equal(date) {
+				day.Sessions = append(day.Sessions, session)
+				interval, err := appointmentRange(session.StartTime, session.EndTime)
+				if err != nil {
+					return nil, err
+				}
+				busy = append(busy, interval)
+			}
+		}
+
+		if shift, ok := shifts[date.Weekday()]; ok {
+			day.Shift = newAgendaInterval(shift.timeRange)
+			day.Breaks = append(day.Breaks, shift.Breaks...)
+			day.FreeGaps = freeGaps(shift.timeRange, append(busy, shift.breakRanges...))
+		}
+
+		agenda.Days[i] = day
+	}
+
+	return agenda, nil
+}
+
+// agendaShift is a shift with its times parsed.
+type agendaShift struct {
+	timeRange
+	Breaks      []employees.Break
+	breakRanges []timeRange
+}
+
+// covers reports whether the slot falls inside the shift and outside its
+// breaks.
+func (s agendaShift) covers(slot timeRange) bool {
+	return slot.Start >= s.Start && slot.End <= s.End && isFree(slot, s.breakRanges)
+}
+
+// weeklyShifts maps each working weekday to its shift. A specialist without
+// a schedule works the business opening hours every day.
+func (s *AppointmentService) weeklyShifts(schedule []employees.WorkDay) (map[time.Weekday]agendaShift, error) {
+	shifts := make(map[time.Weekday]agendaShift)
+
+	if len(schedule) == 0 {
+		opening, err := parseClock(s.config.Scheduling.OpeningTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opening time configuration: %w", err)
+		}
+		closing, err := parseClock(s.config.Scheduling.ClosingTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid closing time configuration: %w", err)
+		}
+
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			shifts[weekday] = agendaShift{timeRange: timeRange{Start: opening, End: closing}}
+		}
+		return shifts, nil
+	}
+
+	for _, day := range schedule {
+		interval, err := appointmentRange(day.StartTime, day.EndTime)
+		if err != nil {
+			return nil, err
+		}
+
+		shift := agendaShift{timeRange: interval, Breaks: day.Breaks}
+		for _, b := range day.Breaks {
+			breakRange, err := appointmentRange(b.StartTime, b.EndTime)
+			if err != nil {
+				return nil, err
+			}
+			shift.breakRanges = append(shift.breakRanges, breakRange)
+		}
+
+		shifts[time.Weekday(day.Weekday)] = shift
+	}
+
+	return shifts, nil
+}
+
+// freeGaps returns the parts of the shift not taken by any blocked range.
+func freeGaps(shift timeRange, blocked []timeRange) []AgendaInterval {
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Start < blocked[j].Start
+	})
+
+	gaps := []AgendaInterval{}
+	free := shift.Start
+	for _, b := range blocked {
+		if b.Start > free {
+			end := b.Start
+			if end > shift.End {
+				end = shift.End
+			}
+			if end > free {
+				gaps = append(gaps, *newAgendaInterval(timeRange{Start: free, End: end}))
+			}
+		}
+		if b.End > free {
+			free = b.End
+		}
+	}
+	if free < shift.End {
+		gaps = append(gaps, *newAgendaInterval(timeRange{Start: free, End: shift.End}))
+	}
+
+	return gaps
+}
+
+func newAgendaInterval(r timeRange) *AgendaInterval {
+	return &AgendaInterval{
+		StartTime: formatClock(r.Start),
+		EndTime:   formatClock(r.End),
+		Minutes:   r.End - r.Start,
+	}
+}
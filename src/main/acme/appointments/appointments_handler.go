@@ -1,11 +1,18 @@
 package appointments
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+
+	"acme/etag"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ChatbotKeyHeader carries the key the chatbot integration books with.
+const ChatbotKeyHeader = "X-Chatbot-Key"
+
 type AppointmentsHandler struct {
 	service *AppointmentService
 }
@@ -21,8 +28,10 @@ func NewAppointmentsHandler(service *AppointmentService) *AppointmentsHandler {
 // @Accept json
 // @Produce json
 // @Param appointment body CreateAppointmentRequest true "Appointment data"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
 // @Success 201 {object} Appointment
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
 // @Router /appointments [post]
 func (h *AppointmentsHandler) CreateAppointment(c *gin.Context) {
 	var req CreateAppointmentRequest
@@ -31,6 +40,12 @@ func (h *AppointmentsHandler) CreateAppointment(c *gin.Context) {
 		return
 	}
 
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
 	appointment, err := h.service.CreateAppointment(req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -40,6 +55,17 @@ func (h *AppointmentsHandler) CreateAppointment(c *gin.Context) {
 	c.JSON(http.StatusCreated, appointment)
 }
 
+// bookingChannel works out who is booking from the chatbot key header and
+// answers 401 when the key is wrong.
+func (h *AppointmentsHandler) bookingChannel(c *gin.Context) (string, bool) {
+	channel, err := h.service.CallerChannel(c.GetHeader(ChatbotKeyHeader))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return string(channel), true
+}
+
 func (h *AppointmentsHandler) GetAppointmentByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -53,7 +79,7 @@ func (h *AppointmentsHandler) GetAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, appointment)
+	etag.JSON(c, http.StatusOK, etag.Version(appointment.Version), appointment)
 }
 
 func (h *AppointmentsHandler) GetAppointmentWithDetails(c *gin.Context) {
@@ -69,29 +95,46 @@ func (h *AppointmentsHandler) GetAppointmentWithDetails(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, appointment)
+	etag.JSON(c, http.StatusOK, etag.Version(appointment.Version), appointment)
 }
 
-func (h *AppointmentsHandler) UpdateAppointment(c *gin.Context) {
-	id := c.Param("id")
-
-	var req UpdateAppointmentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+// ListAppointments godoc
+// @Summary List appointments
+// @Description List appointments matching every given filter, one page at a time. Pass next_cursor as cursor to get the following page.
+// @Tags appointments
+// @Produce json
+// @Param start_date query string false "From date (YYYY-MM-DD)"
+// @Param end_date query string false "To date (YYYY-MM-DD)"
+// @Param status query string false "Comma-separated statuses"
+// @Param employee_id query string false "Attending specialist"
+// @Param service_id query string false "Service"
+// @Param client_id query string false "Client"
+// @Param channel query string false "Booking channel: staff or chatbot"
+// @Param sort query string false "starts_at (default) or created_at, prefixed with - for descending"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size, 1 to 200 (default 50)"
+// @Param include query string false "Comma-separated: client, service, employee"
+// @Success 200 {object} AppointmentPage
+// @Failure 400 {object} map[string]interface{}
+// @Router /appointments [get]
+func (h *AppointmentsHandler) ListAppointments(c *gin.Context) {
+	var req ListAppointmentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	appointment, err := h.service.UpdateAppointment(id, req)
+	page, err := h.service.ListAppointments(req)
 	if err != nil {
-		if err.Error() == "appointment not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		if strings.HasPrefix(err.Error(), "error listing appointments") {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, appointment)
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *AppointmentsHandler) GetAppointmentsByDateRange(c *gin.Context) {
@@ -128,13 +171,14 @@ func (h *AppointmentsHandler) CheckAvailability(c *gin.Context) {
 	date := c.Query("date")
 	startTime := c.Query("start_time")
 	attendedBy := c.Query("attended_by")
+	serviceID := c.Query("service_id")
 
 	if date == "" || startTime == "" || attendedBy == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "date, start_time, and attended_by query parameters are required"})
 		return
 	}
 
-	available, err := h.service.CheckAvailability(date, startTime, attendedBy)
+	available, err := h.service.CheckAvailability(date, startTime, attendedBy, serviceID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -148,6 +192,186 @@ func (h *AppointmentsHandler) CheckAvailability(c *gin.Context) {
 	})
 }
 
+// FindAvailableSlots godoc
+// @Summary Find available slots for a service
+// @Description List start times on a date where a qualified specialist and all required resources are free, plus the group sessions of the service with their seat counts
+// @Tags appointments
+// @Produce json
+// @Param service_id query string true "Service ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Param attended_by query string false "Specialist ID"
+// @Success 200 {array} AvailabilitySlot
+// @Failure 400 {object} map[string]interface{}
+// @Router /appointments/slots [get]
+func (h *AppointmentsHandler) FindAvailableSlots(c *gin.Context) {
+	serviceID := c.Query("service_id")
+	date := c.Query("date")
+	attendedBy := c.Query("attended_by")
+
+	if serviceID == "" || date == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service_id and date query parameters are required"})
+		return
+	}
+
+	slots, err := h.service.FindAvailableSlots(serviceID, date, attendedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// CreateSeries godoc
+// @Summary Book a recurring appointment series
+// @Description Validate every occurrence of an RRULE and book them; conflicts are reported with alternative slots
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param series body CreateSeriesRequest true "Series data"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 201 {object} SeriesBookingResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} SeriesBookingResult
+// @Failure 401 {object} map[string]interface{}
+// @Router /appointments/series [post]
+func (h *AppointmentsHandler) CreateSeries(c *gin.Context) {
+	h.createSeries(c, false)
+}
+
+// PreviewSeries godoc
+// @Summary Preview a recurring appointment series
+// @Description Validate every occurrence of an RRULE without booking anything
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param series body CreateSeriesRequest true "Series data"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 200 {object} SeriesBookingResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /appointments/series/preview [post]
+func (h *AppointmentsHandler) PreviewSeries(c *gin.Context) {
+	h.createSeries(c, true)
+}
+
+func (h *AppointmentsHandler) createSeries(c *gin.Context, dryRun bool) {
+	var req CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
+	result, err := h.service.CreateSeries(req, dryRun)
+	if err != nil {
+		if result != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "occurrences": result.Occurrences})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// UpdateSeries godoc
+// @Summary Reschedule a series occurrence
+// @Description Reschedule this occurrence only, or this and all following occurrences, under the service policy
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param update body UpdateSeriesRequest true "Scope and changes"
+// @Success 200 {array} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/series [put]
+func (h *AppointmentsHandler) UpdateSeries(c *gin.Context) {
+	id := c.Param("id")
+
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req UpdateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointments, err := h.service.UpdateSeries(id, req, version)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointments)
+}
+
+// CancelSeries godoc
+// @Summary Cancel a series occurrence
+// @Description Cancel this occurrence only, or this and all following occurrences. "cancelled" lists the IDs of the occurrences cancelled, also when a later one fails
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param cancellation body CancelSeriesRequest true "Scope and cancellation data"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/cancel-series [put]
+func (h *AppointmentsHandler) CancelSeries(c *gin.Context) {
+	id := c.Param("id")
+
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CancelSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cancelled, err := h.service.CancelSeries(id, req, version)
+	if err != nil {
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error(), "cancelled": cancelled})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "cancelled": cancelled})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Appointments cancelled successfully", "cancelled": cancelled})
+}
+
 // CancelAppointment godoc
 // @Summary Cancel an appointment
 // @Description Cancel an appointment with reason and audit trail
@@ -155,21 +379,33 @@ func (h *AppointmentsHandler) CheckAvailability(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
 // @Param cancellation body CancelAppointmentRequest true "Cancellation data"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
 // @Router /appointments/{id}/cancel [put]
 func (h *AppointmentsHandler) CancelAppointment(c *gin.Context) {
 	id := c.Param("id")
 
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req CancelAppointmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.service.CancelAppointment(id, req)
+	err = h.service.CancelAppointment(id, req, version)
 	if err != nil {
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -184,13 +420,21 @@ func (h *AppointmentsHandler) CancelAppointment(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
 // @Param cancellation body map[string]string true "Client DNI and reason"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
 // @Router /appointments/{id}/cancel-by-client [put]
 func (h *AppointmentsHandler) CancelAppointmentByClient(c *gin.Context) {
 	id := c.Param("id")
 
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req struct {
 		ClientDNI string `json:"client_dni" binding:"required"`
 		Reason    string `json:"reason" binding:"required"`
@@ -201,8 +445,12 @@ func (h *AppointmentsHandler) CancelAppointmentByClient(c *gin.Context) {
 		return
 	}
 
-	err := h.service.CancelByClient(id, req.ClientDNI, req.Reason)
+	err = h.service.CancelByClient(id, req.ClientDNI, req.Reason, version)
 	if err != nil {
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -217,13 +465,21 @@ func (h *AppointmentsHandler) CancelAppointmentByClient(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
 // @Param cancellation body map[string]string true "Employee email and reason"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
 // @Router /appointments/{id}/cancel-by-employee [put]
 func (h *AppointmentsHandler) CancelAppointmentByEmployee(c *gin.Context) {
 	id := c.Param("id")
 
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req struct {
 		EmployeeEmail string `json:"employee_email" binding:"required"`
 		Reason        string `json:"reason" binding:"required"`
@@ -234,8 +490,12 @@ func (h *AppointmentsHandler) CancelAppointmentByEmployee(c *gin.Context) {
 		return
 	}
 
-	err := h.service.CancelByEmployee(id, req.EmployeeEmail, req.Reason)
+	err = h.service.CancelByEmployee(id, req.EmployeeEmail, req.Reason, version)
 	if err != nil {
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -243,3 +503,1097 @@ func (h *AppointmentsHandler) CancelAppointmentByEmployee(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Appointment cancelled by employee successfully"})
 }
 
+
+// ConfirmAppointment godoc
+// @Summary Confirm an appointment
+// @Description Move a pending appointment to confirmed
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param change body StatusChangeRequest false "Actor and reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/confirm [put]
+func (h *AppointmentsHandler) ConfirmAppointment(c *gin.Context) {
+	h.transitionStatus(c, StatusConfirmed)
+}
+
+// CheckInAppointment godoc
+// @Summary Check in a client
+// @Description Record the client's arrival on the day of the appointment
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param change body StatusChangeRequest true "Actor and reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/check-in [put]
+func (h *AppointmentsHandler) CheckInAppointment(c *gin.Context) {
+	h.transitionStatus(c, StatusCheckedIn)
+}
+
+// StartAppointment godoc
+// @Summary Start an appointment
+// @Description Mark a checked-in appointment as in progress
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param change body StatusChangeRequest true "Actor and reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/start [put]
+func (h *AppointmentsHandler) StartAppointment(c *gin.Context) {
+	h.transitionStatus(c, StatusInProgress)
+}
+
+// CompleteAppointment godoc
+// @Summary Complete an appointment
+// @Description Mark an attended appointment as completed, redeeming its package session if any
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param change body StatusChangeRequest true "Actor and reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/complete [put]
+func (h *AppointmentsHandler) CompleteAppointment(c *gin.Context) {
+	h.transitionStatus(c, StatusCompleted)
+}
+
+// MarkNoShow godoc
+// @Summary Mark an appointment as no-show
+// @Description Record that the client did not attend; requires an actor and a reason
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param change body StatusChangeRequest true "Actor and reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/no-show [put]
+func (h *AppointmentsHandler) MarkNoShow(c *gin.Context) {
+	h.transitionStatus(c, StatusNoShow)
+}
+
+func (h *AppointmentsHandler) transitionStatus(c *gin.Context, to AppointmentStatus) {
+	id := c.Param("id")
+
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req StatusChangeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	appointment, err := h.service.TransitionStatus(id, to, req, version)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "cannot change appointment status") || err.Error() == "appointment status changed, please retry" ||
+			err.Error() == "the client must sign the current consent form before the treatment starts" {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", etag.Version(appointment.Version))
+	c.JSON(http.StatusOK, appointment)
+}
+
+// GetStatusHistory godoc
+// @Summary Get appointment status history
+// @Description List every status change of an appointment with actor and reason
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {array} StatusHistory
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/{id}/history [get]
+func (h *AppointmentsHandler) GetStatusHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	history, err := h.service.GetStatusHistory(id)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetClientReliability godoc
+// @Summary Get a client's reliability
+// @Description Get the client's attendance score and the booking policies it triggers
+// @Tags clients
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} ClientReliability
+// @Failure 500 {object} map[string]interface{}
+// @Router /clients/{id}/reliability [get]
+func (h *AppointmentsHandler) GetClientReliability(c *gin.Context) {
+	clientID := c.Param("id")
+
+	reliability, err := h.service.GetClientReliability(clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reliability)
+}
+
+// GrantReliabilityOverride godoc
+// @Summary Override reliability policies
+// @Description Let staff lift the deposit and self-booking restrictions for a client
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param override body ReliabilityOverrideRequest true "Staff member and reason"
+// @Success 200 {object} ClientReliability
+// @Failure 400 {object} map[string]interface{}
+// @Router /clients/{id}/reliability/override [put]
+func (h *AppointmentsHandler) GrantReliabilityOverride(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req ReliabilityOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reliability, err := h.service.GrantReliabilityOverride(clientID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reliability)
+}
+
+func (h *AppointmentsHandler) RevokeReliabilityOverride(c *gin.Context) {
+	clientID := c.Param("id")
+
+	err := h.service.RevokeReliabilityOverride(clientID)
+	if err != nil {
+		if err.Error() == "override not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Override not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// RescheduleAppointment godoc
+// @Summary Reschedule an appointment
+// @Description Move an appointment to a new slot, subject to the service's notice window and reschedule limit for clients
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param If-Match header string false "ETag of the appointment"
+// @Param reschedule body RescheduleRequest true "New slot and requester"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
+// @Router /appointments/{id}/reschedule [post]
+func (h *AppointmentsHandler) RescheduleAppointment(c *gin.Context) {
+	id := c.Param("id")
+
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointment, err := h.service.RescheduleAppointment(id, req, version)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", etag.Version(appointment.Version))
+	c.JSON(http.StatusOK, appointment)
+}
+
+// GetReschedules godoc
+// @Summary Get reschedule history
+// @Description List the slots an appointment was moved from and to
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {array} RescheduleRecord
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/{id}/reschedules [get]
+func (h *AppointmentsHandler) GetReschedules(c *gin.Context) {
+	id := c.Param("id")
+
+	records, err := h.service.GetReschedules(id)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// GetReminders godoc
+// @Summary Get appointment reminders
+// @Description List the reminders and confirmation requests scheduled, sent or cancelled for an appointment
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {array} ReminderJob
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/{id}/reminders [get]
+func (h *AppointmentsHandler) GetReminders(c *gin.Context) {
+	id := c.Param("id")
+
+	reminders, err := h.service.GetReminders(id)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reminders)
+}
+
+// CreateWaitlistEntry godoc
+// @Summary Join the waitlist
+// @Description Register a client's interest in a service within a date window, optionally with a specialist and time bounds
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param entry body CreateWaitlistEntryRequest true "Waitlist entry"
+// @Success 201 {object} WaitlistEntry
+// @Failure 400 {object} map[string]interface{}
+// @Router /waitlist [post]
+func (h *AppointmentsHandler) CreateWaitlistEntry(c *gin.Context) {
+	var req CreateWaitlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.service.CreateWaitlistEntry(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// GetWaitlist godoc
+// @Summary List the waitlist
+// @Description List open waitlist entries in offer order
+// @Tags waitlist
+// @Produce json
+// @Param service_id query string false "Service ID"
+// @Param client_id query string false "Client ID"
+// @Success 200 {array} WaitlistEntry
+// @Router /waitlist [get]
+func (h *AppointmentsHandler) GetWaitlist(c *gin.Context) {
+	entries, err := h.service.GetWaitlist(c.Query("service_id"), c.Query("client_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetWaitlistEntry godoc
+// @Summary Get a waitlist entry
+// @Description Get a waitlist entry with the slot currently offered to it, if any
+// @Tags waitlist
+// @Produce json
+// @Param id path string true "Waitlist entry ID"
+// @Success 200 {object} WaitlistEntry
+// @Failure 404 {object} map[string]interface{}
+// @Router /waitlist/{id} [get]
+func (h *AppointmentsHandler) GetWaitlistEntry(c *gin.Context) {
+	id := c.Param("id")
+
+	entry, err := h.service.GetWaitlistEntry(id)
+	if err != nil {
+		if err.Error() == "waitlist entry not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+func (h *AppointmentsHandler) CancelWaitlistEntry(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.CancelWaitlistEntry(id)
+	if err != nil {
+		if err.Error() == "waitlist entry not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// AcceptWaitlistOffer godoc
+// @Summary Accept a waitlist offer
+// @Description Book the slot currently held for the waitlist entry
+// @Tags waitlist
+// @Accept json
+// @Produce json
+// @Param id path string true "Waitlist entry ID"
+// @Param offer body AcceptOfferRequest false "Package and booking channel"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 201 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /waitlist/{id}/accept [post]
+func (h *AppointmentsHandler) AcceptWaitlistOffer(c *gin.Context) {
+	id := c.Param("id")
+
+	var req AcceptOfferRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
+	appointment, err := h.service.AcceptOffer(id, req)
+	if err != nil {
+		switch err.Error() {
+		case "waitlist entry not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		case "no active offer for this waitlist entry", "hold has expired":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// DeclineWaitlistOffer godoc
+// @Summary Decline a waitlist offer
+// @Description Release the held slot to the next client; the entry stays on the waitlist
+// @Tags waitlist
+// @Produce json
+// @Param id path string true "Waitlist entry ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /waitlist/{id}/decline [post]
+func (h *AppointmentsHandler) DeclineWaitlistOffer(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.DeclineOffer(id)
+	if err != nil {
+		switch err.Error() {
+		case "waitlist entry not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Waitlist entry not found"})
+		case "no active offer for this waitlist entry", "hold is no longer active":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer declined successfully"})
+}
+
+// CreateHold godoc
+// @Summary Hold a slot
+// @Description Reserve a specialist interval for a few minutes while the client confirms; the hold counts as busy until it expires
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param hold body CreateHoldRequest true "Slot to hold"
+// @Success 201 {object} Hold
+// @Failure 400 {object} map[string]interface{}
+// @Router /appointments/holds [post]
+func (h *AppointmentsHandler) CreateHold(c *gin.Context) {
+	var req CreateHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, err := h.service.CreateHold(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// GetHold godoc
+// @Summary Get a hold
+// @Description Get a hold and its status
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} Hold
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/holds/{id} [get]
+func (h *AppointmentsHandler) GetHold(c *gin.Context) {
+	id := c.Param("id")
+
+	hold, err := h.service.GetHold(id)
+	if err != nil {
+		if err.Error() == "hold not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *AppointmentsHandler) ReleaseHold(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.ReleaseHold(id)
+	if err != nil {
+		switch err.Error() {
+		case "hold not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		case "hold is no longer active":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ConfirmHold godoc
+// @Summary Confirm a hold
+// @Description Book the held slot as an appointment
+// @Tags appointments
+// @Accept json
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Param confirmation body ConfirmHoldRequest false "Client, package and booking channel"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 201 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /appointments/holds/{id}/confirm [post]
+func (h *AppointmentsHandler) ConfirmHold(c *gin.Context) {
+	id := c.Param("id")
+
+	var req ConfirmHoldRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
+	appointment, err := h.service.ConfirmHold(id, req)
+	if err != nil {
+		switch err.Error() {
+		case "hold not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		case "hold is no longer active", "hold has expired":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// CreateSession godoc
+// @Summary Schedule a group session
+// @Description Book a specialist and the service's resources for a class that up to capacity clients can enroll in
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param session body CreateSessionRequest true "Session"
+// @Success 201 {object} Session
+// @Failure 400 {object} map[string]interface{}
+// @Router /sessions [post]
+func (h *AppointmentsHandler) CreateSession(c *gin.Context) {
+	var req CreateSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.CreateSession(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetSessions godoc
+// @Summary List group sessions
+// @Description List scheduled sessions with their seat counts
+// @Tags sessions
+// @Produce json
+// @Param service_id query string false "Service ID"
+// @Param date query string false "Date (YYYY-MM-DD)"
+// @Success 200 {array} Session
+// @Failure 400 {object} map[string]interface{}
+// @Router /sessions [get]
+func (h *AppointmentsHandler) GetSessions(c *gin.Context) {
+	sessions, err := h.service.GetSessions(c.Query("service_id"), c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// GetSession godoc
+// @Summary Get a group session
+// @Description Get a session with its seat counts
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} Session
+// @Failure 404 {object} map[string]interface{}
+// @Router /sessions/{id} [get]
+func (h *AppointmentsHandler) GetSession(c *gin.Context) {
+	id := c.Param("id")
+
+	session, err := h.service.GetSession(id)
+	if err != nil {
+		if err.Error() == "session not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// GetSessionRoster godoc
+// @Summary Get a session's roster
+// @Description List the clients enrolled in a session with their appointment status
+// @Tags sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} SessionRoster
+// @Failure 404 {object} map[string]interface{}
+// @Router /sessions/{id}/roster [get]
+func (h *AppointmentsHandler) GetSessionRoster(c *gin.Context) {
+	id := c.Param("id")
+
+	roster, err := h.service.GetSessionRoster(id)
+	if err != nil {
+		if err.Error() == "session not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, roster)
+}
+
+// EnrollInSession godoc
+// @Summary Enroll a client in a session
+// @Description Book a seat in a group session; the enrollment is an appointment linked to the session
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param enrollment body EnrollRequest true "Enrollment"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 201 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /sessions/{id}/enroll [post]
+func (h *AppointmentsHandler) EnrollInSession(c *gin.Context) {
+	id := c.Param("id")
+
+	var req EnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
+	appointment, err := h.service.EnrollInSession(id, req)
+	if err != nil {
+		switch err.Error() {
+		case "session not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case "session is full", "client is already enrolled in this session", "session has been cancelled", "session has already started":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appointment)
+}
+
+// CancelSession godoc
+// @Summary Cancel a group session
+// @Description Cancel a session and every enrollment in it, return package sessions and notify the enrolled clients
+// @Tags sessions
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param cancellation body CancelSessionRequest true "Cancellation"
+// @Success 200 {object} Session
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /sessions/{id}/cancel [put]
+func (h *AppointmentsHandler) CancelSession(c *gin.Context) {
+	id := c.Param("id")
+
+	var req CancelSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := h.service.CancelSession(id, req)
+	if err != nil {
+		switch err.Error() {
+		case "session not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case "session is already cancelled", "cannot cancel a session that has already started":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// CreateVisit godoc
+// @Summary Book a multi-service visit
+// @Description Book several services back to back for one client, each with its own specialist; if any line is unavailable nothing is booked
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param visit body CreateVisitRequest true "Visit"
+// @Param X-Chatbot-Key header string false "Chatbot integration key; books on the chatbot channel"
+// @Success 201 {object} Visit
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /visits [post]
+func (h *AppointmentsHandler) CreateVisit(c *gin.Context) {
+	var req CreateVisitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channel, ok := h.bookingChannel(c)
+	if !ok {
+		return
+	}
+	req.Channel = channel
+
+	visit, err := h.service.CreateVisit(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, visit)
+}
+
+// GetVisit godoc
+// @Summary Get a visit
+// @Description Get a visit with its lines in order and its total
+// @Tags visits
+// @Produce json
+// @Param id path string true "Visit ID"
+// @Success 200 {object} Visit
+// @Failure 404 {object} map[string]interface{}
+// @Router /visits/{id} [get]
+func (h *AppointmentsHandler) GetVisit(c *gin.Context) {
+	id := c.Param("id")
+
+	visit, err := h.service.GetVisit(id)
+	if err != nil {
+		if err.Error() == "visit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Visit not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, visit)
+}
+
+// CancelVisit godoc
+// @Summary Cancel a visit
+// @Description Cancel every line of the visit that has not started
+// @Tags visits
+// @Accept json
+// @Produce json
+// @Param id path string true "Visit ID"
+// @Param cancellation body CancelAppointmentRequest true "Cancellation data"
+// @Success 200 {object} Visit
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /visits/{id}/cancel [put]
+func (h *AppointmentsHandler) CancelVisit(c *gin.Context) {
+	id := c.Param("id")
+
+	var req CancelAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	visit, err := h.service.CancelVisit(id, req)
+	if err != nil {
+		if err.Error() == "visit not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Visit not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, visit)
+}
+
+// GetDayAgenda godoc
+// @Summary Get a specialist's day agenda
+// @Description Get the specialist's shift, breaks, booked appointments and sessions, and free gaps for a date
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param date query string false "Date (YYYY-MM-DD), today by default"
+// @Success 200 {object} EmployeeAgenda
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/agenda [get]
+func (h *AppointmentsHandler) GetDayAgenda(c *gin.Context) {
+	agenda, err := h.service.GetDayAgenda(c.Param("id"), c.Query("date"))
+	if err != nil {
+		h.agendaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, agenda)
+}
+
+// GetWeekAgenda godoc
+// @Summary Get a specialist's week agenda
+// @Description Get the day agenda for each day of the Monday to Sunday week containing the date
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param date query string false "Any date in the week (YYYY-MM-DD), today by default"
+// @Success 200 {object} EmployeeAgenda
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/agenda/week [get]
+func (h *AppointmentsHandler) GetWeekAgenda(c *gin.Context) {
+	agenda, err := h.service.GetWeekAgenda(c.Param("id"), c.Query("date"))
+	if err != nil {
+		h.agendaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, agenda)
+}
+
+func (h *AppointmentsHandler) agendaError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "employee not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+	case strings.HasPrefix(err.Error(), "invalid date format"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// PreviewAbsence godoc
+// @Summary Preview an absence plan
+// @Description Propose a colleague or a new slot for each of the specialist's appointments in the date range without changing anything
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param absence body AbsenceRequest true "Absence data"
+// @Success 200 {object} AbsencePlan
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/absence/preview [post]
+func (h *AppointmentsHandler) PreviewAbsence(c *gin.Context) {
+	h.handleAbsence(c, true)
+}
+
+// ApplyAbsence godoc
+// @Summary Apply an absence plan
+// @Description Reassign or move the specialist's appointments in the date range in one transaction and notify their clients; plan_hash must match the preview, and appointments without a solution are left untouched
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param absence body AbsenceRequest true "Absence data"
+// @Success 201 {object} AbsencePlan
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /employees/{id}/absence [post]
+func (h *AppointmentsHandler) ApplyAbsence(c *gin.Context) {
+	h.handleAbsence(c, false)
+}
+
+func (h *AppointmentsHandler) handleAbsence(c *gin.Context, dryRun bool) {
+	var req AbsenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.HandleAbsence(c.Param("id"), req, dryRun)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		if plan != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "outcomes": plan.Outcomes, "plan_hash": plan.PlanHash})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, plan)
+		return
+	}
+
+	c.JSON(http.StatusCreated, plan)
+}
+
+// CreateActionLinks godoc
+// @Summary Create confirm and cancel links
+// @Description Issue signed, single-use links the client can follow to confirm or cancel without logging in; confirmation requests and reminders include them
+// @Tags appointments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 201 {object} ActionLinks
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/{id}/action-links [post]
+func (h *AppointmentsHandler) CreateActionLinks(c *gin.Context) {
+	links, err := h.service.CreateActionLinks(c.Param("id"))
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, links)
+}
+
+// PreviewActionLink godoc
+// @Summary Preview an action link
+// @Description Describe the appointment and action of a link without using it
+// @Tags appointment-actions
+// @Produce json
+// @Param token path string true "Link token"
+// @Success 200 {object} ActionLinkPreview
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /appointment-actions/{token} [get]
+func (h *AppointmentsHandler) PreviewActionLink(c *gin.Context) {
+	preview, err := h.service.PreviewActionLink(c.Param("token"))
+	if err != nil {
+		h.actionLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// ConfirmByLink godoc
+// @Summary Confirm an appointment from a link
+// @Description Confirm the appointment of a confirm link; the link can only be used once
+// @Tags appointment-actions
+// @Produce json
+// @Param token path string true "Link token"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /appointment-actions/{token}/confirm [post]
+func (h *AppointmentsHandler) ConfirmByLink(c *gin.Context) {
+	appointment, err := h.service.ConfirmByLink(c.Param("token"), c.ClientIP())
+	if err != nil {
+		h.actionLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// CancelByLink godoc
+// @Summary Cancel an appointment from a link
+// @Description Cancel the appointment of a cancel link as the client, under the service's cancellation policy; the link can only be used once
+// @Tags appointment-actions
+// @Accept json
+// @Produce json
+// @Param token path string true "Link token"
+// @Param cancellation body CancelByLinkRequest true "Cancellation reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /appointment-actions/{token}/cancel [post]
+func (h *AppointmentsHandler) CancelByLink(c *gin.Context) {
+	var req CancelByLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appointment, err := h.service.CancelByLink(c.Param("token"), req.Reason, c.ClientIP())
+	if err != nil {
+		h.actionLinkError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+func (h *AppointmentsHandler) actionLinkError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "invalid or expired link":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid or expired link"})
+	case "appointment not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+	case "link has already been used":
+		c.JSON(http.StatusConflict, gin.H{"error": "Link has already been used"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
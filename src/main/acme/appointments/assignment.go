@@ -0,0 +1,107 @@
+package appointments
+
+import (
+	"fmt"
+	"time"
+)
+
+// AssignmentCandidate is a qualified, free specialist together with the
+// statistics the assignment strategies rank on.
+type AssignmentCandidate struct {
+	EmployeeID     string
+	DailyLoad      int
+	LastAssignedAt *time.Time
+	ClientVisits   int
+}
+
+// AssignmentStrategy picks the specialist for an appointment booked without
+// one. Candidates are never empty and keep a stable order between calls.
+type AssignmentStrategy interface {
+	Name() string
+	Select(candidates []AssignmentCandidate) string
+}
+
+const (
+	StrategyLeastLoaded = "least_loaded"
+	StrategyRoundRobin  = "round_robin"
+	StrategyPreferred   = "preferred"
+)
+
+func NewAssignmentStrategy(name string) (AssignmentStrategy, error) {
+	switch name {
+	case "", StrategyLeastLoaded:
+		return leastLoadedStrategy{}, nil
+	case StrategyRoundRobin:
+		return roundRobinStrategy{}, nil
+	case StrategyPreferred:
+		return preferredStrategy{fallback: leastLoadedStrategy{}}, nil
+	}
+	return nil, fmt.Errorf("unknown assignment strategy: %s", name)
+}
+
+// leastLoadedStrategy picks the specialist with the fewest bookings that day,
+// breaking ties by who was assigned this service longest ago.
+type leastLoadedStrategy struct{}
+
+func (leastLoadedStrategy) Name() string { return StrategyLeastLoaded }
+
+func (leastLoadedStrategy) Select(candidates []AssignmentCandidate) string {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.DailyLoad < best.DailyLoad ||
+			(c.DailyLoad == best.DailyLoad && assignedBefore(c, best)) {
+			best = c
+		}
+	}
+	return best.EmployeeID
+}
+
+// roundRobinStrategy rotates through specialists by picking whoever was
+// assigned this service longest ago. It is derived from stored appointments,
+// so the rotation survives restarts.
+type roundRobinStrategy struct{}
+
+func (roundRobinStrategy) Name() string { return StrategyRoundRobin }
+
+func (roundRobinStrategy) Select(candidates []AssignmentCandidate) string {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if assignedBefore(c, best) {
+			best = c
+		}
+	}
+	return best.EmployeeID
+}
+
+// preferredStrategy picks the specialist the client has visited most and
+// falls back to another strategy for clients without history.
+type preferredStrategy struct {
+	fallback AssignmentStrategy
+}
+
+func (preferredStrategy) Name() string { return StrategyPreferred }
+
+func (p preferredStrategy) Select(candidates []AssignmentCandidate) string {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.ClientVisits > best.ClientVisits {
+			best = c
+		}
+	}
+	if best.ClientVisits == 0 {
+		return p.fallback.Select(candidates)
+	}
+	return best.EmployeeID
+}
+
+// assignedBefore reports whether a was last assigned earlier than b, treating
+// never-assigned specialists as the earliest.
+func assignedBefore(a, b AssignmentCandidate) bool {
+	if a.LastAssignedAt == nil {
+		return b.LastAssignedAt != nil
+	}
+	if b.LastAssignedAt == nil {
+		return false
+	}
+	return a.LastAssignedAt.Before(*b.LastAssignedAt)
+}
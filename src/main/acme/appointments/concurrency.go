@@ -0,0 +1,87 @@
+package appointments
+
+// Values of services.concurrency_mode; see the catalog package.
+const (
+	concurrencyExclusive  = "exclusive"
+	concurrencyParallel   = "parallel"
+	concurrencyProcessing = "processing"
+)
+
+// concurrencyRule says how a service occupies its specialist.
+type concurrencyRule struct {
+	Mode            string
+	MaxConcurrent   int
+	ProcessingStart int // minutes after the start the gap begins
+	ProcessingTime  int // length of the gap in minutes
+}
+
+// exclusiveRule applies to group sessions and to checks made without a
+// service.
+var exclusiveRule = concurrencyRule{Mode: concurrencyExclusive, MaxConcurrent: 1}
+
+// busySegment is a stretch of time a specialist is attending a booking.
+// Limit is how many bookings may be attended at once while it lasts.
+type busySegment struct {
+	timeRange
+	Limit int
+}
+
+// segments returns the parts of slot the specialist is attending. A
+// processing gap is left out; if the booking is shorter than the service,
+// the gap is clipped to it.
+func (r concurrencyRule) segments(slot timeRange) []busySegment {
+	switch r.Mode {
+	case concurrencyParallel:
+		limit := r.MaxConcurrent
+		if limit < 1 {
+			limit = 1
+		}
+		return []busySegment{{timeRange: slot, Limit: limit}}
+	case concurrencyProcessing:
+		gap := timeRange{Start: slot.Start + r.ProcessingStart, End: slot.Start + r.ProcessingStart + r.ProcessingTime}
+		if r.ProcessingTime <= 0 || !slot.overlaps(gap) {
+			break
+		}
+		var segments []busySegment
+		if gap.Start > slot.Start {
+			segments = append(segments, busySegment{timeRange: timeRange{Start: slot.Start, End: gap.Start}, Limit: 1})
+		}
+		if gap.End < slot.End {
+			segments = append(segments, busySegment{timeRange: timeRange{Start: gap.End, End: slot.End}, Limit: 1})
+		}
+		return segments
+	}
+	return []busySegment{{timeRange: slot, Limit: 1}}
+}
+
+// fits reports whether a booking attended over candidate can be added to
+// the specialist's busy segments. At every instant the number of bookings
+// attended, the new one included, must not exceed the lowest limit among
+// them. That number only grows where a segment starts, so checking the
+// start of each candidate segment and every busy start inside it is enough.
+func fits(candidate []busySegment, busy []busySegment) bool {
+	for _, c := range candidate {
+		points := []int{c.Start}
+		for _, b := range busy {
+			if b.Start > c.Start && b.Start < c.End {
+				points = append(points, b.Start)
+			}
+		}
+
+		for _, p := range points {
+			count, limit := 1, c.Limit
+			for _, b := range busy {
+				if b.Start <= p && p < b.End {
+					count++
+					if b.Limit < limit {
+						limit = b.Limit
+					}
+				}
+			}
+			if count > limit {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package appointments
+
+import (
+	"reflect"
+	"testing"
+)
+
+// span builds a time range from "HH:MM" clock times.
+func span(t *testing.T, start, end string) timeRange {
+	t.Helper()
+	r, err := appointmentRange(start, end)
+	if err != nil {
+		t.Fatalf("appointmentRange(%q, %q) failed: %v", start, end, err)
+	}
+	return r
+}
+
+func TestConcurrencyRuleSegments(t *testing.T) {
+	processing := concurrencyRule{Mode: concurrencyProcessing, MaxConcurrent: 1, ProcessingStart: 15, ProcessingTime: 30}
+
+	tests := []struct {
+		name string
+		rule concurrencyRule
+		slot timeRange
+		want []busySegment
+	}{
+		{
+			name: "exclusive",
+			rule: exclusiveRule,
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{{timeRange: span(t, "10:00", "11:00"), Limit: 1}},
+		},
+		{
+			name: "parallel",
+			rule: concurrencyRule{Mode: concurrencyParallel, MaxConcurrent: 3},
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{{timeRange: span(t, "10:00", "11:00"), Limit: 3}},
+		},
+		{
+			name: "parallel without a limit",
+			rule: concurrencyRule{Mode: concurrencyParallel},
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{{timeRange: span(t, "10:00", "11:00"), Limit: 1}},
+		},
+		{
+			name: "processing gap in the middle",
+			rule: processing,
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{
+				{timeRange: span(t, "10:00", "10:15"), Limit: 1},
+				{timeRange: span(t, "10:45", "11:00"), Limit: 1},
+			},
+		},
+		{
+			name: "processing gap at the start",
+			rule: concurrencyRule{Mode: concurrencyProcessing, ProcessingTime: 20},
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{{timeRange: span(t, "10:20", "11:00"), Limit: 1}},
+		},
+		{
+			name: "processing gap clipped by a shorter booking",
+			rule: processing,
+			slot: span(t, "10:00", "10:30"),
+			want: []busySegment{{timeRange: span(t, "10:00", "10:15"), Limit: 1}},
+		},
+		{
+			name: "processing gap after a shorter booking",
+			rule: concurrencyRule{Mode: concurrencyProcessing, ProcessingStart: 45, ProcessingTime: 10},
+			slot: span(t, "10:00", "10:30"),
+			want: []busySegment{{timeRange: span(t, "10:00", "10:30"), Limit: 1}},
+		},
+		{
+			name: "processing without a gap",
+			rule: concurrencyRule{Mode: concurrencyProcessing, ProcessingStart: 15},
+			slot: span(t, "10:00", "11:00"),
+			want: []busySegment{{timeRange: span(t, "10:00", "11:00"), Limit: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.segments(tt.slot); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("segments() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFits(t *testing.T) {
+	parallel := concurrencyRule{Mode: concurrencyParallel, MaxConcurrent: 2}
+	processing := concurrencyRule{Mode: concurrencyProcessing, ProcessingStart: 15, ProcessingTime: 30}
+
+	tests := []struct {
+		name      string
+		candidate []busySegment
+		busy      []busySegment
+		want      bool
+	}{
+		{
+			name:      "free specialist",
+			candidate: exclusiveRule.segments(span(t, "10:00", "11:00")),
+			want:      true,
+		},
+		{
+			name:      "exclusive overlap",
+			candidate: exclusiveRule.segments(span(t, "10:00", "11:00")),
+			busy:      exclusiveRule.segments(span(t, "10:30", "11:30")),
+			want:      false,
+		},
+		{
+			name:      "back to back",
+			candidate: exclusiveRule.segments(span(t, "10:00", "11:00")),
+			busy:      exclusiveRule.segments(span(t, "11:00", "12:00")),
+			want:      true,
+		},
+		{
+			name:      "second parallel booking",
+			candidate: parallel.segments(span(t, "10:00", "11:00")),
+			busy:      parallel.segments(span(t, "10:00", "11:00")),
+			want:      true,
+		},
+		{
+			name:      "third parallel booking",
+			candidate: parallel.segments(span(t, "10:00", "11:00")),
+			busy:      append(parallel.segments(span(t, "10:00", "11:00")), parallel.segments(span(t, "10:30", "11:30"))...),
+			want:      false,
+		},
+		{
+			name:      "parallel next to an exclusive booking",
+			candidate: parallel.segments(span(t, "10:00", "11:00")),
+			busy:      exclusiveRule.segments(span(t, "10:30", "11:30")),
+			want:      false,
+		},
+		{
+			name:      "inside a processing gap",
+			candidate: exclusiveRule.segments(span(t, "10:15", "10:45")),
+			busy:      processing.segments(span(t, "10:00", "11:00")),
+			want:      true,
+		},
+		{
+			name:      "past the processing gap",
+			candidate: exclusiveRule.segments(span(t, "10:15", "10:50")),
+			busy:      processing.segments(span(t, "10:00", "11:00")),
+			want:      false,
+		},
+		{
+			name:      "busy booking starting inside the candidate",
+			candidate: exclusiveRule.segments(span(t, "10:00", "12:00")),
+			busy:      exclusiveRule.segments(span(t, "11:00", "11:15")),
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fits(tt.candidate, tt.busy); got != tt.want {
+				t.Errorf("fits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
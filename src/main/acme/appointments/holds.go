@@ -0,0 +1,200 @@
+package appointments
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maxHoldMinutes bounds how long a caller may hold a slot.
+const maxHoldMinutes = 60
+
+// holdSweepInterval is how often expired holds are released and waitlist
+// offers passed on to the next client.
+const holdSweepInterval = time.Minute
+
+// CreateHold reserves a specialist interval for a few minutes, e.g. while
+// the chatbot waits for the client to confirm the proposed slot.
+func (s *AppointmentService) CreateHold(req CreateHoldRequest) (*Hold, error) {
+	appointmentDate, err := time.Parse("2006-01-02", req.AppointmentDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if appointmentDate.Before(s.today()) {
+		return nil, fmt.Errorf("cannot hold slots on past dates")
+	}
+
+	if !s.isValidTimeFormat(req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = s.config.Scheduling.HoldMinutes
+	}
+	if minutes <= 0 || minutes > maxHoldMinutes {
+		return nil, fmt.Errorf("minutes must be between 1 and %d", maxHoldMinutes)
+	}
+
+	duration, err := s.repo.GetServiceDuration(req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := s.calculateEndTime(req.StartTime, duration)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating end time: %w", err)
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(req.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	if err := s.repo.CheckResourceCapacity(appointmentDate, req.StartTime, endTime, requirements); err != nil {
+		return nil, err
+	}
+
+	clientID := ""
+	if req.ClientID != nil {
+		clientID = *req.ClientID
+	}
+
+	attendedBy := req.AttendedBy
+	if attendedBy == "" {
+		attendedBy, err = s.assignSpecialist(req.ServiceID, clientID, appointmentDate, req.StartTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := s.ensureQualified(attendedBy, req.ServiceID); err != nil {
+		return nil, err
+	}
+
+	hold := &Hold{
+		ServiceID:       req.ServiceID,
+		ClientID:        req.ClientID,
+		AttendedBy:      attendedBy,
+		AppointmentDate: appointmentDate,
+		StartTime:       req.StartTime,
+		EndTime:         endTime,
+	}
+
+	if err := s.repo.CreateHold(hold, minutes, requirements); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (s *AppointmentService) GetHold(id string) (*Hold, error) {
+	return s.repo.GetHoldByID(id)
+}
+
+// ReleaseHold frees a hold before it expires. Releasing a waitlist offer
+// passes it on to the next client.
+func (s *AppointmentService) ReleaseHold(id string) error {
+	hold, err := s.repo.GetHoldByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.ReleaseHold(id); err != nil {
+		return err
+	}
+
+	if hold.WaitlistEntryID != nil {
+		s.passOffer(hold)
+	}
+
+	return nil
+}
+
+// ConfirmHold books the held slot as an appointment.
+func (s *AppointmentService) ConfirmHold(id string, req ConfirmHoldRequest) (*Appointment, error) {
+	hold, err := s.repo.GetHoldByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := req.ClientID
+	if hold.ClientID != nil {
+		if clientID != "" && clientID != *hold.ClientID {
+			return nil, fmt.Errorf("the hold belongs to another client")
+		}
+		clientID = *hold.ClientID
+	}
+
+	if clientID == "" {
+		return nil, fmt.Errorf("client_id is required to confirm this hold")
+	}
+
+	appointment, err := s.bookHold(hold, clientID, req.ClientPackageID, req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.WaitlistEntryID != nil {
+		if err := s.repo.SetWaitlistStatus(*hold.WaitlistEntryID, WaitlistBooked, WaitlistOffered); err != nil {
+			log.Printf("Warning: Failed to close waitlist entry %s after booking: %v", *hold.WaitlistEntryID, err)
+		}
+	}
+
+	return appointment, nil
+}
+
+// bookHold books the held interval for the client. The hold itself does not
+// count against the booking and is converted in the same transaction.
+func (s *AppointmentService) bookHold(hold *Hold, clientID string, clientPackageID *string, channel string) (*Appointment, error) {
+	if hold.Status != string(HoldActive) {
+		return nil, fmt.Errorf("hold is no longer active")
+	}
+
+	return s.bookAppointment(CreateAppointmentRequest{
+		ClientID:        clientID,
+		ServiceID:       hold.ServiceID,
+		AppointmentDate: hold.AppointmentDate.Format("2006-01-02"),
+		StartTime:       hold.StartTime[:5],
+		AttendedBy:      hold.AttendedBy,
+		ClientPackageID: clientPackageID,
+		Channel:         channel,
+	}, hold.ID)
+}
+
+// StartHoldSweeper periodically expires holds that were not confirmed and
+// passes waitlist offers on to the next client. It stops when ctx is
+// cancelled.
+func (s *AppointmentService) StartHoldSweeper(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(holdSweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.ExpireHolds(); err != nil {
+				log.Printf("Hold sweep failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *AppointmentService) ExpireHolds() error {
+	expired, err := s.repo.ExpireHolds()
+	if err != nil {
+		return err
+	}
+
+	for i := range expired {
+		if expired[i].WaitlistEntryID != nil {
+			s.passOffer(&expired[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package appointments
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// appointmentCursor marks the last appointment of a page by its sort value
+// and id.
+type appointmentCursor struct {
+	Value time.Time
+	ID    string
+}
+
+func (c appointmentCursor) encode() string {
+	raw := c.Value.Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(value string) (*appointmentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	sortValue, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parsed, err := time.Parse(time.RFC3339Nano, sortValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &appointmentCursor{Value: parsed, ID: id}, nil
+}
+
+// ListAppointments returns one page of the appointments matching the
+// request's filters.
+func (s *AppointmentService) ListAppointments(req ListAppointmentsRequest) (*AppointmentPage, error) {
+	filter, includes, err := parseListRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra row tells whether another page follows.
+	limit := filter.Limit
+	filter.Limit++
+
+	rows, err := s.repo.ListAppointments(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AppointmentPage{}
+	if len(rows) > limit {
+		rows = rows[:limit]
+
+		last := rows[limit-1]
+		cursor := appointmentCursor{Value: last.StartsAt, ID: last.ID}
+		if filter.SortColumn == "created_at" {
+			cursor.Value = last.CreatedAt
+		}
+		next := cursor.encode()
+		page.NextCursor = &next
+	}
+
+	if len(includes) == 0 {
+		appointments := make([]Appointment, len(rows))
+		for i, row := range rows {
+			appointments[i] = row.Appointment
+		}
+		page.Appointments = appointments
+		return page, nil
+	}
+
+	items := make([]AppointmentListItem, len(rows))
+	for i, row := range rows {
+		items[i] = listItem(row, includes)
+	}
+	page.Appointments = items
+
+	return page, nil
+}
+
+// listItem keeps the details of row that includes asks for.
+func listItem(row AppointmentWithDetails, includes map[string]bool) AppointmentListItem {
+	item := AppointmentListItem{Appointment: row.Appointment}
+	if includes["client"] {
+		item.ClientName = &row.ClientName
+		item.ClientDNI = &row.ClientDNI
+	}
+	if includes["service"] {
+		item.ServiceName = &row.ServiceName
+		item.ServicePrice = &row.ServicePrice
+		item.ServiceDuration = &row.ServiceDuration
+		item.VisitTotal = row.VisitTotal
+	}
+	if includes["employee"] {
+		item.EmployeeName = row.EmployeeName
+	}
+	return item
+}
+
+func parseListRequest(req ListAppointmentsRequest) (appointmentFilter, map[string]bool, error) {
+	filter := appointmentFilter{
+		EmployeeID: req.EmployeeID,
+		ServiceID:  req.ServiceID,
+		ClientID:   req.ClientID,
+		SortColumn: "starts_at",
+		Limit:      defaultPageSize,
+	}
+
+	if req.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return filter, nil, fmt.Errorf("invalid start date format, use YYYY-MM-DD: %w", err)
+		}
+		filter.StartDate = &startDate
+	}
+
+	if req.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return filter, nil, fmt.Errorf("invalid end date format, use YYYY-MM-DD: %w", err)
+		}
+		filter.EndDate = &endDate
+	}
+
+	if filter.StartDate != nil && filter.EndDate != nil && filter.EndDate.Before(*filter.StartDate) {
+		return filter, nil, fmt.Errorf("end date must be after start date")
+	}
+
+	for _, status := range splitList(req.Status) {
+		if !AppointmentStatus(status).IsValid() {
+			return filter, nil, fmt.Errorf("invalid status: %s", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if req.Channel != "" {
+		if !BookingChannel(req.Channel).IsValid() {
+			return filter, nil, fmt.Errorf("invalid channel %q, use staff or chatbot", req.Channel)
+		}
+		filter.Channel = req.Channel
+	}
+
+	sort := req.Sort
+	if strings.HasPrefix(sort, "-") {
+		filter.Descending = true
+		sort = sort[1:]
+	}
+	switch sort {
+	case "", "starts_at":
+	case "created_at":
+		filter.SortColumn = "created_at"
+	default:
+		return filter, nil, fmt.Errorf("invalid sort %q, use starts_at or created_at", req.Sort)
+	}
+
+	if req.Limit != 0 {
+		if req.Limit < 1 || req.Limit > maxPageSize {
+			return filter, nil, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		filter.Limit = req.Limit
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return filter, nil, err
+		}
+		filter.After = cursor
+	}
+
+	includes := make(map[string]bool)
+	for _, include := range splitList(req.Include) {
+		switch include {
+		case "client", "service", "employee":
+			includes[include] = true
+		default:
+			return filter, nil, fmt.Errorf("invalid include %q, use client, service or employee", include)
+		}
+	}
+
+	return filter, includes, nil
+}
+
+// splitList splits a comma-separated query value, skipping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
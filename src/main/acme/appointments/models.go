@@ -1,25 +1,45 @@
 package appointments
 
 import (
+	"acme/employees"
 	"time"
 )
 
 type Appointment struct {
-	ID                  string    `json:"id" db:"id"`
-	ClientID            string    `json:"client_id" db:"client_id"`
-	ServiceID           string    `json:"service_id" db:"service_id"`
-	AppointmentDate     time.Time `json:"appointment_date" db:"appointment_date"`
-	StartTime           string    `json:"start_time" db:"start_time"`
-	EndTime             string    `json:"end_time" db:"end_time"`
-	AttendedBy          *string   `json:"attended_by" db:"attended_by"`
-	Status              string    `json:"status" db:"status"`
-	CancelledBy         *string   `json:"cancelled_by" db:"cancelled_by"`
-	CancelledByType     *string   `json:"cancelled_by_type" db:"cancelled_by_type"`
-	CancellationReason  *string   `json:"cancellation_reason" db:"cancellation_reason"`
-	CreatedAt           time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	ID                 string     `json:"id" db:"id"`
+	ClientID           string     `json:"client_id" db:"client_id"`
+	ServiceID          string     `json:"service_id" db:"service_id"`
+	AppointmentDate    time.Time  `json:"appointment_date" db:"appointment_date"`
+	StartTime          string     `json:"start_time" db:"start_time"`
+	EndTime            string     `json:"end_time" db:"end_time"`
+	StartsAt           time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt             time.Time  `json:"ends_at" db:"ends_at"`
+	AttendedBy         *string    `json:"attended_by" db:"attended_by"`
+	Status             string     `json:"status" db:"status"`
+	CancelledBy        *string    `json:"cancelled_by" db:"cancelled_by"`
+	CancelledByType    *string    `json:"cancelled_by_type" db:"cancelled_by_type"`
+	CancellationReason *string    `json:"cancellation_reason" db:"cancellation_reason"`
+	SeriesID           *string    `json:"series_id" db:"series_id"`
+	SeriesIndex        *int       `json:"series_index" db:"series_index"`
+	SessionID          *string    `json:"session_id" db:"session_id"`
+	VisitID            *string    `json:"visit_id" db:"visit_id"`
+	VisitPosition      *int       `json:"visit_position" db:"visit_position"`
+	Channel            string     `json:"channel" db:"channel"`
+	DepositRequired    bool       `json:"deposit_required" db:"deposit_required"`
+	RescheduleCount    int        `json:"reschedule_count" db:"reschedule_count"`
+	OriginalDate       *time.Time `json:"original_date" db:"original_date"`
+	OriginalStartTime  *string    `json:"original_start_time" db:"original_start_time"`
+	LateCancellation   bool       `json:"late_cancellation" db:"late_cancellation"`
+	CancellationFee    *float64   `json:"cancellation_fee" db:"cancellation_fee"`
+	Version            int        `json:"version" db:"version"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
+	// ScreeningWarnings are returned when the appointment is booked and are
+	// not stored.
+	ScreeningWarnings []string `json:"screening_warnings,omitempty"`
 }
 
+
 type AppointmentWithDetails struct {
 	Appointment
 	ClientName  string  `json:"client_name"`
@@ -27,6 +47,50 @@ type AppointmentWithDetails struct {
 	ServiceName string  `json:"service_name"`
 	ServicePrice float64 `json:"service_price"`
 	ServiceDuration int  `json:"service_duration"`
+	// VisitTotal is the price of all active lines of the appointment's visit.
+	VisitTotal *float64 `json:"visit_total,omitempty"`
+	// EmployeeName is the attending specialist's name.
+	EmployeeName *string `json:"employee_name,omitempty"`
+}
+
+// ListAppointmentsRequest holds the filters of GET /appointments. Filters
+// combine, and status is a comma-separated set.
+type ListAppointmentsRequest struct {
+	StartDate  string `form:"start_date"`
+	EndDate    string `form:"end_date"`
+	Status     string `form:"status"`
+	EmployeeID string `form:"employee_id"`
+	ServiceID  string `form:"service_id"`
+	ClientID   string `form:"client_id"`
+	Channel    string `form:"channel"`
+	// Sort is starts_at (default) or created_at; prefix "-" for descending.
+	Sort   string `form:"sort"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit"`
+	// Include is a comma-separated set of client, service and employee. Each
+	// adds that part of the appointment's details to every item.
+	Include string `form:"include"`
+}
+
+// AppointmentListItem is an appointment of GET /appointments with the
+// details its include asked for. The others are left out.
+type AppointmentListItem struct {
+	Appointment
+	ClientName      *string  `json:"client_name,omitempty"`
+	ClientDNI       *string  `json:"client_dni,omitempty"`
+	ServiceName     *string  `json:"service_name,omitempty"`
+	ServicePrice    *float64 `json:"service_price,omitempty"`
+	ServiceDuration *int     `json:"service_duration,omitempty"`
+	VisitTotal      *float64 `json:"visit_total,omitempty"`
+	EmployeeName    *string  `json:"employee_name,omitempty"`
+}
+
+// AppointmentPage is one page of GET /appointments. Appointments holds
+// Appointment items, or AppointmentListItem when include is set.
+// NextCursor fetches the following page and is null on the last one.
+type AppointmentPage struct {
+	Appointments interface{} `json:"appointments"`
+	NextCursor   *string     `json:"next_cursor"`
 }
 
 type CreateAppointmentRequest struct {
@@ -34,38 +98,89 @@ type CreateAppointmentRequest struct {
 	ServiceID       string `json:"service_id" binding:"required"`
 	AppointmentDate string `json:"appointment_date" binding:"required"`
 	StartTime       string `json:"start_time" binding:"required"`
-	AttendedBy      string `json:"attended_by"`
+	// AttendedBy is optional; when empty a qualified specialist is assigned.
+	AttendedBy string `json:"attended_by"`
+	// ClientPackageID optionally pays for the appointment with a session
+	// from one of the client's prepaid packages.
+	ClientPackageID *string `json:"client_package_id"`
+	// Channel is set by the handler from the caller, never from the body.
+	Channel string `json:"-"`
+	// ScreeningOverride books the appointment even though the client's
+	// health screening blocks it.
+	ScreeningOverride *ScreeningOverride `json:"screening_override"`
 }
 
-type UpdateAppointmentRequest struct {
-	AppointmentDate *string `json:"appointment_date"`
-	StartTime       *string `json:"start_time"`
-	AttendedBy      *string `json:"attended_by"`
-	Status          *string `json:"status"`
+// ScreeningOverride is a specialist qualified for the service accepting the
+// risk of a blocking contraindication. Every override is audited.
+type ScreeningOverride struct {
+	OverriddenBy string `json:"overridden_by" binding:"required"`
+	Reason       string `json:"reason" binding:"required"`
+}
+
+type BookingChannel string
+
+const (
+	ChannelStaff   BookingChannel = "staff"
+	ChannelChatbot BookingChannel = "chatbot"
+)
+
+func (c BookingChannel) IsValid() bool {
+	switch c {
+	case ChannelStaff, ChannelChatbot:
+		return true
+	}
+	return false
 }
 
 type AppointmentStatus string
 
 const (
-	StatusPending   AppointmentStatus = "pending"
-	StatusConfirmed AppointmentStatus = "confirmed"
-	StatusCancelled AppointmentStatus = "cancelled"
-	StatusCompleted AppointmentStatus = "completed"
+	StatusPending    AppointmentStatus = "pending"
+	StatusConfirmed  AppointmentStatus = "confirmed"
+	StatusCheckedIn  AppointmentStatus = "checked_in"
+	StatusInProgress AppointmentStatus = "in_progress"
+	StatusCompleted  AppointmentStatus = "completed"
+	StatusCancelled  AppointmentStatus = "cancelled"
+	StatusNoShow     AppointmentStatus = "no_show"
 )
 
 func (s AppointmentStatus) IsValid() bool {
 	switch s {
-	case StatusPending, StatusConfirmed, StatusCancelled, StatusCompleted:
+	case StatusPending, StatusConfirmed, StatusCheckedIn, StatusInProgress, StatusCompleted, StatusCancelled, StatusNoShow:
 		return true
 	}
 	return false
 }
 
+// StatusChangeRequest identifies who moves an appointment to a new status
+// and why. Which fields are required depends on the transition.
+type StatusChangeRequest struct {
+	ChangedBy     string  `json:"changed_by"`
+	ChangedByType string  `json:"changed_by_type"`
+	Reason        *string `json:"reason"`
+}
+
+type StatusHistory struct {
+	ID            string    `json:"id" db:"id"`
+	AppointmentID string    `json:"appointment_id" db:"appointment_id"`
+	FromStatus    *string   `json:"from_status" db:"from_status"`
+	ToStatus      string    `json:"to_status" db:"to_status"`
+	ChangedBy     *string   `json:"changed_by" db:"changed_by"`
+	ChangedByType *string   `json:"changed_by_type" db:"changed_by_type"`
+	Reason        *string   `json:"reason" db:"reason"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// AvailabilitySlot is a bookable start time. Slots of group sessions carry
+// the session and its seat counts; they are available while seats are left.
 type AvailabilitySlot struct {
-	Date      time.Time `json:"date"`
-	StartTime string    `json:"start_time"`
-	EndTime   string    `json:"end_time"`
-	Available bool      `json:"available"`
+	Date           time.Time `json:"date"`
+	StartTime      string    `json:"start_time"`
+	EndTime        string    `json:"end_time"`
+	Available      bool      `json:"available"`
+	SessionID      *string   `json:"session_id,omitempty"`
+	Capacity       *int      `json:"capacity,omitempty"`
+	SeatsAvailable *int      `json:"seats_available,omitempty"`
 }
 
 type CancelAppointmentRequest struct {
@@ -74,6 +189,33 @@ type CancelAppointmentRequest struct {
 	Reason          string `json:"reason" binding:"required"`
 }
 
+// RescheduleRequest moves an appointment to a new slot. Clients are bound by
+// the service's policy; employees may reschedule regardless of it.
+type RescheduleRequest struct {
+	AppointmentDate string  `json:"appointment_date" binding:"required"`
+	StartTime       string  `json:"start_time" binding:"required"`
+	AttendedBy      *string `json:"attended_by"`
+	RequestedBy     string  `json:"requested_by" binding:"required"`
+	RequestedByType string  `json:"requested_by_type" binding:"required"`
+	Reason          *string `json:"reason"`
+}
+
+// RescheduleRecord links an appointment to the slot it was moved from.
+type RescheduleRecord struct {
+	ID              string    `json:"id" db:"id"`
+	AppointmentID   string    `json:"appointment_id" db:"appointment_id"`
+	FromDate        time.Time `json:"from_date" db:"from_date"`
+	FromStartTime   string    `json:"from_start_time" db:"from_start_time"`
+	FromAttendedBy  *string   `json:"from_attended_by" db:"from_attended_by"`
+	ToDate          time.Time `json:"to_date" db:"to_date"`
+	ToStartTime     string    `json:"to_start_time" db:"to_start_time"`
+	ToAttendedBy    *string   `json:"to_attended_by" db:"to_attended_by"`
+	RequestedBy     string    `json:"requested_by" db:"requested_by"`
+	RequestedByType string    `json:"requested_by_type" db:"requested_by_type"`
+	Reason          *string   `json:"reason" db:"reason"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
 type CancelledByType string
 
 const (
@@ -87,4 +229,458 @@ func (c CancelledByType) IsValid() bool {
 		return true
 	}
 	return false
-}
\ No newline at end of file
+}
+
+type AppointmentSeries struct {
+	ID         string    `json:"id" db:"id"`
+	ClientID   string    `json:"client_id" db:"client_id"`
+	ServiceID  string    `json:"service_id" db:"service_id"`
+	AttendedBy *string   `json:"attended_by" db:"attended_by"`
+	StartDate  time.Time `json:"start_date" db:"start_date"`
+	StartTime  string    `json:"start_time" db:"start_time"`
+	RRule      string    `json:"rrule" db:"rrule"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateSeriesRequest struct {
+	ClientID  string `json:"client_id" binding:"required"`
+	ServiceID string `json:"service_id" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	StartTime string `json:"start_time" binding:"required"`
+	// AttendedBy is optional; when empty each occurrence keeps the previous
+	// occurrence's specialist if free, or gets one assigned.
+	AttendedBy string `json:"attended_by"`
+	// RRule uses RFC 5545 syntax, e.g. "FREQ=WEEKLY;INTERVAL=2;COUNT=6".
+	RRule string `json:"rrule" binding:"required"`
+	// AllowPartial books the available occurrences even when others conflict.
+	AllowPartial bool `json:"allow_partial"`
+	// ScreeningOverride applies to every occurrence.
+	ScreeningOverride *ScreeningOverride `json:"screening_override"`
+	// Channel is set by the handler from the caller, never from the body.
+	Channel string `json:"-"`
+}
+
+type OccurrenceResult struct {
+	Index         int                `json:"index"`
+	Date          string             `json:"date"`
+	StartTime     string             `json:"start_time"`
+	EndTime       string             `json:"end_time"`
+	AttendedBy    string             `json:"attended_by,omitempty"`
+	Available     bool               `json:"available"`
+	Reason        string             `json:"reason,omitempty"`
+	Alternatives  []AvailabilitySlot `json:"alternatives,omitempty"`
+	AppointmentID string             `json:"appointment_id,omitempty"`
+}
+
+type SeriesBookingResult struct {
+	Series      *AppointmentSeries `json:"series,omitempty"`
+	Booked      int                `json:"booked"`
+	Conflicts   int                `json:"conflicts"`
+	Occurrences []OccurrenceResult `json:"occurrences"`
+	// ScreeningWarnings come from the client's health screening.
+	ScreeningWarnings []string `json:"screening_warnings,omitempty"`
+}
+
+type SeriesScope string
+
+const (
+	ScopeThis      SeriesScope = "this"
+	ScopeFollowing SeriesScope = "following"
+)
+
+func (s SeriesScope) IsValid() bool {
+	switch s {
+	case ScopeThis, ScopeFollowing:
+		return true
+	}
+	return false
+}
+
+type UpdateSeriesRequest struct {
+	Scope           string  `json:"scope" binding:"required"`
+	AppointmentDate *string `json:"appointment_date"`
+	StartTime       *string `json:"start_time"`
+	AttendedBy      *string `json:"attended_by"`
+	RequestedBy     string  `json:"requested_by" binding:"required"`
+	RequestedByType string  `json:"requested_by_type" binding:"required"`
+	Reason          *string `json:"reason"`
+}
+
+type CancelSeriesRequest struct {
+	Scope           string `json:"scope" binding:"required"`
+	CancelledBy     string `json:"cancelled_by" binding:"required"`
+	CancelledByType string `json:"cancelled_by_type" binding:"required"`
+	Reason          string `json:"reason" binding:"required"`
+}
+
+// ClientReliability summarizes a client's attendance record and the booking
+// policies it triggers. An override granted by staff lifts the policies.
+type ClientReliability struct {
+	ClientID           string               `json:"client_id"`
+	Completed          int                  `json:"completed"`
+	NoShows            int                  `json:"no_shows"`
+	LateCancellations  int                  `json:"late_cancellations"`
+	Score              int                  `json:"score"`
+	DepositRequired    bool                 `json:"deposit_required"`
+	SelfBookingBlocked bool                 `json:"self_booking_blocked"`
+	Override           *ReliabilityOverride `json:"override"`
+}
+
+type ReliabilityOverride struct {
+	ClientID  string    `json:"client_id" db:"client_id"`
+	GrantedBy string    `json:"granted_by" db:"granted_by"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type ReliabilityOverrideRequest struct {
+	GrantedBy string `json:"granted_by" binding:"required"`
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// Hold reserves a specialist interval for a short time without booking it.
+// Active holds count as busy until they expire, are released or are turned
+// into an appointment.
+type Hold struct {
+	ID              string    `json:"id" db:"id"`
+	ServiceID       string    `json:"service_id" db:"service_id"`
+	ClientID        *string   `json:"client_id" db:"client_id"`
+	AttendedBy      string    `json:"attended_by" db:"attended_by"`
+	AppointmentDate time.Time `json:"appointment_date" db:"appointment_date"`
+	StartTime       string    `json:"start_time" db:"start_time"`
+	EndTime         string    `json:"end_time" db:"end_time"`
+	Status          string    `json:"status" db:"status"`
+	WaitlistEntryID *string   `json:"waitlist_entry_id" db:"waitlist_entry_id"`
+	AppointmentID   *string   `json:"appointment_id" db:"appointment_id"`
+	ExpiresAt       time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type HoldStatus string
+
+const (
+	HoldActive    HoldStatus = "active"
+	HoldConverted HoldStatus = "converted"
+	HoldReleased  HoldStatus = "released"
+	HoldExpired   HoldStatus = "expired"
+)
+
+// WaitlistEntry is a client's interest in a service within a date window,
+// optionally with a specific specialist and time of day.
+type WaitlistEntry struct {
+	ID           string    `json:"id" db:"id"`
+	ClientID     string    `json:"client_id" db:"client_id"`
+	ServiceID    string    `json:"service_id" db:"service_id"`
+	AttendedBy   *string   `json:"attended_by" db:"attended_by"`
+	DateFrom     time.Time `json:"date_from" db:"date_from"`
+	DateTo       time.Time `json:"date_to" db:"date_to"`
+	EarliestTime *string   `json:"earliest_time" db:"earliest_time"`
+	LatestTime   *string   `json:"latest_time" db:"latest_time"`
+	Priority     int       `json:"priority" db:"priority"`
+	Status       string    `json:"status" db:"status"`
+	Offer        *Hold     `json:"offer,omitempty"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistBooked    WaitlistStatus = "booked"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+type CreateWaitlistEntryRequest struct {
+	ClientID   string  `json:"client_id" binding:"required"`
+	ServiceID  string  `json:"service_id" binding:"required"`
+	AttendedBy *string `json:"attended_by"`
+	DateFrom   string  `json:"date_from" binding:"required"`
+	DateTo     string  `json:"date_to" binding:"required"`
+	// EarliestTime and LatestTime optionally bound the start time (HH:MM).
+	EarliestTime *string `json:"earliest_time"`
+	LatestTime   *string `json:"latest_time"`
+	// Priority orders clients waiting for the same slot; higher goes first.
+	Priority int `json:"priority"`
+}
+
+// AcceptOfferRequest books the slot held for a waitlist entry.
+type AcceptOfferRequest struct {
+	ClientPackageID *string `json:"client_package_id"`
+	Channel         string  `json:"-"`
+}
+
+type CreateHoldRequest struct {
+	ServiceID       string `json:"service_id" binding:"required"`
+	AppointmentDate string `json:"appointment_date" binding:"required"`
+	StartTime       string `json:"start_time" binding:"required"`
+	// AttendedBy is optional; when empty a qualified specialist is assigned.
+	AttendedBy string  `json:"attended_by"`
+	ClientID   *string `json:"client_id"`
+	// Minutes defaults to HOLD_MINUTES.
+	Minutes int `json:"minutes"`
+}
+
+// ConfirmHoldRequest turns a hold into an appointment. ClientID may be
+// omitted when the hold was made for a client.
+type ConfirmHoldRequest struct {
+	ClientID        string  `json:"client_id"`
+	ClientPackageID *string `json:"client_package_id"`
+	Channel         string  `json:"-"`
+}
+
+// Session is a group class where one specialist serves up to Capacity
+// clients in the same slot. Each enrolled client has an appointment linked
+// to the session.
+type Session struct {
+	ID                 string    `json:"id" db:"id"`
+	ServiceID          string    `json:"service_id" db:"service_id"`
+	AttendedBy         string    `json:"attended_by" db:"attended_by"`
+	SessionDate        time.Time `json:"session_date" db:"session_date"`
+	StartTime          string    `json:"start_time" db:"start_time"`
+	EndTime            string    `json:"end_time" db:"end_time"`
+	StartsAt           time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt             time.Time `json:"ends_at" db:"ends_at"`
+	Capacity           int       `json:"capacity" db:"capacity"`
+	Enrolled           int       `json:"enrolled" db:"enrolled"`
+	SeatsAvailable     int       `json:"seats_available"`
+	Status             string    `json:"status" db:"status"`
+	CancelledBy        *string   `json:"cancelled_by" db:"cancelled_by"`
+	CancellationReason *string   `json:"cancellation_reason" db:"cancellation_reason"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SessionStatus string
+
+const (
+	SessionScheduled SessionStatus = "scheduled"
+	SessionCancelled SessionStatus = "cancelled"
+)
+
+type CreateSessionRequest struct {
+	ServiceID   string `json:"service_id" binding:"required"`
+	SessionDate string `json:"session_date" binding:"required"`
+	StartTime   string `json:"start_time" binding:"required"`
+	// AttendedBy is optional; when empty a qualified specialist is assigned.
+	AttendedBy string `json:"attended_by"`
+	Capacity   int    `json:"capacity" binding:"required,min=1"`
+}
+
+type EnrollRequest struct {
+	ClientID          string             `json:"client_id" binding:"required"`
+	ClientPackageID   *string            `json:"client_package_id"`
+	Channel           string             `json:"-"`
+	ScreeningOverride *ScreeningOverride `json:"screening_override"`
+}
+
+// CancelSessionRequest cancels a session on the spa's side; every enrolled
+// client's appointment is cancelled and the client is notified.
+type CancelSessionRequest struct {
+	CancelledBy string `json:"cancelled_by" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+}
+
+// SessionRoster lists the clients enrolled in a session, including those
+// who cancelled.
+type SessionRoster struct {
+	Session     Session                  `json:"session"`
+	Enrollments []AppointmentWithDetails `json:"enrollments"`
+}
+
+// Visit books several services for one client back to back, e.g. a
+// manicure followed by a pedicure. Each line is an appointment with its own
+// specialist, and the lines are booked all or nothing.
+type Visit struct {
+	ID              string                   `json:"id" db:"id"`
+	ClientID        string                   `json:"client_id" db:"client_id"`
+	AppointmentDate time.Time                `json:"appointment_date" db:"appointment_date"`
+	StartTime       string                   `json:"start_time"`
+	EndTime         string                   `json:"end_time"`
+	Total           float64                  `json:"total"`
+	Lines           []AppointmentWithDetails `json:"lines"`
+	CreatedAt       time.Time                `json:"created_at" db:"created_at"`
+	// ScreeningWarnings come from the client's health screening for each
+	// line when the visit is booked.
+	ScreeningWarnings []string `json:"screening_warnings,omitempty"`
+}
+
+type VisitLineRequest struct {
+	ServiceID string `json:"service_id" binding:"required"`
+	// AttendedBy is optional; when empty a qualified specialist is assigned.
+	AttendedBy        string             `json:"attended_by"`
+	ClientPackageID   *string            `json:"client_package_id"`
+	ScreeningOverride *ScreeningOverride `json:"screening_override"`
+}
+
+// CreateVisitRequest books the lines in order, each starting when the
+// previous one ends.
+type CreateVisitRequest struct {
+	ClientID        string             `json:"client_id" binding:"required"`
+	AppointmentDate string             `json:"appointment_date" binding:"required"`
+	StartTime       string             `json:"start_time" binding:"required"`
+	Lines           []VisitLineRequest `json:"lines" binding:"required,min=1,dive"`
+	Channel         string             `json:"-"`
+}
+
+// EmployeeAgenda is a specialist's schedule day by day, one day for the day
+// view and seven for the week view.
+type EmployeeAgenda struct {
+	EmployeeID   string      `json:"employee_id"`
+	EmployeeName string      `json:"employee_name"`
+	StartDate    time.Time   `json:"start_date"`
+	EndDate      time.Time   `json:"end_date"`
+	Days         []AgendaDay `json:"days"`
+}
+
+// AgendaDay is the specialist's shift and breaks on a date, what is booked
+// in it and the free gaps left. Shift is nil on a day off.
+type AgendaDay struct {
+	Date         time.Time                `json:"date"`
+	Shift        *AgendaInterval          `json:"shift"`
+	Breaks       []employees.Break        `json:"breaks"`
+	Appointments []AppointmentWithDetails `json:"appointments"`
+	Sessions     []Session                `json:"sessions"`
+	FreeGaps     []AgendaInterval         `json:"free_gaps"`
+}
+
+type AgendaInterval struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Minutes   int    `json:"minutes"`
+}
+
+// AbsenceRequest asks to clear a specialist's appointments from StartDate
+// to EndDate, both included.
+type AbsenceRequest struct {
+	StartDate   string  `json:"start_date" binding:"required"`
+	EndDate     string  `json:"end_date" binding:"required"`
+	RequestedBy string  `json:"requested_by" binding:"required"`
+	Reason      *string `json:"reason"`
+	// SearchDays is how many days past the absence new slots are looked
+	// for; 7 when not given.
+	SearchDays *int `json:"search_days"`
+	// PlanHash is the plan_hash of the preview being applied. It is
+	// required to apply a plan.
+	PlanHash string `json:"plan_hash"`
+}
+
+type AbsenceAction string
+
+const (
+	// AbsenceReassign keeps the slot and hands it to a colleague.
+	AbsenceReassign AbsenceAction = "reassign"
+	// AbsenceMove books the appointment into a new slot.
+	AbsenceMove AbsenceAction = "move"
+	// AbsenceUnresolved is left for the receptionist to handle.
+	AbsenceUnresolved AbsenceAction = "unresolved"
+)
+
+// AbsenceOutcome is what happens, or would happen on a dry run, to one of
+// the absent specialist's appointments.
+type AbsenceOutcome struct {
+	AppointmentID  string     `json:"appointment_id"`
+	ClientName     string     `json:"client_name"`
+	ServiceName    string     `json:"service_name"`
+	FromDate       time.Time  `json:"from_date"`
+	FromStartTime  string     `json:"from_start_time"`
+	Action         string     `json:"action"`
+	ToDate         *time.Time `json:"to_date,omitempty"`
+	ToStartTime    *string    `json:"to_start_time,omitempty"`
+	ToAttendedBy   *string    `json:"to_attended_by,omitempty"`
+	ToEmployeeName *string    `json:"to_employee_name,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	Applied        bool       `json:"applied"`
+}
+
+// AbsencePlan lists the outcome for every affected appointment. Group
+// sessions cannot be moved and are listed for the receptionist to reassign
+// or cancel.
+type AbsencePlan struct {
+	EmployeeID        string           `json:"employee_id"`
+	StartDate         time.Time        `json:"start_date"`
+	EndDate           time.Time        `json:"end_date"`
+	DryRun            bool             `json:"dry_run"`
+	Outcomes          []AbsenceOutcome `json:"outcomes"`
+	UnhandledSessions []Session        `json:"unhandled_sessions"`
+	// PlanHash identifies the outcomes, so a preview can be applied only
+	// while it still holds.
+	PlanHash string `json:"plan_hash"`
+}
+
+// ReminderJob is a reminder or confirmation request due before an upcoming
+// appointment. Jobs are stored so a restart neither loses nor repeats them.
+type ReminderJob struct {
+	ID             string    `json:"id" db:"id"`
+	AppointmentID  string    `json:"appointment_id" db:"appointment_id"`
+	Kind           string    `json:"kind" db:"kind"`
+	OffsetMinutes  int       `json:"offset_minutes" db:"offset_minutes"`
+	DueAt          time.Time `json:"due_at" db:"due_at"`
+	Status         string    `json:"status" db:"status"`
+	Attempts       int       `json:"attempts" db:"attempts"`
+	NotificationID *string   `json:"notification_id" db:"notification_id"`
+	LastError      *string   `json:"last_error" db:"last_error"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ReminderKind tells what a reminder job sends.
+type ReminderKind string
+
+const (
+	// ReminderKindReminder reminds a confirmed appointment.
+	ReminderKindReminder ReminderKind = "reminder"
+	// ReminderKindConfirmation asks the client to confirm a pending
+	// appointment, with links to confirm or cancel it.
+	ReminderKindConfirmation ReminderKind = "confirmation"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderScheduled ReminderStatus = "scheduled"
+	ReminderSending   ReminderStatus = "sending"
+	ReminderSent      ReminderStatus = "sent"
+	ReminderFailed    ReminderStatus = "failed"
+	ReminderCancelled ReminderStatus = "cancelled"
+	// ReminderSkipped marks a reminder that became due while a later one
+	// was also due, e.g. after downtime; only the later one is sent.
+	ReminderSkipped ReminderStatus = "skipped"
+)
+
+// LinkAction is what a client can do from a link, without logging in.
+type LinkAction string
+
+const (
+	LinkConfirm LinkAction = "confirm"
+	LinkCancel  LinkAction = "cancel"
+)
+
+// ActionLinks are the signed, single-use links a client can follow to
+// confirm or cancel an appointment. Only pending appointments get a
+// confirm link.
+type ActionLinks struct {
+	AppointmentID string    `json:"appointment_id"`
+	ConfirmURL    *string   `json:"confirm_url,omitempty"`
+	CancelURL     string    `json:"cancel_url"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// ActionLinkPreview describes what a link does, for the landing page to
+// show before the client acts on it.
+type ActionLinkPreview struct {
+	Action          string    `json:"action"`
+	ExpiresAt       time.Time `json:"expires_at"`
+	AppointmentID   string    `json:"appointment_id"`
+	ServiceName     string    `json:"service_name"`
+	AppointmentDate time.Time `json:"appointment_date"`
+	StartTime       string    `json:"start_time"`
+	EmployeeName    *string   `json:"employee_name,omitempty"`
+	Status          string    `json:"status"`
+}
+
+type CancelByLinkRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,42 @@
+package appointments
+
+import (
+	"acme/events"
+	"log"
+)
+
+// publish tells the open event streams about a change. previous is the
+// appointment as it was before a move, or nil.
+func (s *AppointmentService) publish(eventType events.EventType, appointment *Appointment, previous *Appointment) {
+	e := events.Event{
+		Type:          eventType,
+		AppointmentID: appointment.ID,
+		Date:          appointment.AppointmentDate.Format("2006-01-02"),
+		EmployeeID:    appointment.AttendedBy,
+		Status:        appointment.Status,
+		Appointment:   appointment,
+	}
+
+	if previous != nil {
+		if date := previous.AppointmentDate.Format("2006-01-02"); date != e.Date {
+			e.PreviousDate = &date
+		}
+		if previous.AttendedBy != nil && (appointment.AttendedBy == nil || *previous.AttendedBy != *appointment.AttendedBy) {
+			e.PreviousEmployeeID = previous.AttendedBy
+		}
+	}
+
+	s.events.Publish(e)
+}
+
+// publishByID publishes the appointment as it is now stored, for changes
+// made without loading it back.
+func (s *AppointmentService) publishByID(eventType events.EventType, id string, previous *Appointment) {
+	appointment, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		log.Printf("Warning: Failed to publish %s for appointment %s: %v", eventType, id, err)
+		return
+	}
+
+	s.publish(eventType, appointment, previous)
+}
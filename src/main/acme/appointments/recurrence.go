@@ -0,0 +1,128 @@
+package appointments
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences caps how many appointments a single series may create.
+const maxOccurrences = 52
+
+const (
+	FrequencyDaily  = "DAILY"
+	FrequencyWeekly = "WEEKLY"
+)
+
+// RecurrenceRule is the subset of RFC 5545 RRULE supported for series:
+// FREQ=DAILY|WEEKLY with an optional INTERVAL, bounded by COUNT or UNTIL,
+// e.g. "FREQ=WEEKLY;INTERVAL=2;COUNT=6".
+type RecurrenceRule struct {
+	Frequency string
+	Interval  int
+	Count     int
+	Until     *time.Time
+}
+
+func ParseRecurrenceRule(value string) (*RecurrenceRule, error) {
+	rule := &RecurrenceRule{Interval: 1}
+
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rrule part: %s", part)
+		}
+
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		val := strings.TrimSpace(kv[1])
+
+		switch key {
+		case "FREQ":
+			rule.Frequency = strings.ToUpper(val)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("invalid rrule INTERVAL: %s", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("invalid rrule COUNT: %s", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseRuleDate(val)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rrule UNTIL: %s", val)
+			}
+			rule.Until = &until
+		default:
+			return nil, fmt.Errorf("unsupported rrule part: %s", key)
+		}
+	}
+
+	if rule.Frequency != FrequencyDaily && rule.Frequency != FrequencyWeekly {
+		return nil, fmt.Errorf("rrule FREQ must be DAILY or WEEKLY")
+	}
+
+	if rule.Count == 0 && rule.Until == nil {
+		return nil, fmt.Errorf("rrule must set COUNT or UNTIL")
+	}
+
+	if rule.Count > maxOccurrences {
+		return nil, fmt.Errorf("rrule COUNT cannot exceed %d", maxOccurrences)
+	}
+
+	return rule, nil
+}
+
+// parseRuleDate accepts the RFC 5545 basic date (20240115, optionally with a
+// time part) as well as YYYY-MM-DD.
+func parseRuleDate(value string) (time.Time, error) {
+	if len(value) >= 8 && !strings.Contains(value, "-") {
+		return time.Parse("20060102", value[:8])
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences expands the rule into the dates of each occurrence, starting
+// with start itself.
+func (r RecurrenceRule) Occurrences(start time.Time) []time.Time {
+	step := r.Interval
+	if r.Frequency == FrequencyWeekly {
+		step *= 7
+	}
+
+	var dates []time.Time
+	for date := start; len(dates) < maxOccurrences; date = date.AddDate(0, 0, step) {
+		if r.Count > 0 && len(dates) >= r.Count {
+			break
+		}
+		if r.Until != nil && date.After(*r.Until) {
+			break
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
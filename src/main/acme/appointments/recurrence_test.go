@@ -0,0 +1,110 @@
+package appointments
+
+import (
+	"acme/clock"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	until := time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    *RecurrenceRule
+		wantErr bool
+	}{
+		{"weekly count", "FREQ=WEEKLY;COUNT=6", &RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1, Count: 6}, false},
+		{"daily interval", "FREQ=DAILY;INTERVAL=2;COUNT=3", &RecurrenceRule{Frequency: FrequencyDaily, Interval: 2, Count: 3}, false},
+		{"prefix and lower case", "RRULE:freq=weekly;count=2", &RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1, Count: 2}, false},
+		{"basic until", "FREQ=WEEKLY;UNTIL=20250430", &RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1, Until: &until}, false},
+		{"until with time", "FREQ=WEEKLY;UNTIL=20250430T235959Z", &RecurrenceRule{Frequency: FrequencyWeekly, Interval: 1, Until: &until}, false},
+		{"dashed until", "FREQ=DAILY;UNTIL=2025-04-30", &RecurrenceRule{Frequency: FrequencyDaily, Interval: 1, Until: &until}, false},
+		{"trailing separator", "FREQ=DAILY;COUNT=2;", &RecurrenceRule{Frequency: FrequencyDaily, Interval: 1, Count: 2}, false},
+		{"monthly", "FREQ=MONTHLY;COUNT=2", nil, true},
+		{"no bound", "FREQ=WEEKLY", nil, true},
+		{"zero interval", "FREQ=WEEKLY;INTERVAL=0;COUNT=2", nil, true},
+		{"zero count", "FREQ=WEEKLY;COUNT=0", nil, true},
+		{"count over the cap", "FREQ=DAILY;COUNT=53", nil, true},
+		{"unsupported part", "FREQ=WEEKLY;COUNT=2;BYDAY=MO", nil, true},
+		{"bad until", "FREQ=WEEKLY;UNTIL=tomorrow", nil, true},
+		{"missing value", "FREQ=WEEKLY;COUNT", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecurrenceRule(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRecurrenceRule(%q) = %+v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) failed: %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRecurrenceRule(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	lima, err := time.LoadLocation("America/Lima")
+	if err != nil {
+		t.Fatalf("loading America/Lima: %v", err)
+	}
+
+	// 21:00 in Lima is already the next day in UTC; the series must still
+	// start on the Lima date.
+	start := clock.Today(clock.Fixed(time.Date(2025, 3, 10, 21, 0, 0, 0, lima)))
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"weekly count", "FREQ=WEEKLY;COUNT=3", []string{"2025-03-10", "2025-03-17", "2025-03-24"}},
+		{"every other week", "FREQ=WEEKLY;INTERVAL=2;COUNT=3", []string{"2025-03-10", "2025-03-24", "2025-04-07"}},
+		{"daily count", "FREQ=DAILY;COUNT=3", []string{"2025-03-10", "2025-03-11", "2025-03-12"}},
+		{"daily across month end", "FREQ=DAILY;INTERVAL=10;COUNT=3", []string{"2025-03-10", "2025-03-20", "2025-03-30"}},
+		{"until inclusive", "FREQ=WEEKLY;UNTIL=20250324", []string{"2025-03-10", "2025-03-17", "2025-03-24"}},
+		{"until between occurrences", "FREQ=WEEKLY;UNTIL=20250320", []string{"2025-03-10", "2025-03-17"}},
+		{"count stops before until", "FREQ=DAILY;COUNT=2;UNTIL=20250331", []string{"2025-03-10", "2025-03-11"}},
+		{"until before start", "FREQ=DAILY;UNTIL=20250301", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.value)
+			if err != nil {
+				t.Fatalf("ParseRecurrenceRule(%q) failed: %v", tt.value, err)
+			}
+
+			var got []string
+			for _, date := range rule.Occurrences(start) {
+				got = append(got, date.Format("2006-01-02"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceOccurrencesCap(t *testing.T) {
+	start := clock.Today(clock.Fixed(time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)))
+
+	rule, err := ParseRecurrenceRule("FREQ=DAILY;UNTIL=20301231")
+	if err != nil {
+		t.Fatalf("ParseRecurrenceRule failed: %v", err)
+	}
+
+	if got := len(rule.Occurrences(start)); got != maxOccurrences {
+		t.Errorf("len(Occurrences()) = %d, want %d", got, maxOccurrences)
+	}
+}
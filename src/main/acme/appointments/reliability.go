@@ -0,0 +1,141 @@
+package appointments
+
+import (
+	"acme/audit"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+)
+
+// noShowActor is recorded as the actor of no-shows flagged automatically.
+const noShowActor = "no-show-detector"
+
+// GetClientReliability scores a client's attendance from 0 to 100 as the
+// share of kept appointments, where a late cancellation weighs half a
+// no-show. Clients without history score 100.
+func (s *AppointmentService) GetClientReliability(clientID string) (*ClientReliability, error) {
+	policy := s.config.Reliability
+
+	reliability, err := s.repo.GetReliabilityStats(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	reliability.Score = 100
+	missed := float64(reliability.NoShows) + float64(reliability.LateCancellations)/2
+	if total := float64(reliability.Completed) + missed; total > 0 {
+		reliability.Score = int(math.Round(100 * float64(reliability.Completed) / total))
+	}
+
+	reliability.Override, err = s.repo.GetReliabilityOverride(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reliability.Override == nil {
+		reliability.DepositRequired = policy.DepositAfterNoShows > 0 && reliability.NoShows >= policy.DepositAfterNoShows
+		reliability.SelfBookingBlocked = policy.BlockSelfBookingAfterNoShows > 0 && reliability.NoShows >= policy.BlockSelfBookingAfterNoShows
+	}
+
+	return reliability, nil
+}
+
+// GrantReliabilityOverride lets staff lift the no-show policies for a client.
+func (s *AppointmentService) GrantReliabilityOverride(clientID string, req ReliabilityOverrideRequest) (*ClientReliability, error) {
+	override := &ReliabilityOverride{
+		ClientID:  clientID,
+		GrantedBy: req.GrantedBy,
+		Reason:    req.Reason,
+	}
+
+	if err := s.repo.SetReliabilityOverride(override); err != nil {
+		return nil, err
+	}
+
+	return s.GetClientReliability(clientID)
+}
+
+func (s *AppointmentService) RevokeReliabilityOverride(clientID string) error {
+	return s.repo.DeleteReliabilityOverride(clientID)
+}
+
+// applyReliabilityPolicy checks a new booking against the client's record:
+// chatbot self-booking may be blocked, and a deposit may be required.
+func (s *AppointmentService) applyReliabilityPolicy(appointment *Appointment, channel BookingChannel) error {
+	reliability, err := s.GetClientReliability(appointment.ClientID)
+	if err != nil {
+		return err
+	}
+
+	if channel == ChannelChatbot && reliability.SelfBookingBlocked {
+		return fmt.Errorf("self-booking is not available for this client, please contact the spa")
+	}
+
+	appointment.DepositRequired = reliability.DepositRequired
+	return nil
+}
+
+// StartNoShowDetection periodically flags appointments that ended more than
+// the grace period ago without the client checking in. It stops when ctx is
+// cancelled.
+func (s *AppointmentService) StartNoShowDetection(ctx context.Context) {
+	interval := time.Duration(s.config.Reliability.NoShowCheckIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if flagged, err := s.DetectNoShows(); err != nil {
+				log.Printf("No-show detection failed: %v", err)
+			} else if flagged > 0 {
+				log.Printf("No-show detection flagged %d appointments", flagged)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// DetectNoShows marks every overdue appointment as a no-show through the
+// state machine, which forfeits its package session, returning how many were
+// flagged.
+func (s *AppointmentService) DetectNoShows() (int, error) {
+	grace := time.Duration(s.config.Reliability.NoShowGraceMinutes) * time.Minute
+
+	overdue, err := s.repo.GetOverdueAppointments(s.clock.Now().Add(-grace))
+	if err != nil {
+		return 0, err
+	}
+
+	reason := "Client did not check in"
+	req := StatusChangeRequest{
+		ChangedBy:     noShowActor,
+		ChangedByType: string(audit.ChangedBySystem),
+		Reason:        &reason,
+	}
+
+	flagged := 0
+	for _, appointment := range overdue {
+		if _, err := s.TransitionStatus(appointment.ID, StatusNoShow, req, nil); err != nil {
+			// A status change since it was loaded (e.g. a late check-in) is expected
+			if err.Error() != "appointment status changed, please retry" && !strings.HasPrefix(err.Error(), "cannot change appointment status") {
+				log.Printf("Warning: Failed to flag no-show %s: %v", appointment.ID, err)
+			}
+			continue
+		}
+		flagged++
+	}
+
+	return flagged, nil
+}
@@ -0,0 +1,201 @@
+package appointments
+
+import (
+	"acme/notifications"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// reminderLockDuration is how long a claimed reminder is reserved for
+	// its sender before another run may pick it up again.
+	reminderLockDuration = 5 * time.Minute
+	// reminderRetryDelay is multiplied by the attempts made so far.
+	reminderRetryDelay = 5 * time.Minute
+	reminderBatchSize  = 100
+)
+
+// StartReminderScheduler syncs and sends reminders every
+// REMINDER_CHECK_INTERVAL_SECONDS until ctx is done.
+func (s *AppointmentService) StartReminderScheduler(ctx context.Context) {
+	interval := time.Duration(s.config.Reminders.CheckIntervalSeconds) * time.Second
+	if interval <= 0 || len(s.config.Reminders.Offsets) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if sent, err := s.SendDueReminders(); err != nil {
+				log.Printf("Reminder run failed: %v", err)
+			} else if sent > 0 {
+				log.Printf("Sent %d appointment reminders", sent)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// SendDueReminders schedules reminders for upcoming appointments, drops
+// the ones that no longer apply and delivers those that are due, returning
+// how many were sent.
+func (s *AppointmentService) SendDueReminders() (int, error) {
+	var offsets []int
+	for _, offset := range s.config.Reminders.Offsets {
+		if minutes := int(offset / time.Minute); minutes > 0 {
+			offsets = append(offsets, minutes)
+		}
+	}
+
+	now := s.clock.Now()
+	if err := s.repo.SyncReminderJobs(offsets, now); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for {
+		jobs, err := s.repo.ClaimDueReminders(now, now.Add(reminderLockDuration), reminderBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range jobs {
+			if err := s.sendReminder(&jobs[i]); err != nil {
+				log.Printf("Warning: Failed to send reminder %s: %v", jobs[i].ID, err)
+				continue
+			}
+			sent++
+		}
+
+		if len(jobs) < reminderBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// sendReminder delivers one claimed reminder. The notification is stored
+// and linked to the job before it is sent, so a reminder retried after a
+// crash resends that notification rather than creating another.
+func (s *AppointmentService) sendReminder(job *ReminderJob) error {
+	if job.NotificationID == nil {
+		appointment, err := s.repo.GetAppointmentWithDetails(job.AppointmentID)
+		if err != nil {
+			return err
+		}
+
+		request := s.reminderNotification(appointment)
+		if ReminderKind(job.Kind) == ReminderKindConfirmation {
+			request = s.confirmationNotification(appointment)
+		}
+
+		notification, err := s.notificationService.Enqueue(request)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.SetReminderNotification(job.ID, notification.ID); err != nil {
+			return err
+		}
+		job.NotificationID = &notification.ID
+	}
+
+	notification, err := s.notificationService.Deliver(*job.NotificationID)
+	if err != nil {
+		return err
+	}
+
+	var deliveryErr error
+	var retryAt *time.Time
+	if notification.Status != string(notifications.StatusSent) {
+		deliveryErr = fmt.Errorf("delivery failed")
+		if notification.Error != nil {
+			deliveryErr = fmt.Errorf("%s", *notification.Error)
+		}
+
+		if job.Attempts < s.config.Reminders.MaxAttempts {
+			next := s.clock.Now().Add(time.Duration(job.Attempts) * reminderRetryDelay)
+			retryAt = &next
+		}
+	}
+
+	if err := s.repo.CompleteReminder(job.ID, deliveryErr, retryAt); err != nil {
+		return err
+	}
+
+	return deliveryErr
+}
+
+// reminderNotification builds the reminder of a confirmed appointment with
+// a link to cancel it. If the link cannot be created the reminder is sent
+// without it.
+func (s *AppointmentService) reminderNotification(appointment *AppointmentWithDetails) notifications.NotifyRequest {
+	message := "This is a reminder of your " + describeAppointment(appointment) + "."
+
+	links, err := s.CreateActionLinks(appointment.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to create action links for appointment %s: %v", appointment.ID, err)
+	} else {
+		message += " To cancel: " + links.CancelURL
+	}
+
+	return notifications.NotifyRequest{
+		ClientID:      appointment.ClientID,
+		AppointmentID: &appointment.ID,
+		Kind:          notifications.KindAppointmentReminder,
+		Subject:       "Appointment reminder",
+		Message:       message,
+	}
+}
+
+// confirmationNotification asks the client to confirm a pending appointment
+// with links to confirm or cancel it. If the links cannot be created the
+// request is sent without them.
+func (s *AppointmentService) confirmationNotification(appointment *AppointmentWithDetails) notifications.NotifyRequest {
+	message := "Please confirm your " + describeAppointment(appointment) + "."
+
+	links, err := s.CreateActionLinks(appointment.ID)
+	if err != nil {
+		log.Printf("Warning: Failed to create action links for appointment %s: %v", appointment.ID, err)
+	} else {
+		if links.ConfirmURL != nil {
+			message += " To confirm: " + *links.ConfirmURL
+		}
+		message += " To cancel: " + links.CancelURL
+	}
+
+	return notifications.NotifyRequest{
+		ClientID:      appointment.ClientID,
+		AppointmentID: &appointment.ID,
+		Kind:          notifications.KindConfirmationRequest,
+		Subject:       "Please confirm your appointment",
+		Message:       message,
+	}
+}
+
+func describeAppointment(appointment *AppointmentWithDetails) string {
+	description := fmt.Sprintf("%s appointment on %s at %s",
+		appointment.ServiceName, appointment.AppointmentDate.Format("2006-01-02"), appointment.StartTime[:5])
+	if appointment.EmployeeName != nil {
+		description += " with " + *appointment.EmployeeName
+	}
+	return description
+}
+
+// GetReminders lists the appointment's reminders, including sent and
+// cancelled ones.
+func (s *AppointmentService) GetReminders(appointmentID string) ([]ReminderJob, error) {
+	if _, err := s.repo.GetAppointmentByID(appointmentID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReminderJobs(appointmentID)
+}
@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"acme/etag"
+	"acme/resources"
+
+	"github.com/lib/pq"
 )
 
 type Repository struct {
@@ -15,13 +20,131 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) CreateAppointment(appointment *Appointment) error {
+// CreateAppointment inserts the appointment and reserves its specialist and
+// required resources in a single transaction. When holdID is set the booking
+// takes over that hold, which must still be active.
+func (r *Repository) CreateAppointment(appointment *Appointment, requirements []resources.Requirement, holdID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertAppointment(tx, appointment, requirements, excludeIDs(holdID)); err != nil {
+		return err
+	}
+
+	if holdID != "" {
+		result, err := tx.Exec(`
+			UPDATE appointment_holds SET status = 'converted', appointment_id = $2
+			WHERE id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP`,
+			holdID, appointment.ID)
+		if err != nil {
+			return fmt.Errorf("error converting hold: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("error getting rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return fmt.Errorf("hold has expired")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing appointment: %w", err)
+	}
+
+	return nil
+}
+
+// CreateSeries inserts a series and all of its appointments in a single
+// transaction, so either every occurrence is booked or none is.
+func (r *Repository) CreateSeries(series *AppointmentSeries, appointments []*Appointment, requirements []resources.Requirement) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO appointments (client_id, service_id, appointment_date, start_time, end_time, attended_by, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO appointment_series (client_id, service_id, attended_by, start_date, start_time, rrule)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at`
 
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
+		query,
+		series.ClientID,
+		series.ServiceID,
+		series.AttendedBy,
+		series.StartDate,
+		series.StartTime,
+		series.RRule,
+	).Scan(
+		&series.ID,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating appointment series: %w", err)
+	}
+
+	for _, appointment := range appointments {
+		appointment.SeriesID = &series.ID
+		if err := insertAppointment(tx, appointment, requirements, nil); err != nil {
+			return fmt.Errorf("occurrence on %s: %w", appointment.AppointmentDate.Format("2006-01-02"), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing appointment series: %w", err)
+	}
+
+	return nil
+}
+
+// insertAppointment locks the specialist and resource rows so concurrent
+// bookings for them are serialized, re-checks availability and inserts the
+// appointment with its resource reservations. Holds in exclude do not count
+// as busy. Session enrollments skip the checks: the session already holds
+// the specialist and resources.
+func insertAppointment(tx *sql.Tx, appointment *Appointment, requirements []resources.Requirement, exclude []string) error {
+	slot, err := appointmentRange(appointment.StartTime, appointment.EndTime)
+	if err != nil {
+		return err
+	}
+
+	if appointment.AttendedBy != nil && appointment.SessionID == nil {
+		if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, *appointment.AttendedBy); err != nil {
+			return fmt.Errorf("error locking specialist: %w", err)
+		}
+
+		available, err := checkAvailability(tx, appointment.AppointmentDate, appointment.StartTime, appointment.EndTime, *appointment.AttendedBy, appointment.ServiceID, exclude)
+		if err != nil {
+			return err
+		}
+		if !available {
+			return fmt.Errorf("the requested time slot is not available")
+		}
+	}
+
+	if appointment.SessionID == nil {
+		if err := reserveCapacity(tx, appointment.AppointmentDate, slot, requirements, exclude); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO appointments (client_id, service_id, appointment_date, start_time, end_time, attended_by, status,
+		                          series_id, series_index, deposit_required, starts_at, ends_at, session_id,
+		                          visit_id, visit_position, channel)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id, version, created_at, updated_at`
+
+	err = tx.QueryRow(
 		query,
 		appointment.ClientID,
 		appointment.ServiceID,
@@ -30,8 +153,18 @@ func (r *Repository) CreateAppointment(appointment *Appointment) error {
 		appointment.EndTime,
 		appointment.AttendedBy,
 		appointment.Status,
+		appointment.SeriesID,
+		appointment.SeriesIndex,
+		appointment.DepositRequired,
+		appointment.StartsAt,
+		appointment.EndsAt,
+		appointment.SessionID,
+		appointment.VisitID,
+		appointment.VisitPosition,
+		appointment.Channel,
 	).Scan(
 		&appointment.ID,
+		&appointment.Version,
 		&appointment.CreatedAt,
 		&appointment.UpdatedAt,
 	)
@@ -40,32 +173,100 @@ func (r *Repository) CreateAppointment(appointment *Appointment) error {
 		return fmt.Errorf("error creating appointment: %w", err)
 	}
 
-	return nil
+	for _, requirement := range requirements {
+		_, err := tx.Exec(
+			`INSERT INTO appointment_resources (appointment_id, resource_id, quantity) VALUES ($1, $2, $3)`,
+			appointment.ID, requirement.ResourceID, requirement.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("error reserving resource: %w", err)
+		}
+	}
+
+	return insertStatusHistory(tx, appointment.ID, nil, AppointmentStatus(appointment.Status), nil, nil, nil)
 }
 
-func (r *Repository) GetAppointmentByID(id string) (*Appointment, error) {
-	appointment := &Appointment{}
-	query := `
-		SELECT id, client_id, service_id, appointment_date, start_time, end_time, 
-		       attended_by, status, cancelled_by, cancelled_by_type, cancellation_reason,
-		       created_at, updated_at
-		FROM appointments WHERE id = $1`
+// appointmentColumns lists the appointment fields in the order scanned by
+// scanAppointment; queries alias the appointments table as "a".
+const appointmentColumns = `
+		a.id, a.client_id, a.service_id, a.appointment_date, a.start_time, a.end_time, 
+		a.starts_at, a.ends_at, a.attended_by, a.status, a.cancelled_by, a.cancelled_by_type, a.cancellation_reason,
+		a.series_id, a.series_index, a.session_id, a.visit_id, a.visit_position, a.channel, a.deposit_required, a.reschedule_count, a.original_date, a.original_start_time,
+		a.late_cancellation, a.cancellation_fee, a.version, a.created_at, a.updated_at`
 
-	err := r.db.QueryRow(query, id).Scan(
+const detailsColumns = appointmentColumns + `,
+		CONCAT(c.first_name, ' ', c.last_name) as client_name, c.dni as client_dni,
+		s.name as service_name, s.price as service_price, s.duration_minutes as service_duration,
+		(SELECT SUM(vs.price) FROM appointments va JOIN services vs ON va.service_id = vs.id
+		 WHERE va.visit_id = a.visit_id AND va.status != 'cancelled') as visit_total,
+		e.name || ' ' || e.paternal_surname as employee_name`
+
+const detailsFrom = `
+		FROM appointments a
+		JOIN clients c ON a.client_id = c.id
+		JOIN services s ON a.service_id = s.id
+		LEFT JOIN employees e ON a.attended_by = e.id`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func appointmentFields(appointment *Appointment) []interface{} {
+	return []interface{}{
 		&appointment.ID,
 		&appointment.ClientID,
 		&appointment.ServiceID,
 		&appointment.AppointmentDate,
 		&appointment.StartTime,
 		&appointment.EndTime,
+		&appointment.StartsAt,
+		&appointment.EndsAt,
 		&appointment.AttendedBy,
 		&appointment.Status,
 		&appointment.CancelledBy,
 		&appointment.CancelledByType,
 		&appointment.CancellationReason,
+		&appointment.SeriesID,
+		&appointment.SeriesIndex,
+		&appointment.SessionID,
+		&appointment.VisitID,
+		&appointment.VisitPosition,
+		&appointment.Channel,
+		&appointment.DepositRequired,
+		&appointment.RescheduleCount,
+		&appointment.OriginalDate,
+		&appointment.OriginalStartTime,
+		&appointment.LateCancellation,
+		&appointment.CancellationFee,
+		&appointment.Version,
 		&appointment.CreatedAt,
 		&appointment.UpdatedAt,
+	}
+}
+
+func scanAppointment(row rowScanner, appointment *Appointment) error {
+	return row.Scan(appointmentFields(appointment)...)
+}
+
+func scanAppointmentWithDetails(row rowScanner, appointment *AppointmentWithDetails) error {
+	fields := append(appointmentFields(&appointment.Appointment),
+		&appointment.ClientName,
+		&appointment.ClientDNI,
+		&appointment.ServiceName,
+		&appointment.ServicePrice,
+		&appointment.ServiceDuration,
+		&appointment.VisitTotal,
+		&appointment.EmployeeName,
 	)
+	return row.Scan(fields...)
+}
+
+func (r *Repository) GetAppointmentByID(id string) (*Appointment, error) {
+	appointment := &Appointment{}
+	query := `SELECT` + appointmentColumns + ` FROM appointments a WHERE a.id = $1`
+
+	err := scanAppointment(r.db.QueryRow(query, id), appointment)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -79,37 +280,9 @@ func (r *Repository) GetAppointmentByID(id string) (*Appointment, error) {
 
 func (r *Repository) GetAppointmentWithDetails(id string) (*AppointmentWithDetails, error) {
 	appointment := &AppointmentWithDetails{}
-	query := `
-		SELECT a.id, a.client_id, a.service_id, a.appointment_date, a.start_time, a.end_time, 
-		       a.attended_by, a.status, a.cancelled_by, a.cancelled_by_type, a.cancellation_reason,
-		       a.created_at, a.updated_at,
-		       CONCAT(c.first_name, ' ', c.last_name) as client_name, c.dni as client_dni,
-		       s.name as service_name, s.price as service_price, s.duration_minutes as service_duration
-		FROM appointments a
-		JOIN clients c ON a.client_id = c.id
-		JOIN services s ON a.service_id = s.id
-		WHERE a.id = $1`
+	query := `SELECT` + detailsColumns + detailsFrom + ` WHERE a.id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&appointment.ID,
-		&appointment.ClientID,
-		&appointment.ServiceID,
-		&appointment.AppointmentDate,
-		&appointment.StartTime,
-		&appointment.EndTime,
-		&appointment.AttendedBy,
-		&appointment.Status,
-		&appointment.CancelledBy,
-		&appointment.CancelledByType,
-		&appointment.CancellationReason,
-		&appointment.CreatedAt,
-		&appointment.UpdatedAt,
-		&appointment.ClientName,
-		&appointment.ClientDNI,
-		&appointment.ServiceName,
-		&appointment.ServicePrice,
-		&appointment.ServiceDuration,
-	)
+	err := scanAppointmentWithDetails(r.db.QueryRow(query, id), appointment)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -121,187 +294,1941 @@ func (r *Repository) GetAppointmentWithDetails(id string) (*AppointmentWithDetai
 	return appointment, nil
 }
 
-func (r *Repository) CheckAvailability(date time.Time, startTime string, attendedBy string, excludeAppointmentID string) (bool, error) {
-	query := `
-		SELECT COUNT(*) FROM appointments 
-		WHERE appointment_date = $1 AND start_time = $2 AND attended_by = $3 AND status != 'cancelled'`
-	
-	args := []interface{}{date, startTime, attendedBy}
-	
-	if excludeAppointmentID != "" {
-		query += " AND id != $4"
-		args = append(args, excludeAppointmentID)
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// CheckAvailability reports whether the specialist can take a booking of
+// the service over the interval. An empty serviceID checks for an exclusive
+// booking.
+func (r *Repository) CheckAvailability(date time.Time, startTime, endTime string, attendedBy, serviceID string, excludeAppointmentID string) (bool, error) {
+	return checkAvailability(r.db, date, startTime, endTime, attendedBy, serviceID, excludeIDs(excludeAppointmentID))
+}
+
+// CheckAvailabilityExcluding is CheckAvailability ignoring several
+// appointments, e.g. the occurrences of a series that are being moved.
+func (r *Repository) CheckAvailabilityExcluding(date time.Time, startTime, endTime string, attendedBy, serviceID string, excludeAppointmentIDs []string) (bool, error) {
+	return checkAvailability(r.db, date, startTime, endTime, attendedBy, serviceID, excludeAppointmentIDs)
+}
+
+func excludeIDs(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return []string{id}
+}
+
+// checkAvailability reports whether a booking of the service falls within
+// the specialist's shift and fits around their breaks, appointments, active
+// holds and group sessions over the interval, following the concurrency
+// rules of each service involved. Enrollments are covered by their session.
+// The exclusions may name appointments, holds and sessions.
+func checkAvailability(q queryer, date time.Time, startTime, endTime string, attendedBy, serviceID string, excludeAppointmentIDs []string) (bool, error) {
+	slot, err := appointmentRange(startTime, endTime)
+	if err != nil {
+		return false, err
+	}
+
+	working, err := onShift(q, attendedBy, date, slot)
+	if err != nil || !working {
+		return false, err
+	}
+
+	rule := exclusiveRule
+	if serviceID != "" {
+		rule, err = getConcurrencyRule(q, serviceID)
+		if err != nil {
+			return false, err
+		}
 	}
 
-	var count int
-	err := r.db.QueryRow(query, args...).Scan(&count)
+	busy, err := getBusySegments(q, date, []string{attendedBy}, excludeAppointmentIDs)
 	if err != nil {
 		return false, fmt.Errorf("error checking availability: %w", err)
 	}
 
-	return count == 0, nil
+	return fits(rule.segments(slot), busy[attendedBy]), nil
 }
 
-func (r *Repository) GetAppointmentsByDateRange(startDate, endDate time.Time) ([]AppointmentWithDetails, error) {
+// onShift reports whether the slot falls inside the specialist's shift on
+// the date and outside their breaks. Specialists without a weekly schedule
+// are not limited.
+func onShift(q queryer, employeeID string, date time.Time, slot timeRange) (bool, error) {
+	query := `
+		SELECT NOT EXISTS (SELECT 1 FROM employee_shifts WHERE employee_id = $1)
+		    OR EXISTS (
+		       SELECT 1 FROM employee_shifts s
+		       WHERE s.employee_id = $1 AND s.weekday = $2
+		         AND s.start_time <= $3::time AND s.end_time >= $4::time
+		         AND NOT EXISTS (
+		             SELECT 1 FROM employee_breaks b
+		             WHERE b.employee_id = s.employee_id AND b.weekday = s.weekday
+		               AND b.start_time < $4::time AND b.end_time > $3::time))`
+
+	var working bool
+	err := q.QueryRow(query, employeeID, int(date.Weekday()), formatClock(slot.Start), formatClock(slot.End)).Scan(&working)
+	if err != nil {
+		return false, fmt.Errorf("error checking work schedule: %w", err)
+	}
+
+	return working, nil
+}
+
+// CheckResourceCapacity verifies that every required resource has capacity
+// left over the interval.
+func (r *Repository) CheckResourceCapacity(date time.Time, startTime, endTime string, requirements []resources.Requirement, excludeAppointmentIDs ...string) error {
+	slot, err := appointmentRange(startTime, endTime)
+	if err != nil {
+		return err
+	}
+	return checkCapacity(r.db, date, slot, requirements, excludeAppointmentIDs)
+}
+
+// reserveCapacity locks the required resources before checking capacity so
+// that the check holds until the surrounding transaction commits.
+func reserveCapacity(tx *sql.Tx, date time.Time, slot timeRange, requirements []resources.Requirement, excludeAppointmentIDs []string) error {
+	for _, requirement := range requirements {
+		if _, err := tx.Exec(`SELECT id FROM resources WHERE id = $1 FOR UPDATE`, requirement.ResourceID); err != nil {
+			return fmt.Errorf("error locking resource: %w", err)
+		}
+	}
+	return checkCapacity(tx, date, slot, requirements, excludeAppointmentIDs)
+}
+
+func checkCapacity(q queryer, date time.Time, slot timeRange, requirements []resources.Requirement, excludeAppointmentIDs []string) error {
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(requirements))
+	for i, requirement := range requirements {
+		ids[i] = requirement.ResourceID
+	}
+
+	bookings, err := getResourceBookings(q, date, ids, excludeAppointmentIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, requirement := range requirements {
+		if peakUsage(slot, bookings[requirement.ResourceID])+requirement.Quantity > requirement.Capacity {
+			return fmt.Errorf("%s is fully booked for the requested time slot", requirement.ResourceName)
+		}
+	}
+
+	return nil
+}
+
+// GetBusySegments returns the stretches each employee is attending a
+// booking on a date, with the concurrency limit of each.
+func (r *Repository) GetBusySegments(date time.Time, employeeIDs []string) (map[string][]busySegment, error) {
+	return getBusySegments(r.db, date, employeeIDs, nil)
+}
+
+func getBusySegments(q queryer, date time.Time, employeeIDs []string, excludeAppointmentIDs []string) (map[string][]busySegment, error) {
 	query := `
-		SELECT a.id, a.client_id, a.service_id, a.appointment_date, a.start_time, a.end_time, 
-		       a.attended_by, a.status, a.cancelled_by, a.cancelled_by_type, a.cancellation_reason,
-		       a.created_at, a.updated_at,
-		       CONCAT(c.first_name, ' ', c.last_name) as client_name, c.dni as client_dni,
-		       s.name as service_name, s.price as service_price, s.duration_minutes as service_duration
+		SELECT a.attended_by, a.start_time, a.end_time,
+		       s.concurrency_mode, s.max_concurrent, s.processing_start_minutes, s.processing_minutes
 		FROM appointments a
-		JOIN clients c ON a.client_id = c.id
 		JOIN services s ON a.service_id = s.id
-		WHERE a.appointment_date BETWEEN $1 AND $2
-		ORDER BY a.appointment_date ASC, a.start_time ASC`
+		WHERE a.appointment_date = $1 AND a.attended_by = ANY($2::uuid[]) AND a.status != 'cancelled' AND a.session_id IS NULL
+		  AND a.id <> ALL(COALESCE($3::uuid[], '{}'))
+		UNION ALL
+		SELECT attended_by, start_time, end_time, 'exclusive', 1, 0, 0 FROM class_sessions
+		WHERE session_date = $1 AND attended_by = ANY($2::uuid[]) AND status = 'scheduled'
+		  AND id <> ALL(COALESCE($3::uuid[], '{}'))
+		UNION ALL
+		SELECT h.attended_by, h.start_time, h.end_time,
+		       s.concurrency_mode, s.max_concurrent, s.processing_start_minutes, s.processing_minutes
+		FROM appointment_holds h
+		JOIN services s ON h.service_id = s.id
+		WHERE h.appointment_date = $1 AND h.attended_by = ANY($2::uuid[])
+		  AND h.status = 'active' AND h.expires_at > CURRENT_TIMESTAMP
+		  AND h.id <> ALL(COALESCE($3::uuid[], '{}'))`
 
-	rows, err := r.db.Query(query, startDate, endDate)
+	rows, err := q.Query(query, date, pq.Array(employeeIDs), pq.Array(excludeAppointmentIDs))
 	if err != nil {
-		return nil, fmt.Errorf("error querying appointments by date range: %w", err)
+		return nil, fmt.Errorf("error querying busy intervals: %w", err)
 	}
 	defer rows.Close()
 
-	var appointments []AppointmentWithDetails
+	busy := make(map[string][]busySegment)
 	for rows.Next() {
-		var appointment AppointmentWithDetails
-		err := rows.Scan(
-			&appointment.ID,
-			&appointment.ClientID,
-			&appointment.ServiceID,
-			&appointment.AppointmentDate,
-			&appointment.StartTime,
-			&appointment.EndTime,
-			&appointment.AttendedBy,
-			&appointment.Status,
-			&appointment.CancelledBy,
-			&appointment.CancelledByType,
-			&appointment.CancellationReason,
-			&appointment.CreatedAt,
-			&appointment.UpdatedAt,
-			&appointment.ClientName,
-			&appointment.ClientDNI,
-			&appointment.ServiceName,
-			&appointment.ServicePrice,
-			&appointment.ServiceDuration,
-		)
+		var employeeID, startTime, endTime string
+		var rule concurrencyRule
+		if err := rows.Scan(&employeeID, &startTime, &endTime,
+			&rule.Mode, &rule.MaxConcurrent, &rule.ProcessingStart, &rule.ProcessingTime); err != nil {
+			return nil, fmt.Errorf("error scanning busy interval: %w", err)
+		}
+		interval, err := appointmentRange(startTime, endTime)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning appointment: %w", err)
+			return nil, err
 		}
-		appointments = append(appointments, appointment)
+		busy[employeeID] = append(busy[employeeID], rule.segments(interval)...)
 	}
 
-	return appointments, nil
+	return busy, nil
 }
 
-func (r *Repository) GetAppointmentsByClient(clientID string) ([]AppointmentWithDetails, error) {
+// GetConcurrencyRule returns how a booking of the service occupies its
+// specialist.
+func (r *Repository) GetConcurrencyRule(serviceID string) (concurrencyRule, error) {
+	return getConcurrencyRule(r.db, serviceID)
+}
+
+func getConcurrencyRule(q queryer, serviceID string) (concurrencyRule, error) {
+	var rule concurrencyRule
 	query := `
-		SELECT a.id, a.client_id, a.service_id, a.appointment_date, a.start_time, a.end_time, 
-		       a.attended_by, a.status, a.cancelled_by, a.cancelled_by_type, a.cancellation_reason,
-		       a.created_at, a.updated_at,
-		       CONCAT(c.first_name, ' ', c.last_name) as client_name, c.dni as client_dni,
-		       s.name as service_name, s.price as service_price, s.duration_minutes as service_duration
-		FROM appointments a
-		JOIN clients c ON a.client_id = c.id
-		JOIN services s ON a.service_id = s.id
-		WHERE a.client_id = $1
-		ORDER BY a.appointment_date DESC, a.start_time DESC`
+		SELECT concurrency_mode, max_concurrent, processing_start_minutes, processing_minutes
+		FROM services WHERE id = $1`
 
-	rows, err := r.db.Query(query, clientID)
+	err := q.QueryRow(query, serviceID).Scan(&rule.Mode, &rule.MaxConcurrent, &rule.ProcessingStart, &rule.ProcessingTime)
 	if err != nil {
-		return nil, fmt.Errorf("error querying appointments by client: %w", err)
+		if err == sql.ErrNoRows {
+			return concurrencyRule{}, fmt.Errorf("service not found")
+		}
+		return concurrencyRule{}, fmt.Errorf("error getting service concurrency: %w", err)
+	}
+	return rule, nil
+}
+
+// GetResourceBookings returns the reservations of each resource on a date.
+func (r *Repository) GetResourceBookings(date time.Time, resourceIDs []string) (map[string][]resourceBooking, error) {
+	return getResourceBookings(r.db, date, resourceIDs, nil)
+}
+
+func getResourceBookings(q queryer, date time.Time, resourceIDs []string, excludeAppointmentIDs []string) (map[string][]resourceBooking, error) {
+	query := `
+		SELECT ar.resource_id, a.start_time, a.end_time, ar.quantity
+		FROM appointment_resources ar
+		JOIN appointments a ON ar.appointment_id = a.id
+		WHERE a.appointment_date = $1 AND ar.resource_id = ANY($2::uuid[]) AND a.status != 'cancelled'
+		  AND NOT (a.id = ANY($3::uuid[]))
+		UNION ALL
+		SELECT sr.resource_id, cs.start_time, cs.end_time, sr.quantity
+		FROM session_resources sr
+		JOIN class_sessions cs ON sr.session_id = cs.id
+		WHERE cs.session_date = $1 AND sr.resource_id = ANY($2::uuid[]) AND cs.status = 'scheduled'
+		  AND NOT (cs.id = ANY($3::uuid[]))
+		UNION ALL
+		SELECT sr.resource_id, h.start_time, h.end_time, sr.quantity
+		FROM appointment_holds h
+		JOIN service_resources sr ON sr.service_id = h.service_id
+		WHERE h.appointment_date = $1 AND sr.resource_id = ANY($2::uuid[])
+		  AND h.status = 'active' AND h.expires_at > CURRENT_TIMESTAMP
+		  AND NOT (h.id = ANY($3::uuid[]))`
+
+	if excludeAppointmentIDs == nil {
+		excludeAppointmentIDs = []string{}
+	}
+
+	rows, err := q.Query(query, date, pq.Array(resourceIDs), pq.Array(excludeAppointmentIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying resource bookings: %w", err)
 	}
 	defer rows.Close()
 
-	var appointments []AppointmentWithDetails
+	bookings := make(map[string][]resourceBooking)
 	for rows.Next() {
-		var appointment AppointmentWithDetails
-		err := rows.Scan(
-			&appointment.ID,
-			&appointment.ClientID,
-			&appointment.ServiceID,
-			&appointment.AppointmentDate,
-			&appointment.StartTime,
-			&appointment.EndTime,
-			&appointment.AttendedBy,
-			&appointment.Status,
-			&appointment.CancelledBy,
-			&appointment.CancelledByType,
-			&appointment.CancellationReason,
-			&appointment.CreatedAt,
-			&appointment.UpdatedAt,
-			&appointment.ClientName,
-			&appointment.ClientDNI,
-			&appointment.ServiceName,
-			&appointment.ServicePrice,
-			&appointment.ServiceDuration,
-		)
+		var resourceID, startTime, endTime string
+		var quantity int
+		if err := rows.Scan(&resourceID, &startTime, &endTime, &quantity); err != nil {
+			return nil, fmt.Errorf("error scanning resource booking: %w", err)
+		}
+		interval, err := appointmentRange(startTime, endTime)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning appointment: %w", err)
+			return nil, err
 		}
-		appointments = append(appointments, appointment)
+		bookings[resourceID] = append(bookings[resourceID], resourceBooking{timeRange: interval, Quantity: quantity})
 	}
 
-	return appointments, nil
+	return bookings, nil
 }
 
-func (r *Repository) UpdateAppointment(id string, updates UpdateAppointmentRequest) error {
-	setParts := []string{}
-	args := []interface{}{}
-	argIndex := 1
-
-	if updates.AppointmentDate != nil {
-		setParts = append(setParts, fmt.Sprintf("appointment_date = $%d", argIndex))
-		args = append(args, *updates.AppointmentDate)
-		argIndex++
+func (r *Repository) GetServiceDuration(serviceID string) (int, error) {
+	var duration int
+	err := r.db.QueryRow(`SELECT duration_minutes FROM services WHERE id = $1`, serviceID).Scan(&duration)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("service not found")
+		}
+		return 0, fmt.Errorf("error getting service duration: %w", err)
 	}
-	if updates.StartTime != nil {
-		setParts = append(setParts, fmt.Sprintf("start_time = $%d", argIndex))
-		args = append(args, *updates.StartTime)
-		argIndex++
+	return duration, nil
+}
+
+func (r *Repository) GetServiceName(serviceID string) (string, error) {
+	var name string
+	err := r.db.QueryRow(`SELECT name FROM services WHERE id = $1`, serviceID).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("service not found")
+		}
+		return "", fmt.Errorf("error getting service name: %w", err)
 	}
-	if updates.AttendedBy != nil {
-		setParts = append(setParts, fmt.Sprintf("attended_by = $%d", argIndex))
-		args = append(args, updates.AttendedBy)
-		argIndex++
+	return name, nil
+}
+
+func appointmentRange(startTime, endTime string) (timeRange, error) {
+	start, err := parseClock(startTime)
+	if err != nil {
+		return timeRange{}, err
 	}
-	if updates.Status != nil {
-		setParts = append(setParts, fmt.Sprintf("status = $%d", argIndex))
-		args = append(args, *updates.Status)
-		argIndex++
+	end, err := parseClock(endTime)
+	if err != nil {
+		return timeRange{}, err
 	}
+	return timeRange{Start: start, End: end}, nil
+}
 
-	if len(setParts) == 0 {
-		return fmt.Errorf("no fields to update")
-	}
+func (r *Repository) GetAppointmentsByDateRange(startDate, endDate time.Time) ([]AppointmentWithDetails, error) {
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.appointment_date BETWEEN $1 AND $2
+		ORDER BY a.appointment_date ASC, a.start_time ASC`
 
-	query := fmt.Sprintf("UPDATE appointments SET %s WHERE id = $%d", 
-		strings.Join(setParts, ", "), argIndex)
-	args = append(args, id)
+	appointments, err := r.queryAppointmentsWithDetails(query, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying appointments by date range: %w", err)
+	}
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	return appointments, nil
 }
 
-func (r *Repository) CancelAppointment(id string, cancelledBy, cancelledByType, reason string) error {
-	query := `
-		UPDATE appointments 
-		SET status = 'cancelled', cancelled_by = $2, cancelled_by_type = $3, cancellation_reason = $4, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND status != 'cancelled'`
+func (r *Repository) GetAppointmentsByClient(clientID string) ([]AppointmentWithDetails, error) {
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.client_id = $1
+		ORDER BY a.appointment_date DESC, a.start_time DESC`
 
-	result, err := r.db.Exec(query, id, cancelledBy, cancelledByType, reason)
+	appointments, err := r.queryAppointmentsWithDetails(query, clientID)
 	if err != nil {
-		return fmt.Errorf("error cancelling appointment: %w", err)
+		return nil, fmt.Errorf("error querying appointments by client: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return appointments, nil
+}
+
+// GetEmployeeAppointments returns the specialist's active private
+// appointments between two dates; group session enrollments are listed with
+// their session instead.
+func (r *Repository) GetEmployeeAppointments(employeeID string, startDate, endDate time.Time) ([]AppointmentWithDetails, error) {
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.attended_by = $1 AND a.appointment_date BETWEEN $2 AND $3
+		  AND a.status != 'cancelled' AND a.session_id IS NULL
+		ORDER BY a.appointment_date ASC, a.start_time ASC`
+
+	appointments, err := r.queryAppointmentsWithDetails(query, employeeID, startDate, endDate)
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+		return nil, fmt.Errorf("error querying employee appointments: %w", err)
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("appointment not found or already cancelled")
+	return appointments, nil
+}
+
+// GetMovableAppointments returns the specialist's pending and confirmed
+// private appointments between two dates.
+func (r *Repository) GetMovableAppointments(employeeID string, startDate, endDate time.Time) ([]AppointmentWithDetails, error) {
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.attended_by = $1 AND a.appointment_date BETWEEN $2 AND $3
+		  AND a.status IN ('pending', 'confirmed') AND a.session_id IS NULL
+		ORDER BY a.appointment_date ASC, a.start_time ASC`
+
+	appointments, err := r.queryAppointmentsWithDetails(query, employeeID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying employee appointments: %w", err)
 	}
 
-	return nil
+	return appointments, nil
+}
+
+// appointmentFilter is a parsed ListAppointmentsRequest. Rows are ordered
+// by the sort column and then by id, which the cursor continues from.
+type appointmentFilter struct {
+	StartDate  *time.Time
+	EndDate    *time.Time
+	Statuses   []string
+	EmployeeID string
+	ServiceID  string
+	ClientID   string
+	Channel    string
+	SortColumn string
+	Descending bool
+	After      *appointmentCursor
+	Limit      int
 }
 
+// ListAppointments returns up to filter.Limit appointments matching every
+// filter that is set.
+func (r *Repository) ListAppointments(filter appointmentFilter) ([]AppointmentWithDetails, error) {
+	var conditions []string
+	var args []interface{}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.StartDate != nil {
+		where("a.appointment_date >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		where("a.appointment_date <= $%d", *filter.EndDate)
+	}
+	if len(filter.Statuses) > 0 {
+		where("a.status = ANY($%d)", pq.Array(filter.Statuses))
+	}
+	if filter.EmployeeID != "" {
+		where("a.attended_by = $%d", filter.EmployeeID)
+	}
+	if filter.ServiceID != "" {
+		where("a.service_id = $%d", filter.ServiceID)
+	}
+	if filter.ClientID != "" {
+		where("a.client_id = $%d", filter.ClientID)
+	}
+	if filter.Channel != "" {
+		where("a.channel = $%d", filter.Channel)
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		args = append(args, filter.After.Value, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(a.%s, a.id) %s ($%d, $%d)",
+			filter.SortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	query := `SELECT` + detailsColumns + detailsFrom
+	if len(conditions) > 0 {
+		query += `
+		WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(`
+		ORDER BY a.%s %s, a.id %s
+		LIMIT $%d`, filter.SortColumn, direction, direction, len(args))
+
+	appointments, err := r.queryAppointmentsWithDetails(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+func (r *Repository) queryAppointmentsWithDetails(query string, args ...interface{}) ([]AppointmentWithDetails, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []AppointmentWithDetails
+	for rows.Next() {
+		var appointment AppointmentWithDetails
+		if err := scanAppointmentWithDetails(rows, &appointment); err != nil {
+			return nil, fmt.Errorf("error scanning appointment: %w", err)
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	return appointments, rows.Err()
+}
+
+func (r *Repository) queryAppointments(query string, args ...interface{}) ([]Appointment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []Appointment
+	for rows.Next() {
+		var appointment Appointment
+		if err := scanAppointment(rows, &appointment); err != nil {
+			return nil, fmt.Errorf("error scanning appointment: %w", err)
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	return appointments, rows.Err()
+}
+
+func (r *Repository) CancelAppointment(id string, from AppointmentStatus, version *int, cancelledBy, cancelledByType, reason string, late bool, fee *float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE appointments 
+		SET status = 'cancelled', cancelled_by = $2, cancelled_by_type = $3, cancellation_reason = $4,
+		    late_cancellation = $6, cancellation_fee = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = $5 AND ($8::integer IS NULL OR version = $8)`
+
+	result, err := tx.Exec(query, id, cancelledBy, cancelledByType, reason, string(from), late, fee, version)
+	if err != nil {
+		return fmt.Errorf("error cancelling appointment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return staleAppointment(tx, id, version)
+	}
+
+	if err := insertStatusHistory(tx, id, &from, StatusCancelled, &cancelledBy, &cancelledByType, &reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing cancellation: %w", err)
+	}
+
+	return nil
+}
+
+// TransitionStatus moves an appointment from one status to another. The
+// update only applies while the appointment is still in the expected status,
+// and at the expected version when one is given, so two concurrent
+// transitions cannot both succeed.
+func (r *Repository) TransitionStatus(id string, from, to AppointmentStatus, version *int, changedBy, changedByType, reason *string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE appointments SET status = $2 WHERE id = $1 AND status = $3 AND ($4::integer IS NULL OR version = $4)`,
+		id, string(to), string(from), version,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating appointment status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return staleAppointment(tx, id, version)
+	}
+
+	if err := insertStatusHistory(tx, id, &from, to, changedBy, changedByType, reason); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing status change: %w", err)
+	}
+
+	return nil
+}
+
+// staleAppointment explains a conditional update that matched no rows: the
+// appointment is at another version than the caller read, or its status
+// changed in the meantime.
+func staleAppointment(q queryer, id string, version *int) error {
+	if version != nil {
+		var current int
+		err := q.QueryRow(`SELECT version FROM appointments WHERE id = $1`, id).Scan(&current)
+		if err == nil && current != *version {
+			return etag.ErrModified
+		}
+	}
+
+	return fmt.Errorf("appointment status changed, please retry")
+}
+
+func insertStatusHistory(tx *sql.Tx, appointmentID string, from *AppointmentStatus, to AppointmentStatus, changedBy, changedByType, reason *string) error {
+	var fromStatus *string
+	if from != nil {
+		value := string(*from)
+		fromStatus = &value
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO appointment_status_history (appointment_id, from_status, to_status, changed_by, changed_by_type, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		appointmentID, fromStatus, string(to), changedBy, changedByType, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording status history: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetStatusHistory(appointmentID string) ([]StatusHistory, error) {
+	query := `
+		SELECT id, appointment_id, from_status, to_status, changed_by, changed_by_type, reason, created_at
+		FROM appointment_status_history
+		WHERE appointment_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(query, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying status history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []StatusHistory
+	for rows.Next() {
+		var entry StatusHistory
+		err := rows.Scan(
+			&entry.ID,
+			&entry.AppointmentID,
+			&entry.FromStatus,
+			&entry.ToStatus,
+			&entry.ChangedBy,
+			&entry.ChangedByType,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning status history: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// DeleteAppointment removes an appointment that could not be completed as
+// booked, releasing its slot and resources.
+func (r *Repository) DeleteAppointment(id string) error {
+	result, err := r.db.Exec(`DELETE FROM appointments WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting appointment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("appointment not found")
+	}
+
+	return nil
+}
+
+// GetAssignmentCandidates loads the statistics used by the assignment
+// strategies for the given employees, preserving their order.
+func (r *Repository) GetAssignmentCandidates(employeeIDs []string, date time.Time, serviceID, clientID string) ([]AssignmentCandidate, error) {
+	query := `
+		SELECT e.id,
+		       (SELECT COUNT(*) FROM appointments a
+		        WHERE a.attended_by = e.id AND a.appointment_date = $1 AND a.status != 'cancelled'),
+		       (SELECT MAX(a.created_at) FROM appointments a
+		        WHERE a.attended_by = e.id AND a.service_id = $2),
+		       (SELECT COUNT(*) FROM appointments a
+		        WHERE a.attended_by = e.id AND a.client_id = $3 AND a.status != 'cancelled')
+		FROM employees e
+		WHERE e.id = ANY($4::uuid[])`
+
+	rows, err := r.db.Query(query, date, serviceID, clientID, pq.Array(employeeIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying assignment candidates: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]AssignmentCandidate)
+	for rows.Next() {
+		var candidate AssignmentCandidate
+		var lastAssignedAt sql.NullTime
+		err := rows.Scan(
+			&candidate.EmployeeID,
+			&candidate.DailyLoad,
+			&lastAssignedAt,
+			&candidate.ClientVisits,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning assignment candidate: %w", err)
+		}
+		if lastAssignedAt.Valid {
+			candidate.LastAssignedAt = &lastAssignedAt.Time
+		}
+		byID[candidate.EmployeeID] = candidate
+	}
+
+	candidates := make([]AssignmentCandidate, 0, len(employeeIDs))
+	for _, id := range employeeIDs {
+		if candidate, ok := byID[id]; ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return candidates, nil
+}
+
+func (r *Repository) GetSeriesByID(id string) (*AppointmentSeries, error) {
+	series := &AppointmentSeries{}
+	query := `
+		SELECT id, client_id, service_id, attended_by, start_date, start_time, rrule, created_at, updated_at
+		FROM appointment_series WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&series.ID,
+		&series.ClientID,
+		&series.ServiceID,
+		&series.AttendedBy,
+		&series.StartDate,
+		&series.StartTime,
+		&series.RRule,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("series not found")
+		}
+		return nil, fmt.Errorf("error getting appointment series: %w", err)
+	}
+
+	return series, nil
+}
+
+// GetSeriesOccurrences returns the active occurrences of a series from the
+// given index onwards, in order.
+func (r *Repository) GetSeriesOccurrences(seriesID string, fromIndex int) ([]Appointment, error) {
+	query := `SELECT` + appointmentColumns + `
+		FROM appointments a
+		WHERE a.series_id = $1 AND a.series_index >= $2 AND a.status != 'cancelled'
+		ORDER BY a.series_index ASC`
+
+	appointments, err := r.queryAppointments(query, seriesID, fromIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error querying series occurrences: %w", err)
+	}
+
+	return appointments, nil
+}
+
+// GetOverdueAppointments returns pending and confirmed appointments that
+// ended before cutoff without the client being checked in.
+func (r *Repository) GetOverdueAppointments(cutoff time.Time) ([]Appointment, error) {
+	query := `SELECT` + appointmentColumns + `
+		FROM appointments a
+		WHERE a.status IN ('pending', 'confirmed') AND a.ends_at < $1
+		ORDER BY a.starts_at ASC`
+
+	return r.queryAppointments(query, cutoff)
+}
+
+// GetReliabilityStats counts a client's completed appointments, no-shows and
+// cancellations flagged as late by the service's policy.
+func (r *Repository) GetReliabilityStats(clientID string) (*ClientReliability, error) {
+	reliability := &ClientReliability{ClientID: clientID}
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'completed'),
+			COUNT(*) FILTER (WHERE status = 'no_show'),
+			COUNT(*) FILTER (WHERE status = 'cancelled' AND late_cancellation)
+		FROM appointments
+		WHERE client_id = $1`
+
+	err := r.db.QueryRow(query, clientID).Scan(
+		&reliability.Completed,
+		&reliability.NoShows,
+		&reliability.LateCancellations,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reliability stats: %w", err)
+	}
+
+	return reliability, nil
+}
+
+func (r *Repository) GetReliabilityOverride(clientID string) (*ReliabilityOverride, error) {
+	override := &ReliabilityOverride{}
+	query := `SELECT client_id, granted_by, reason, created_at FROM client_booking_overrides WHERE client_id = $1`
+
+	err := r.db.QueryRow(query, clientID).Scan(
+		&override.ClientID,
+		&override.GrantedBy,
+		&override.Reason,
+		&override.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting reliability override: %w", err)
+	}
+
+	return override, nil
+}
+
+func (r *Repository) SetReliabilityOverride(override *ReliabilityOverride) error {
+	query := `
+		INSERT INTO client_booking_overrides (client_id, granted_by, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client_id) DO UPDATE SET granted_by = EXCLUDED.granted_by, reason = EXCLUDED.reason, created_at = CURRENT_TIMESTAMP
+		RETURNING created_at`
+
+	err := r.db.QueryRow(query, override.ClientID, override.GrantedBy, override.Reason).Scan(&override.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving reliability override: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteReliabilityOverride(clientID string) error {
+	result, err := r.db.Exec(`DELETE FROM client_booking_overrides WHERE client_id = $1`, clientID)
+	if err != nil {
+		return fmt.Errorf("error deleting reliability override: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("override not found")
+	}
+
+	return nil
+}
+
+// RescheduleAppointment moves an appointment to the slot in its fields and
+// records where it came from. The specialist and resources are locked and
+// re-checked as for a new booking; the first original slot is kept. A
+// non-nil version must match the stored one.
+func (r *Repository) RescheduleAppointment(appointment *Appointment, version *int, requirements []resources.Requirement, record *RescheduleRecord) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := rescheduleAppointment(tx, appointment, version, requirements, record, excludeIDs(appointment.ID)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing reschedule: %w", err)
+	}
+
+	return nil
+}
+
+// rescheduleMove is one appointment of a batch reschedule.
+type rescheduleMove struct {
+	Appointment  *Appointment
+	Version      *int // when set, the appointment moves only at this version
+	Requirements []resources.Requirement
+	Record       *RescheduleRecord
+}
+
+// RescheduleAppointments moves every appointment in one transaction, so
+// either all of them move or none does. Each move is checked against the
+// moves made before it; the slots of the appointments still to move do not
+// count, since they are being vacated.
+func (r *Repository) RescheduleAppointments(moves []rescheduleMove) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	pending := make([]string, len(moves))
+	for i, move := range moves {
+		pending[i] = move.Appointment.ID
+	}
+
+	for i, move := range moves {
+		if err := rescheduleAppointment(tx, move.Appointment, move.Version, move.Requirements, move.Record, pending[i:]); err != nil {
+			return fmt.Errorf("appointment %s: %w", move.Appointment.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing reschedule: %w", err)
+	}
+
+	return nil
+}
+
+// rescheduleAppointment locks the specialist and resources, re-checks the
+// new slot ignoring the appointments in exclude, which must include the one
+// being moved, and moves it if it is still at version, when one is given.
+func rescheduleAppointment(tx *sql.Tx, appointment *Appointment, version *int, requirements []resources.Requirement, record *RescheduleRecord, exclude []string) error {
+	slot, err := appointmentRange(appointment.StartTime, appointment.EndTime)
+	if err != nil {
+		return err
+	}
+
+	if appointment.AttendedBy != nil {
+		if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, *appointment.AttendedBy); err != nil {
+			return fmt.Errorf("error locking specialist: %w", err)
+		}
+
+		available, err := checkAvailability(tx, appointment.AppointmentDate, appointment.StartTime, appointment.EndTime, *appointment.AttendedBy, appointment.ServiceID, exclude)
+		if err != nil {
+			return err
+		}
+		if !available {
+			return fmt.Errorf("the requested time slot is not available")
+		}
+	}
+
+	if err := reserveCapacity(tx, appointment.AppointmentDate, slot, requirements, exclude); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE appointments
+		SET appointment_date = $2, start_time = $3, end_time = $4, attended_by = $5,
+		    starts_at = $6, ends_at = $7,
+		    reschedule_count = reschedule_count + 1,
+		    original_date = COALESCE(original_date, appointment_date),
+		    original_start_time = COALESCE(original_start_time, start_time)
+		WHERE id = $1 AND status IN ('pending', 'confirmed') AND ($8::integer IS NULL OR version = $8)`
+
+	result, err := tx.Exec(
+		query,
+		appointment.ID,
+		appointment.AppointmentDate,
+		appointment.StartTime,
+		appointment.EndTime,
+		appointment.AttendedBy,
+		appointment.StartsAt,
+		appointment.EndsAt,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("error rescheduling appointment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return staleAppointment(tx, appointment.ID, version)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO appointment_reschedules (appointment_id, from_date, from_start_time, from_attended_by,
+		                                     to_date, to_start_time, to_attended_by, requested_by, requested_by_type, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		record.AppointmentID,
+		record.FromDate,
+		record.FromStartTime,
+		record.FromAttendedBy,
+		record.ToDate,
+		record.ToStartTime,
+		record.ToAttendedBy,
+		record.RequestedBy,
+		record.RequestedByType,
+		record.Reason,
+	).Scan(&record.ID, &record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error recording reschedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetReschedules(appointmentID string) ([]RescheduleRecord, error) {
+	query := `
+		SELECT id, appointment_id, from_date, from_start_time, from_attended_by, to_date, to_start_time, to_attended_by,
+		       requested_by, requested_by_type, reason, created_at
+		FROM appointment_reschedules
+		WHERE appointment_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.Query(query, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reschedules: %w", err)
+	}
+	defer rows.Close()
+
+	var records []RescheduleRecord
+	for rows.Next() {
+		var record RescheduleRecord
+		err := rows.Scan(
+			&record.ID,
+			&record.AppointmentID,
+			&record.FromDate,
+			&record.FromStartTime,
+			&record.FromAttendedBy,
+			&record.ToDate,
+			&record.ToStartTime,
+			&record.ToAttendedBy,
+			&record.RequestedBy,
+			&record.RequestedByType,
+			&record.Reason,
+			&record.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning reschedule: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+const holdColumns = `
+		id, service_id, client_id, attended_by, appointment_date, start_time, end_time, status,
+		waitlist_entry_id, appointment_id, expires_at, created_at`
+
+func scanHold(row rowScanner, hold *Hold) error {
+	return row.Scan(
+		&hold.ID,
+		&hold.ServiceID,
+		&hold.ClientID,
+		&hold.AttendedBy,
+		&hold.AppointmentDate,
+		&hold.StartTime,
+		&hold.EndTime,
+		&hold.Status,
+		&hold.WaitlistEntryID,
+		&hold.AppointmentID,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+	)
+}
+
+func (r *Repository) queryHolds(query string, args ...interface{}) ([]Hold, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []Hold
+	for rows.Next() {
+		var hold Hold
+		if err := scanHold(rows, &hold); err != nil {
+			return nil, fmt.Errorf("error scanning hold: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, nil
+}
+
+// CreateHold reserves the specialist interval and the service's resources in
+// hold for the given number of minutes. The specialist and resource rows are
+// locked so a hold cannot overlap a booking made at the same moment.
+func (r *Repository) CreateHold(hold *Hold, minutes int, requirements []resources.Requirement) error {
+	slot, err := appointmentRange(hold.StartTime, hold.EndTime)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, hold.AttendedBy); err != nil {
+		return fmt.Errorf("error locking specialist: %w", err)
+	}
+
+	available, err := checkAvailability(tx, hold.AppointmentDate, hold.StartTime, hold.EndTime, hold.AttendedBy, hold.ServiceID, nil)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("the requested time slot is not available")
+	}
+
+	if err := reserveCapacity(tx, hold.AppointmentDate, slot, requirements, nil); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO appointment_holds (service_id, client_id, attended_by, appointment_date, start_time, end_time,
+		                               waitlist_entry_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP + make_interval(mins => $8))
+		RETURNING id, status, expires_at, created_at`
+
+	err = tx.QueryRow(
+		query,
+		hold.ServiceID,
+		hold.ClientID,
+		hold.AttendedBy,
+		hold.AppointmentDate,
+		hold.StartTime,
+		hold.EndTime,
+		hold.WaitlistEntryID,
+		minutes,
+	).Scan(
+		&hold.ID,
+		&hold.Status,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating hold: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing hold: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetHoldByID(id string) (*Hold, error) {
+	hold := &Hold{}
+	query := `SELECT` + holdColumns + ` FROM appointment_holds WHERE id = $1`
+
+	err := scanHold(r.db.QueryRow(query, id), hold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("hold not found")
+		}
+		return nil, fmt.Errorf("error getting hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetActiveHoldForEntry returns the offer currently held for a waitlist
+// entry, or nil when it has none.
+func (r *Repository) GetActiveHoldForEntry(entryID string) (*Hold, error) {
+	hold := &Hold{}
+	query := `SELECT` + holdColumns + `
+		FROM appointment_holds
+		WHERE waitlist_entry_id = $1 AND status = 'active' AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC LIMIT 1`
+
+	err := scanHold(r.db.QueryRow(query, entryID), hold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+func (r *Repository) ReleaseHold(id string) error {
+	result, err := r.db.Exec(`UPDATE appointment_holds SET status = 'released' WHERE id = $1 AND status = 'active'`, id)
+	if err != nil {
+		return fmt.Errorf("error releasing hold: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("hold is no longer active")
+	}
+
+	return nil
+}
+
+// ExpireHolds marks every active hold past its expiry as expired and
+// returns them.
+func (r *Repository) ExpireHolds() ([]Hold, error) {
+	query := `
+		UPDATE appointment_holds SET status = 'expired'
+		WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+		RETURNING` + holdColumns
+
+	return r.queryHolds(query)
+}
+
+const waitlistColumns = `
+		id, client_id, service_id, attended_by, date_from, date_to, earliest_time, latest_time,
+		priority, status, created_at, updated_at`
+
+func scanWaitlistEntry(row rowScanner, entry *WaitlistEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.ClientID,
+		&entry.ServiceID,
+		&entry.AttendedBy,
+		&entry.DateFrom,
+		&entry.DateTo,
+		&entry.EarliestTime,
+		&entry.LatestTime,
+		&entry.Priority,
+		&entry.Status,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+}
+
+func (r *Repository) CreateWaitlistEntry(entry *WaitlistEntry) error {
+	query := `
+		INSERT INTO waitlist_entries (client_id, service_id, attended_by, date_from, date_to, earliest_time, latest_time, priority)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at, updated_at`
+
+	err := r.db.QueryRow(
+		query,
+		entry.ClientID,
+		entry.ServiceID,
+		entry.AttendedBy,
+		entry.DateFrom,
+		entry.DateTo,
+		entry.EarliestTime,
+		entry.LatestTime,
+		entry.Priority,
+	).Scan(
+		&entry.ID,
+		&entry.Status,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetWaitlistEntryByID(id string) (*WaitlistEntry, error) {
+	entry := &WaitlistEntry{}
+	query := `SELECT` + waitlistColumns + ` FROM waitlist_entries WHERE id = $1`
+
+	err := scanWaitlistEntry(r.db.QueryRow(query, id), entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("waitlist entry not found")
+		}
+		return nil, fmt.Errorf("error getting waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// GetWaitlist returns open entries, optionally filtered by service and
+// client, in the order offers are made.
+func (r *Repository) GetWaitlist(serviceID, clientID string) ([]WaitlistEntry, error) {
+	query := `SELECT` + waitlistColumns + `
+		FROM waitlist_entries
+		WHERE status IN ('waiting', 'offered')
+		  AND ($1 = '' OR service_id::text = $1)
+		  AND ($2 = '' OR client_id::text = $2)
+		ORDER BY priority DESC, created_at ASC`
+
+	rows, err := r.db.Query(query, serviceID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying waitlist: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []WaitlistEntry
+	for rows.Next() {
+		var entry WaitlistEntry
+		if err := scanWaitlistEntry(rows, &entry); err != nil {
+			return nil, fmt.Errorf("error scanning waitlist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// SetWaitlistStatus moves an entry between statuses, only while it is still
+// in one of the expected ones.
+func (r *Repository) SetWaitlistStatus(id string, to WaitlistStatus, from ...WaitlistStatus) error {
+	expected := make([]string, len(from))
+	for i, status := range from {
+		expected[i] = string(status)
+	}
+
+	result, err := r.db.Exec(
+		`UPDATE waitlist_entries SET status = $2 WHERE id = $1 AND status = ANY($3::text[])`,
+		id, string(to), pq.Array(expected),
+	)
+	if err != nil {
+		return fmt.Errorf("error updating waitlist entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("waitlist entry status changed, please retry")
+	}
+
+	return nil
+}
+
+// GetNextWaitlistCandidate returns the waiting entry with the highest
+// priority that matches the slot and has not been offered it before, or nil.
+func (r *Repository) GetNextWaitlistCandidate(serviceID string, date time.Time, startTime, attendedBy string) (*WaitlistEntry, error) {
+	entry := &WaitlistEntry{}
+	query := `SELECT` + waitlistColumns + `
+		FROM waitlist_entries w
+		WHERE w.status = 'waiting' AND w.service_id = $1 AND $2 BETWEEN w.date_from AND w.date_to
+		  AND (w.attended_by IS NULL OR w.attended_by = $4)
+		  AND (w.earliest_time IS NULL OR w.earliest_time <= $3::time)
+		  AND (w.latest_time IS NULL OR w.latest_time >= $3::time)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM appointment_holds h
+		      WHERE h.waitlist_entry_id = w.id AND h.appointment_date = $2 AND h.start_time = $3::time AND h.attended_by = $4)
+		ORDER BY w.priority DESC, w.created_at ASC
+		LIMIT 1`
+
+	err := scanWaitlistEntry(r.db.QueryRow(query, serviceID, date, startTime, attendedBy), entry)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting waitlist candidate: %w", err)
+	}
+
+	return entry, nil
+}
+
+// sessionColumns lists the session fields in the order scanned by
+// scanSession; queries alias the class_sessions table as "cs".
+const sessionColumns = `
+		cs.id, cs.service_id, cs.attended_by, cs.session_date, cs.start_time, cs.end_time, cs.starts_at, cs.ends_at,
+		cs.capacity,
+		(SELECT COUNT(*) FROM appointments e WHERE e.session_id = cs.id AND e.status != 'cancelled'),
+		cs.status, cs.cancelled_by, cs.cancellation_reason, cs.created_at, cs.updated_at`
+
+func scanSession(row rowScanner, session *Session) error {
+	err := row.Scan(
+		&session.ID,
+		&session.ServiceID,
+		&session.AttendedBy,
+		&session.SessionDate,
+		&session.StartTime,
+		&session.EndTime,
+		&session.StartsAt,
+		&session.EndsAt,
+		&session.Capacity,
+		&session.Enrolled,
+		&session.Status,
+		&session.CancelledBy,
+		&session.CancellationReason,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	session.SeatsAvailable = 0
+	if session.Status == string(SessionScheduled) && session.Capacity > session.Enrolled {
+		session.SeatsAvailable = session.Capacity - session.Enrolled
+	}
+	return nil
+}
+
+// CreateSession books the specialist and resources for a group session the
+// same way a single appointment books them.
+func (r *Repository) CreateSession(session *Session, requirements []resources.Requirement) error {
+	slot, err := appointmentRange(session.StartTime, session.EndTime)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, session.AttendedBy); err != nil {
+		return fmt.Errorf("error locking specialist: %w", err)
+	}
+
+	available, err := checkAvailability(tx, session.SessionDate, session.StartTime, session.EndTime, session.AttendedBy, "", nil)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("the requested time slot is not available")
+	}
+
+	if err := reserveCapacity(tx, session.SessionDate, slot, requirements, nil); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO class_sessions (service_id, attended_by, session_date, start_time, end_time, starts_at, ends_at, capacity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at, updated_at`
+
+	err = tx.QueryRow(
+		query,
+		session.ServiceID,
+		session.AttendedBy,
+		session.SessionDate,
+		session.StartTime,
+		session.EndTime,
+		session.StartsAt,
+		session.EndsAt,
+		session.Capacity,
+	).Scan(
+		&session.ID,
+		&session.Status,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+
+	for _, requirement := range requirements {
+		_, err := tx.Exec(
+			`INSERT INTO session_resources (session_id, resource_id, quantity) VALUES ($1, $2, $3)`,
+			session.ID, requirement.ResourceID, requirement.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("error reserving resource: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing session: %w", err)
+	}
+
+	session.SeatsAvailable = session.Capacity
+	return nil
+}
+
+func (r *Repository) GetSessionByID(id string) (*Session, error) {
+	session := &Session{}
+	query := `SELECT` + sessionColumns + ` FROM class_sessions cs WHERE cs.id = $1`
+
+	err := scanSession(r.db.QueryRow(query, id), session)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("error getting session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetSessions lists scheduled sessions, optionally of one service and from
+// one date.
+func (r *Repository) GetSessions(serviceID, date string) ([]Session, error) {
+	query := `SELECT` + sessionColumns + `
+		FROM class_sessions cs
+		WHERE cs.status = 'scheduled'
+		  AND ($1 = '' OR cs.service_id::text = $1)
+		  AND ($2 = '' OR cs.session_date = $2::date)
+		ORDER BY cs.session_date ASC, cs.start_time ASC`
+
+	rows, err := r.db.Query(query, serviceID, date)
+	if err != nil {
+		return nil, fmt.Errorf("error querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := scanSession(rows, &session); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// GetEmployeeSessions lists the scheduled sessions a specialist leads
+// between two dates.
+func (r *Repository) GetEmployeeSessions(employeeID string, startDate, endDate time.Time) ([]Session, error) {
+	query := `SELECT` + sessionColumns + `
+		FROM class_sessions cs
+		WHERE cs.attended_by = $1 AND cs.session_date BETWEEN $2 AND $3 AND cs.status = 'scheduled'
+		ORDER BY cs.session_date ASC, cs.start_time ASC`
+
+	rows, err := r.db.Query(query, employeeID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying employee sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := scanSession(rows, &session); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// EnrollInSession books a seat. The session row is locked while seats are
+// counted so two clients cannot take the last seat at once.
+func (r *Repository) EnrollInSession(appointment *Appointment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var capacity int
+	var status string
+	err = tx.QueryRow(`SELECT capacity, status FROM class_sessions WHERE id = $1 FOR UPDATE`, *appointment.SessionID).Scan(&capacity, &status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("session not found")
+		}
+		return fmt.Errorf("error locking session: %w", err)
+	}
+
+	if status != string(SessionScheduled) {
+		return fmt.Errorf("session has been cancelled")
+	}
+
+	var enrolled, alreadyEnrolled int
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE client_id = $2)
+		FROM appointments WHERE session_id = $1 AND status != 'cancelled'`,
+		*appointment.SessionID, appointment.ClientID,
+	).Scan(&enrolled, &alreadyEnrolled)
+	if err != nil {
+		return fmt.Errorf("error counting enrollments: %w", err)
+	}
+
+	if alreadyEnrolled > 0 {
+		return fmt.Errorf("client is already enrolled in this session")
+	}
+	if enrolled >= capacity {
+		return fmt.Errorf("session is full")
+	}
+
+	if err := insertAppointment(tx, appointment, nil, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing enrollment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetSessionEnrollments(sessionID string) ([]AppointmentWithDetails, error) {
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.session_id = $1
+		ORDER BY a.created_at ASC`
+
+	return r.queryAppointmentsWithDetails(query, sessionID)
+}
+
+// CancelSession cancels the session together with every enrollment that has
+// not started, recording each in the status history, and returns the IDs of
+// the cancelled appointments.
+func (r *Repository) CancelSession(id, cancelledBy, reason string) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE class_sessions
+		SET status = 'cancelled', cancelled_by = $2, cancellation_reason = $3
+		WHERE id = $1 AND status = 'scheduled'`,
+		id, cancelledBy, reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("session is already cancelled")
+	}
+
+	rows, err := tx.Query(`
+		UPDATE appointments a
+		SET status = 'cancelled', cancelled_by = $2, cancelled_by_type = $3, cancellation_reason = $4,
+		    updated_at = CURRENT_TIMESTAMP
+		FROM (SELECT id, status FROM appointments
+		      WHERE session_id = $1 AND status IN ('pending', 'confirmed') FOR UPDATE) previous
+		WHERE a.id = previous.id
+		RETURNING a.id, previous.status`,
+		id, cancelledBy, string(CancelledByEmployee), reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling enrollments: %w", err)
+	}
+
+	cancelled := make(map[string]AppointmentStatus)
+	var ids []string
+	for rows.Next() {
+		var appointmentID, from string
+		if err := rows.Scan(&appointmentID, &from); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning cancelled enrollment: %w", err)
+		}
+		cancelled[appointmentID] = AppointmentStatus(from)
+		ids = append(ids, appointmentID)
+	}
+	rows.Close()
+
+	changedByType := string(CancelledByEmployee)
+	for _, appointmentID := range ids {
+		from := cancelled[appointmentID]
+		if err := insertStatusHistory(tx, appointmentID, &from, StatusCancelled, &cancelledBy, &changedByType, &reason); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing session cancellation: %w", err)
+	}
+
+	return ids, nil
+}
+
+// CreateVisit books every line of a visit in one transaction, so if any
+// line is unavailable nothing is booked.
+func (r *Repository) CreateVisit(visit *Visit, lines []*Appointment, requirements [][]resources.Requirement) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO visits (client_id, appointment_date) VALUES ($1, $2) RETURNING id, created_at`,
+		visit.ClientID, visit.AppointmentDate,
+	).Scan(&visit.ID, &visit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creating visit: %w", err)
+	}
+
+	for i, line := range lines {
+		position := i + 1
+		line.VisitID = &visit.ID
+		line.VisitPosition = &position
+		if err := insertAppointment(tx, line, requirements[i], nil); err != nil {
+			return fmt.Errorf("line %d: %w", position, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing visit: %w", err)
+	}
+
+	return nil
+}
+
+// GetVisitByID returns the visit with its lines in order.
+func (r *Repository) GetVisitByID(id string) (*Visit, error) {
+	visit := &Visit{}
+	err := r.db.QueryRow(
+		`SELECT id, client_id, appointment_date, created_at FROM visits WHERE id = $1`, id,
+	).Scan(&visit.ID, &visit.ClientID, &visit.AppointmentDate, &visit.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("visit not found")
+		}
+		return nil, fmt.Errorf("error getting visit: %w", err)
+	}
+
+	query := `SELECT` + detailsColumns + detailsFrom + `
+		WHERE a.visit_id = $1
+		ORDER BY a.visit_position ASC`
+
+	visit.Lines, err = r.queryAppointmentsWithDetails(query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return visit, nil
+}
+
+// DeleteVisit removes a visit and its lines, undoing a booking that could
+// not be completed.
+func (r *Repository) DeleteVisit(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM appointments WHERE visit_id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting visit lines: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM visits WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting visit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing visit deletion: %w", err)
+	}
+
+	return nil
+}
+
+// SyncReminderJobs brings the reminder jobs in line with the appointments:
+// every upcoming confirmed appointment gets a reminder per offset and every
+// pending one a confirmation request per offset, jobs of moved appointments
+// are due again at the new time, and jobs whose kind no longer matches the
+// appointment's status, or whose offset was dropped, are cancelled.
+// Reminders whose time has already passed are not created.
+func (r *Repository) SyncReminderJobs(offsetMinutes []int, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	schedule := `
+		INSERT INTO reminder_jobs (appointment_id, kind, offset_minutes, due_at, next_attempt_at)
+		SELECT a.id, CASE a.status WHEN 'pending' THEN 'confirmation' ELSE 'reminder' END, o.minutes,
+		       a.starts_at - o.minutes * INTERVAL '1 minute', a.starts_at - o.minutes * INTERVAL '1 minute'
+		FROM appointments a
+		CROSS JOIN unnest($1::int[]) AS o(minutes)
+		WHERE a.status IN ('pending', 'confirmed') AND a.starts_at > $2
+		  AND a.starts_at - o.minutes * INTERVAL '1 minute' > $2
+		ON CONFLICT (appointment_id, kind, offset_minutes) DO UPDATE
+		SET due_at = EXCLUDED.due_at, next_attempt_at = EXCLUDED.next_attempt_at, status = 'scheduled',
+		    attempts = 0, locked_until = NULL, notification_id = NULL, last_error = NULL
+		WHERE reminder_jobs.due_at <> EXCLUDED.due_at OR reminder_jobs.status = 'cancelled'`
+
+	if _, err := tx.Exec(schedule, pq.Array(offsetMinutes), now); err != nil {
+		return fmt.Errorf("error scheduling reminders: %w", err)
+	}
+
+	cancel := `
+		UPDATE reminder_jobs j SET status = 'cancelled', locked_until = NULL
+		FROM appointments a
+		WHERE a.id = j.appointment_id
+		  AND (j.status = 'scheduled' OR (j.status = 'sending' AND j.locked_until <= $2))
+		  AND (a.status <> CASE j.kind WHEN 'confirmation' THEN 'pending' ELSE 'confirmed' END OR a.starts_at <= $2
+		       OR j.due_at <> a.starts_at - j.offset_minutes * INTERVAL '1 minute'
+		       OR NOT j.offset_minutes = ANY($1::int[]))`
+
+	if _, err := tx.Exec(cancel, pq.Array(offsetMinutes), now); err != nil {
+		return fmt.Errorf("error cancelling reminders: %w", err)
+	}
+
+	skip := `
+		UPDATE reminder_jobs j SET status = 'skipped'
+		WHERE j.status = 'scheduled' AND j.due_at <= $1
+		  AND EXISTS (
+			SELECT 1 FROM reminder_jobs later
+			WHERE later.appointment_id = j.appointment_id AND later.kind = j.kind
+			  AND later.offset_minutes < j.offset_minutes
+			  AND later.status IN ('scheduled', 'sending', 'sent')
+			  AND later.due_at <= $1
+		  )`
+
+	if _, err := tx.Exec(skip, now); err != nil {
+		return fmt.Errorf("error skipping superseded reminders: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing reminder sync: %w", err)
+	}
+
+	return nil
+}
+
+const reminderColumns = `
+		id, appointment_id, kind, offset_minutes, due_at, status, attempts, notification_id, last_error, created_at, updated_at`
+
+func scanReminderJob(row rowScanner, job *ReminderJob) error {
+	return row.Scan(
+		&job.ID,
+		&job.AppointmentID,
+		&job.Kind,
+		&job.OffsetMinutes,
+		&job.DueAt,
+		&job.Status,
+		&job.Attempts,
+		&job.NotificationID,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+}
+
+func (r *Repository) queryReminderJobs(query string, args ...interface{}) ([]ReminderJob, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reminders: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []ReminderJob{}
+	for rows.Next() {
+		var job ReminderJob
+		if err := scanReminderJob(rows, &job); err != nil {
+			return nil, fmt.Errorf("error scanning reminder: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ClaimDueReminders locks up to limit due reminders until lockedUntil and
+// marks them as sending. A reminder whose sender died is claimed again once
+// its lock runs out. The appointment is checked again here, so a change the
+// last sync has not seen yet never triggers a stale reminder.
+func (r *Repository) ClaimDueReminders(now, lockedUntil time.Time, limit int) ([]ReminderJob, error) {
+	query := `
+		UPDATE reminder_jobs SET status = 'sending', attempts = attempts + 1, locked_until = $2
+		WHERE id IN (
+			SELECT j.id
+			FROM reminder_jobs j
+			JOIN appointments a ON a.id = j.appointment_id
+			WHERE ((j.status = 'scheduled' AND j.next_attempt_at <= $1)
+			       OR (j.status = 'sending' AND j.locked_until <= $1))
+			  AND a.status = CASE j.kind WHEN 'confirmation' THEN 'pending' ELSE 'confirmed' END AND a.starts_at > $1
+			  AND j.due_at = a.starts_at - j.offset_minutes * INTERVAL '1 minute'
+			ORDER BY j.due_at ASC
+			LIMIT $3
+			FOR UPDATE OF j SKIP LOCKED
+		)
+		RETURNING` + reminderColumns
+
+	return r.queryReminderJobs(query, now, lockedUntil, limit)
+}
+
+// SetReminderNotification links the reminder to the notification stored for
+// it, before the notification is sent.
+func (r *Repository) SetReminderNotification(jobID, notificationID string) error {
+	_, err := r.db.Exec(`UPDATE reminder_jobs SET notification_id = $2 WHERE id = $1`, jobID, notificationID)
+	if err != nil {
+		return fmt.Errorf("error updating reminder: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteReminder records the outcome of a delivery attempt. A nil
+// retryAt with an error gives the reminder up.
+func (r *Repository) CompleteReminder(jobID string, deliveryErr error, retryAt *time.Time) error {
+	status := ReminderSent
+	var message *string
+	if deliveryErr != nil {
+		text := deliveryErr.Error()
+		message = &text
+		status = ReminderFailed
+		if retryAt != nil {
+			status = ReminderScheduled
+		}
+	}
+
+	query := `
+		UPDATE reminder_jobs
+		SET status = $2, last_error = $3, next_attempt_at = COALESCE($4, next_attempt_at), locked_until = NULL
+		WHERE id = $1 AND status = 'sending'`
+
+	if _, err := r.db.Exec(query, jobID, status, message, retryAt); err != nil {
+		return fmt.Errorf("error updating reminder: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetReminderJobs(appointmentID string) ([]ReminderJob, error) {
+	query := `SELECT` + reminderColumns + `
+		FROM reminder_jobs
+		WHERE appointment_id = $1
+		ORDER BY offset_minutes DESC, kind`
+
+	return r.queryReminderJobs(query, appointmentID)
+}
+
+func (r *Repository) CreateActionToken(id, appointmentID string, action LinkAction, expiresAt time.Time) error {
+	query := `
+		INSERT INTO appointment_action_tokens (id, appointment_id, action, expires_at)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := r.db.Exec(query, id, appointmentID, action, expiresAt); err != nil {
+		return fmt.Errorf("error creating action link: %w", err)
+	}
+
+	return nil
+}
+
+// IsActionTokenUsed reports whether a stored action token has been used.
+func (r *Repository) IsActionTokenUsed(id string) (bool, error) {
+	var used bool
+	err := r.db.QueryRow(`SELECT used_at IS NOT NULL FROM appointment_action_tokens WHERE id = $1`, id).Scan(&used)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, fmt.Errorf("invalid or expired link")
+		}
+		return false, fmt.Errorf("error getting action link: %w", err)
+	}
+
+	return used, nil
+}
+
+// UseActionToken marks the token as used. Only one of several concurrent
+// requests with the same token succeeds.
+func (r *Repository) UseActionToken(id, appointmentID string, action LinkAction, now time.Time) error {
+	query := `
+		UPDATE appointment_action_tokens SET used_at = $4
+		WHERE id = $1 AND appointment_id = $2 AND action = $3 AND used_at IS NULL AND expires_at > $4`
+
+	result, err := r.db.Exec(query, id, appointmentID, action, now)
+	if err != nil {
+		return fmt.Errorf("error using action link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		used, err := r.IsActionTokenUsed(id)
+		if err != nil {
+			return err
+		}
+		if used {
+			return fmt.Errorf("link has already been used")
+		}
+		return fmt.Errorf("invalid or expired link")
+	}
+
+	return nil
+}
+
+// ReleaseActionToken makes a token usable again after its action failed.
+func (r *Repository) ReleaseActionToken(id string) error {
+	if _, err := r.db.Exec(`UPDATE appointment_action_tokens SET used_at = NULL WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error releasing action link: %w", err)
+	}
+
+	return nil
+}
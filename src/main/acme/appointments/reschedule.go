@@ -0,0 +1,154 @@
+package appointments
+
+import (
+	"acme/events"
+	"acme/policies"
+	"fmt"
+	"time"
+)
+
+// evaluateCancellation applies the service's policy to a cancellation.
+// Only client cancellations can be late; when the spa cancels, the client
+// is never charged.
+func (s *AppointmentService) evaluateCancellation(appointment *Appointment, cancelledBy CancelledByType) (policies.CancellationOutcome, error) {
+	if cancelledBy != CancelledByClient {
+		return policies.CancellationOutcome{}, nil
+	}
+
+	policy, err := s.policyService.GetPolicy(appointment.ServiceID)
+	if err != nil {
+		return policies.CancellationOutcome{}, err
+	}
+
+	return policy.EvaluateCancellation(appointment.StartsAt, s.clock.Now()), nil
+}
+
+// RescheduleAppointment moves an appointment to a new slot after checking
+// the service's policy, keeping its package reservation and recording the
+// slot it came from. A non-nil version must match the appointment's current
+// one.
+func (s *AppointmentService) RescheduleAppointment(id string, req RescheduleRequest, version *int) (*Appointment, error) {
+	requestedBy := CancelledByType(req.RequestedByType)
+	if !requestedBy.IsValid() {
+		return nil, fmt.Errorf("invalid requested_by_type: %s", req.RequestedByType)
+	}
+
+	newDate, err := time.Parse("2006-01-02", req.AppointmentDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if newDate.Before(s.today()) {
+		return nil, fmt.Errorf("cannot reschedule to past dates")
+	}
+
+	if !s.isValidTimeFormat(req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	current, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(current, version); err != nil {
+		return nil, err
+	}
+
+	if current.SessionID != nil {
+		return nil, fmt.Errorf("session enrollments cannot be moved, cancel and enroll in another session")
+	}
+
+	status := AppointmentStatus(current.Status)
+	if status != StatusPending && status != StatusConfirmed {
+		return nil, fmt.Errorf("cannot reschedule an appointment that is %s", current.Status)
+	}
+
+	if requestedBy == CancelledByClient {
+		policy, err := s.policyService.GetPolicy(current.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := policy.CheckReschedule(current.StartsAt, s.clock.Now(), current.RescheduleCount); err != nil {
+			return nil, err
+		}
+	}
+
+	duration, err := s.repo.GetServiceDuration(current.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := s.calculateEndTime(req.StartTime, duration)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating end time: %w", err)
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(current.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	moved := *current
+	moved.AppointmentDate = newDate
+	moved.StartTime = req.StartTime
+	moved.EndTime = endTime
+
+	if req.AttendedBy != nil {
+		if err := s.ensureQualified(*req.AttendedBy, current.ServiceID); err != nil {
+			return nil, err
+		}
+		moved.AttendedBy = req.AttendedBy
+	} else if moved.AttendedBy != nil {
+		available, err := s.repo.CheckAvailability(newDate, req.StartTime, endTime, *moved.AttendedBy, current.ServiceID, id)
+		if err != nil {
+			return nil, fmt.Errorf("error checking availability: %w", err)
+		}
+		if !available {
+			// Keep the slot and hand the appointment to another specialist
+			attendedBy, err := s.assignSpecialist(current.ServiceID, current.ClientID, newDate, req.StartTime, endTime)
+			if err != nil {
+				return nil, err
+			}
+			moved.AttendedBy = &attendedBy
+		}
+	}
+
+	if err := s.setInstants(&moved); err != nil {
+		return nil, err
+	}
+
+	record := &RescheduleRecord{
+		AppointmentID:   id,
+		FromDate:        current.AppointmentDate,
+		FromStartTime:   current.StartTime,
+		FromAttendedBy:  current.AttendedBy,
+		ToDate:          moved.AppointmentDate,
+		ToStartTime:     moved.StartTime,
+		ToAttendedBy:    moved.AttendedBy,
+		RequestedBy:     req.RequestedBy,
+		RequestedByType: req.RequestedByType,
+		Reason:          req.Reason,
+	}
+
+	if err := s.repo.RescheduleAppointment(&moved, version, requirements, record); err != nil {
+		return nil, err
+	}
+
+	rescheduled, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events.AppointmentUpdated, rescheduled, current)
+	return rescheduled, nil
+}
+
+func (s *AppointmentService) GetReschedules(id string) ([]RescheduleRecord, error) {
+	if _, err := s.repo.GetAppointmentByID(id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReschedules(id)
+}
@@ -0,0 +1,118 @@
+package appointments
+
+import (
+	"acme/clock"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timeRange is a half-open interval of minutes since midnight.
+type timeRange struct {
+	Start int
+	End   int
+}
+
+func (r timeRange) overlaps(other timeRange) bool {
+	return r.Start < other.End && other.Start < r.End
+}
+
+// resourceBooking is a quantity of a resource held over a time range.
+type resourceBooking struct {
+	timeRange
+	Quantity int
+}
+
+// parseClock converts "HH:MM" or "HH:MM:SS" (as returned by TIME columns)
+// into minutes since midnight.
+func parseClock(value string) (int, error) {
+	if len(value) < 5 || value[2] != ':' {
+		return 0, fmt.Errorf("invalid time format")
+	}
+
+	hour, err := strconv.Atoi(value[:2])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour")
+	}
+
+	min, err := strconv.Atoi(value[3:5])
+	if err != nil || min < 0 || min > 59 {
+		return 0, fmt.Errorf("invalid minute")
+	}
+
+	return hour*60 + min, nil
+}
+
+// today is the current date in the business timezone.
+func (s *AppointmentService) today() time.Time {
+	return clock.Today(s.clock)
+}
+
+// instant returns the moment a clock time on a date happens in the business
+// timezone.
+func (s *AppointmentService) instant(date time.Time, clockTime string) (time.Time, error) {
+	minutes, err := parseClock(clockTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := date.Date()
+	return time.Date(year, month, day, minutes/60, minutes%60, 0, 0, s.clock.Location()), nil
+}
+
+// setInstants derives when the appointment starts and ends from its date
+// and times.
+func (s *AppointmentService) setInstants(appointment *Appointment) error {
+	startsAt, err := s.instant(appointment.AppointmentDate, appointment.StartTime)
+	if err != nil {
+		return err
+	}
+
+	endsAt, err := s.instant(appointment.AppointmentDate, appointment.EndTime)
+	if err != nil {
+		return err
+	}
+
+	appointment.StartsAt = startsAt
+	appointment.EndsAt = endsAt
+	return nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// peakUsage returns the highest quantity held at any instant within slot.
+// Usage only increases at a booking's start, so checking the slot start and
+// every booking start inside the slot is enough.
+func peakUsage(slot timeRange, bookings []resourceBooking) int {
+	points := []int{slot.Start}
+	for _, b := range bookings {
+		if b.Start > slot.Start && b.Start < slot.End {
+			points = append(points, b.Start)
+		}
+	}
+
+	peak := 0
+	for _, p := range points {
+		usage := 0
+		for _, b := range bookings {
+			if b.Start <= p && p < b.End {
+				usage += b.Quantity
+			}
+		}
+		if usage > peak {
+			peak = usage
+		}
+	}
+	return peak
+}
+
+func isFree(slot timeRange, busy []timeRange) bool {
+	for _, b := range busy {
+		if slot.overlaps(b) {
+			return false
+		}
+	}
+	return true
+}
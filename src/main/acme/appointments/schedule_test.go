@@ -0,0 +1,48 @@
+package appointments
+
+import "testing"
+
+func TestPeakUsage(t *testing.T) {
+	booking := func(start, end string, quantity int) resourceBooking {
+		return resourceBooking{timeRange: span(t, start, end), Quantity: quantity}
+	}
+
+	tests := []struct {
+		name     string
+		slot     timeRange
+		bookings []resourceBooking
+		want     int
+	}{
+		{"no bookings", span(t, "10:00", "11:00"), nil, 0},
+		{"covering booking", span(t, "10:00", "11:00"), []resourceBooking{booking("09:00", "12:00", 2)}, 2},
+		{"booking ending at the slot start", span(t, "10:00", "11:00"), []resourceBooking{booking("09:00", "10:00", 1)}, 0},
+		{"booking starting at the slot end", span(t, "10:00", "11:00"), []resourceBooking{booking("11:00", "12:00", 1)}, 0},
+		{"overlapping bookings add up", span(t, "10:00", "11:00"), []resourceBooking{
+			booking("09:30", "10:30", 1),
+			booking("10:15", "11:15", 2),
+		}, 3},
+		{"consecutive bookings do not add up", span(t, "10:00", "11:00"), []resourceBooking{
+			booking("10:00", "10:30", 1),
+			booking("10:30", "11:00", 1),
+		}, 1},
+		{"peak inside the slot", span(t, "10:00", "12:00"), []resourceBooking{
+			booking("10:00", "10:30", 1),
+			booking("11:00", "11:30", 1),
+			booking("11:15", "12:00", 1),
+			booking("11:20", "11:40", 1),
+		}, 3},
+		{"bookings outside the slot ignored", span(t, "10:00", "11:00"), []resourceBooking{
+			booking("08:00", "09:00", 5),
+			booking("10:00", "11:00", 1),
+			booking("12:00", "13:00", 5),
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := peakUsage(tt.slot, tt.bookings); got != tt.want {
+				t.Errorf("peakUsage() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package appointments
+
+import (
+	"acme/audit"
+	"acme/health"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// screenClient checks the client's health profile against the service.
+// Blocking conflicts refuse the booking unless a specialist qualified for the
+// service overrides them; the overridden conditions are returned so the
+// override can be audited once the appointment exists.
+func (s *AppointmentService) screenClient(clientID, serviceID string, override *ScreeningOverride) (*health.Screening, []health.Condition, error) {
+	screening, err := s.healthService.Screen(clientID, serviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error screening client: %w", err)
+	}
+
+	blocked := screening.Blocked()
+	if len(blocked) == 0 {
+		return screening, nil, nil
+	}
+
+	names := make([]string, len(blocked))
+	for i, condition := range blocked {
+		names[i] = string(condition)
+	}
+
+	if override == nil {
+		return nil, nil, fmt.Errorf("health screening blocks this service, client has declared: %s", strings.Join(names, ", "))
+	}
+
+	if err := s.ensureQualified(override.OverriddenBy, serviceID); err != nil {
+		return nil, nil, fmt.Errorf("screening override must be given by a specialist qualified for the service")
+	}
+
+	screening.Warnings = append(screening.Warnings,
+		fmt.Sprintf("health screening overridden for: %s", strings.Join(names, ", ")))
+
+	return screening, blocked, nil
+}
+
+// auditScreeningOverride records that the appointment was booked despite
+// the given blocking conditions.
+func (s *AppointmentService) auditScreeningOverride(appointment *Appointment, override *ScreeningOverride, overridden []health.Condition) {
+	if len(overridden) == 0 {
+		return
+	}
+
+	auditReq := audit.CreateAuditLogRequest{
+		TableName: "appointments",
+		RecordID:  appointment.ID,
+		Action:    audit.ActionCreate,
+		NewValues: map[string]interface{}{
+			"client_id":               appointment.ClientID,
+			"service_id":              appointment.ServiceID,
+			"screening_overridden":    overridden,
+			"screening_overridden_by": override.OverriddenBy,
+		},
+		ChangedBy:     override.OverriddenBy,
+		ChangedByType: audit.ChangedByEmployee,
+		Reason:        &override.Reason,
+	}
+	if err := s.auditService.LogAction(auditReq); err != nil {
+		log.Printf("Warning: Failed to log screening override for appointment %s: %v", appointment.ID, err)
+	}
+}
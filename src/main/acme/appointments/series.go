@@ -0,0 +1,474 @@
+package appointments
+
+import (
+	"acme/events"
+	"acme/policies"
+	"acme/resources"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// maxAlternatives is how many alternative slots are suggested for each
+// conflicting occurrence.
+const maxAlternatives = 3
+
+// CreateSeries validates every occurrence of a recurring booking. With
+// dryRun it only reports; otherwise it books all occurrences atomically, or
+// just the available ones when AllowPartial is set. When occurrences conflict
+// and partial booking was not requested, the report is returned together
+// with an error and nothing is booked.
+func (s *AppointmentService) CreateSeries(req CreateSeriesRequest, dryRun bool) (*SeriesBookingResult, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if !s.isValidTimeFormat(req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	rule, err := ParseRecurrenceRule(req.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := parseChannel(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := s.repo.GetServiceDuration(req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := s.calculateEndTime(req.StartTime, duration)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating end time: %w", err)
+	}
+
+	if req.AttendedBy != "" {
+		if err := s.ensureQualified(req.AttendedBy, req.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(req.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	screening, overridden, err := s.screenClient(req.ClientID, req.ServiceID, req.ScreeningOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeriesBookingResult{ScreeningWarnings: screening.Warnings}
+	var toBook []*Appointment
+	previous := req.AttendedBy
+
+	for i, date := range rule.Occurrences(startDate) {
+		index := i
+		occurrence := OccurrenceResult{
+			Index:     index,
+			Date:      date.Format("2006-01-02"),
+			StartTime: req.StartTime,
+			EndTime:   endTime,
+		}
+
+		attendedBy, reason := s.validateOccurrence(req, date, endTime, previous, requirements)
+		if reason != "" {
+			occurrence.Reason = reason
+			occurrence.Alternatives = s.suggestAlternatives(req.ServiceID, date, req.StartTime, req.AttendedBy)
+			result.Conflicts++
+		} else {
+			occurrence.Available = true
+			occurrence.AttendedBy = attendedBy
+			previous = attendedBy
+
+			appointment := &Appointment{
+				ClientID:        req.ClientID,
+				ServiceID:       req.ServiceID,
+				AppointmentDate: date,
+				StartTime:       req.StartTime,
+				EndTime:         endTime,
+				AttendedBy:      &attendedBy,
+				Status:          string(StatusPending),
+				SeriesIndex:     &index,
+				Channel:         string(channel),
+			}
+			if err := s.setInstants(appointment); err != nil {
+				return nil, err
+			}
+			if err := s.applyReliabilityPolicy(appointment, channel); err != nil {
+				return nil, err
+			}
+			toBook = append(toBook, appointment)
+		}
+
+		result.Occurrences = append(result.Occurrences, occurrence)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if result.Conflicts > 0 && !req.AllowPartial {
+		return result, fmt.Errorf("%d of %d occurrences are not available", result.Conflicts, len(result.Occurrences))
+	}
+
+	if len(toBook) == 0 {
+		return result, fmt.Errorf("none of the occurrences are available")
+	}
+
+	series := &AppointmentSeries{
+		ClientID:  req.ClientID,
+		ServiceID: req.ServiceID,
+		StartDate: startDate,
+		StartTime: req.StartTime,
+		RRule:     rule.String(),
+	}
+	if req.AttendedBy != "" {
+		series.AttendedBy = &req.AttendedBy
+	}
+
+	if err := s.repo.CreateSeries(series, toBook, requirements); err != nil {
+		return nil, err
+	}
+
+	booked := 0
+	for i := range result.Occurrences {
+		if result.Occurrences[i].Available {
+			result.Occurrences[i].AppointmentID = toBook[booked].ID
+			s.auditScreeningOverride(toBook[booked], req.ScreeningOverride, overridden)
+			s.publish(events.AppointmentCreated, toBook[booked], nil)
+			booked++
+		}
+	}
+
+	result.Series = series
+	result.Booked = booked
+
+	return result, nil
+}
+
+// validateOccurrence returns the specialist for one occurrence, or the
+// reason it cannot be booked. Without a requested specialist the previous
+// occurrence's specialist is kept when free, for continuity of treatment.
+func (s *AppointmentService) validateOccurrence(req CreateSeriesRequest, date time.Time, endTime, previous string, requirements []resources.Requirement) (string, string) {
+	if date.Before(s.today()) {
+		return "", "date is in the past"
+	}
+
+	if err := s.repo.CheckResourceCapacity(date, req.StartTime, endTime, requirements); err != nil {
+		return "", err.Error()
+	}
+
+	if previous != "" {
+		available, err := s.repo.CheckAvailability(date, req.StartTime, endTime, previous, req.ServiceID, "")
+		if err != nil {
+			return "", err.Error()
+		}
+		if available {
+			return previous, ""
+		}
+		if req.AttendedBy != "" {
+			return "", "the requested time slot is not available"
+		}
+	}
+
+	attendedBy, err := s.assignSpecialist(req.ServiceID, req.ClientID, date, req.StartTime, endTime)
+	if err != nil {
+		return "", err.Error()
+	}
+
+	return attendedBy, ""
+}
+
+// suggestAlternatives returns the available slots on the same day closest to
+// the requested start time.
+func (s *AppointmentService) suggestAlternatives(serviceID string, date time.Time, startTime, attendedBy string) []AvailabilitySlot {
+	slots, err := s.FindAvailableSlots(serviceID, date.Format("2006-01-02"), attendedBy)
+	if err != nil {
+		return nil
+	}
+
+	requested, _ := parseClock(startTime)
+
+	var available []AvailabilitySlot
+	for _, slot := range slots {
+		if slot.Available {
+			available = append(available, slot)
+		}
+	}
+
+	sort.SliceStable(available, func(i, j int) bool {
+		a, _ := parseClock(available[i].StartTime)
+		b, _ := parseClock(available[j].StartTime)
+		return abs(a-requested) < abs(b-requested)
+	})
+
+	if len(available) > maxAlternatives {
+		available = available[:maxAlternatives]
+	}
+
+	return available
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// UpdateSeries moves one occurrence or that occurrence and all following
+// ones. For "following", a date change shifts every later occurrence by the
+// same number of days, and a new start time or specialist replaces theirs.
+// Each occurrence is rescheduled as RescheduleAppointment would: the
+// service's policy applies to client requests and every move is recorded.
+// All occurrences are validated before any is moved. A non-nil version must
+// match the current version of the occurrence the request names.
+func (s *AppointmentService) UpdateSeries(id string, req UpdateSeriesRequest, version *int) ([]Appointment, error) {
+	scope := SeriesScope(req.Scope)
+	if !scope.IsValid() {
+		return nil, fmt.Errorf("invalid scope: %s", req.Scope)
+	}
+
+	requestedBy := CancelledByType(req.RequestedByType)
+	if !requestedBy.IsValid() {
+		return nil, fmt.Errorf("invalid requested_by_type: %s", req.RequestedByType)
+	}
+
+	current, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope == ScopeThis {
+		reschedule := RescheduleRequest{
+			AppointmentDate: current.AppointmentDate.Format("2006-01-02"),
+			StartTime:       current.StartTime[:5],
+			AttendedBy:      req.AttendedBy,
+			RequestedBy:     req.RequestedBy,
+			RequestedByType: req.RequestedByType,
+			Reason:          req.Reason,
+		}
+		if req.AppointmentDate != nil {
+			reschedule.AppointmentDate = *req.AppointmentDate
+		}
+		if req.StartTime != nil {
+			reschedule.StartTime = *req.StartTime
+		}
+
+		appointment, err := s.RescheduleAppointment(id, reschedule, version)
+		if err != nil {
+			return nil, err
+		}
+		return []Appointment{*appointment}, nil
+	}
+
+	if err := checkVersion(current, version); err != nil {
+		return nil, err
+	}
+
+	if current.SeriesID == nil || current.SeriesIndex == nil {
+		return nil, fmt.Errorf("appointment is not part of a series")
+	}
+
+	shiftDays := 0
+	if req.AppointmentDate != nil {
+		newDate, err := time.Parse("2006-01-02", *req.AppointmentDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
+		}
+		shiftDays = int(newDate.Sub(current.AppointmentDate).Hours() / 24)
+	}
+
+	if req.StartTime != nil && !s.isValidTimeFormat(*req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	occurrences, err := s.repo.GetSeriesOccurrences(*current.SeriesID, *current.SeriesIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := s.repo.GetServiceDuration(current.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(current.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	if req.AttendedBy != nil {
+		if err := s.ensureQualified(*req.AttendedBy, current.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	var policy *policies.Policy
+	if requestedBy == CancelledByClient {
+		policy, err = s.policyService.GetPolicy(current.ServiceID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	moving := make([]string, len(occurrences))
+	for i, occurrence := range occurrences {
+		moving[i] = occurrence.ID
+	}
+
+	moves := make([]rescheduleMove, len(occurrences))
+	for i := range occurrences {
+		previous := occurrences[i]
+		occurrence := previous
+
+		status := AppointmentStatus(occurrence.Status)
+		if status != StatusPending && status != StatusConfirmed {
+			return nil, fmt.Errorf("occurrence %d cannot be rescheduled because it is %s", *occurrence.SeriesIndex, occurrence.Status)
+		}
+
+		if policy != nil {
+			if err := policy.CheckReschedule(occurrence.StartsAt, s.clock.Now(), occurrence.RescheduleCount); err != nil {
+				return nil, fmt.Errorf("occurrence %d: %w", *occurrence.SeriesIndex, err)
+			}
+		}
+
+		occurrence.AppointmentDate = occurrence.AppointmentDate.AddDate(0, 0, shiftDays)
+		if occurrence.AppointmentDate.Before(s.today()) {
+			return nil, fmt.Errorf("occurrence %d would move to a past date", *occurrence.SeriesIndex)
+		}
+
+		if req.StartTime != nil {
+			occurrence.StartTime = *req.StartTime
+		} else {
+			occurrence.StartTime = occurrence.StartTime[:5]
+		}
+
+		occurrence.EndTime, err = s.calculateEndTime(occurrence.StartTime, duration)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating end time: %w", err)
+		}
+
+		if req.AttendedBy != nil {
+			occurrence.AttendedBy = req.AttendedBy
+		}
+
+		if occurrence.AttendedBy != nil {
+			available, err := s.repo.CheckAvailabilityExcluding(occurrence.AppointmentDate, occurrence.StartTime, occurrence.EndTime, *occurrence.AttendedBy, occurrence.ServiceID, moving)
+			if err != nil {
+				return nil, fmt.Errorf("error checking availability: %w", err)
+			}
+			if !available {
+				return nil, fmt.Errorf("occurrence %d: the requested time slot is not available", *occurrence.SeriesIndex)
+			}
+		}
+
+		if err := s.repo.CheckResourceCapacity(occurrence.AppointmentDate, occurrence.StartTime, occurrence.EndTime, requirements, moving...); err != nil {
+			return nil, fmt.Errorf("occurrence %d: %w", *occurrence.SeriesIndex, err)
+		}
+
+		if err := s.setInstants(&occurrence); err != nil {
+			return nil, err
+		}
+
+		moves[i] = rescheduleMove{
+			Appointment:  &occurrence,
+			Requirements: requirements,
+			Record: &RescheduleRecord{
+				AppointmentID:   occurrence.ID,
+				FromDate:        previous.AppointmentDate,
+				FromStartTime:   previous.StartTime,
+				FromAttendedBy:  previous.AttendedBy,
+				ToDate:          occurrence.AppointmentDate,
+				ToStartTime:     occurrence.StartTime,
+				ToAttendedBy:    occurrence.AttendedBy,
+				RequestedBy:     req.RequestedBy,
+				RequestedByType: req.RequestedByType,
+				Reason:          req.Reason,
+			},
+		}
+		if occurrence.ID == id {
+			moves[i].Version = version
+		}
+	}
+
+	if err := s.repo.RescheduleAppointments(moves); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.repo.GetSeriesOccurrences(*current.SeriesID, *current.SeriesIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range moved {
+		s.publish(events.AppointmentUpdated, &moved[i], &occurrences[i])
+	}
+
+	return moved, nil
+}
+
+// CancelSeries cancels one occurrence or that occurrence and all following
+// ones, each with its own audit entry, and returns the IDs of the occurrences
+// it cancelled. Cancellations that went through stay when a later one fails,
+// so the IDs are returned with the error too. A non-nil version must match
+// the current version of the occurrence the request names.
+func (s *AppointmentService) CancelSeries(id string, req CancelSeriesRequest, version *int) ([]string, error) {
+	scope := SeriesScope(req.Scope)
+	if !scope.IsValid() {
+		return nil, fmt.Errorf("invalid scope: %s", req.Scope)
+	}
+
+	cancelReq := CancelAppointmentRequest{
+		CancelledBy:     req.CancelledBy,
+		CancelledByType: req.CancelledByType,
+		Reason:          req.Reason,
+	}
+
+	cancelled := []string{}
+
+	if scope == ScopeThis {
+		if err := s.CancelAppointment(id, cancelReq, version); err != nil {
+			return cancelled, err
+		}
+		return append(cancelled, id), nil
+	}
+
+	current, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return cancelled, err
+	}
+
+	if err := checkVersion(current, version); err != nil {
+		return cancelled, err
+	}
+
+	if current.SeriesID == nil || current.SeriesIndex == nil {
+		return cancelled, fmt.Errorf("appointment is not part of a series")
+	}
+
+	occurrences, err := s.repo.GetSeriesOccurrences(*current.SeriesID, *current.SeriesIndex)
+	if err != nil {
+		return cancelled, err
+	}
+
+	for _, occurrence := range occurrences {
+		var expected *int
+		if occurrence.ID == id {
+			expected = version
+		}
+		if err := s.CancelAppointment(occurrence.ID, cancelReq, expected); err != nil {
+			return cancelled, fmt.Errorf("occurrence %d: %w", *occurrence.SeriesIndex, err)
+		}
+		cancelled = append(cancelled, occurrence.ID)
+	}
+
+	return cancelled, nil
+}
@@ -2,30 +2,78 @@ package appointments
 
 import (
 	"acme/audit"
+	"acme/clock"
+	"acme/config"
+	"acme/consent"
+	"acme/employees"
+	"acme/etag"
+	"acme/events"
+	"acme/health"
+	"acme/notifications"
+	"acme/packages"
+	"acme/policies"
+	"acme/resources"
+	"crypto/subtle"
 	"fmt"
+	"log"
+	"sort"
 	"strconv"
 	"time"
 )
 
+// defaultSlotMinutes is the slot length assumed when availability is
+// checked without a service.
+const defaultSlotMinutes = 60
+
 type AppointmentService struct {
-	repo        *Repository
-	auditService *audit.Service
+	repo                *Repository
+	auditService        *audit.Service
+	employeeService     *employees.EmployeeService
+	resourceService     *resources.ResourceService
+	packageService      *packages.PackageService
+	policyService       *policies.PolicyService
+	notificationService *notifications.NotificationService
+	healthService       *health.HealthService
+	consentService      *consent.ConsentService
+	events              *events.Bus
+	assignment          AssignmentStrategy
+	linkSecret          []byte
+	clock               clock.Clock
+	config              *config.Config
 }
 
-func NewService(repo *Repository, auditService *audit.Service) *AppointmentService {
+func NewService(repo *Repository, auditService *audit.Service, employeeService *employees.EmployeeService, resourceService *resources.ResourceService, packageService *packages.PackageService, policyService *policies.PolicyService, notificationService *notifications.NotificationService, healthService *health.HealthService, consentService *consent.ConsentService, eventBus *events.Bus, assignment AssignmentStrategy, clk clock.Clock, cfg *config.Config) *AppointmentService {
 	return &AppointmentService{
-		repo:        repo,
-		auditService: auditService,
+		repo:                repo,
+		auditService:        auditService,
+		employeeService:     employeeService,
+		resourceService:     resourceService,
+		packageService:      packageService,
+		policyService:       policyService,
+		notificationService: notificationService,
+		healthService:       healthService,
+		consentService:      consentService,
+		events:              eventBus,
+		assignment:          assignment,
+		linkSecret:          linkSecret(cfg.ActionLinks.Secret),
+		clock:               clk,
+		config:              cfg,
 	}
 }
 
 func (s *AppointmentService) CreateAppointment(req CreateAppointmentRequest) (*Appointment, error) {
+	return s.bookAppointment(req, "")
+}
+
+// bookAppointment creates the appointment, taking over the given hold when
+// one is set so the held interval does not count against it.
+func (s *AppointmentService) bookAppointment(req CreateAppointmentRequest, holdID string) (*Appointment, error) {
 	appointmentDate, err := time.Parse("2006-01-02", req.AppointmentDate)
 	if err != nil {
 		return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
 	}
 
-	if appointmentDate.Before(time.Now().Truncate(24 * time.Hour)) {
+	if appointmentDate.Before(s.today()) {
 		return nil, fmt.Errorf("cannot create appointments for past dates")
 	}
 
@@ -33,53 +81,193 @@ func (s *AppointmentService) CreateAppointment(req CreateAppointmentRequest) (*A
 		return nil, fmt.Errorf("invalid start time format, use HH:MM")
 	}
 
-	available, err := s.repo.CheckAvailability(appointmentDate, req.StartTime, req.AttendedBy, "")
+	channel, err := parseChannel(req.Channel)
 	if err != nil {
-		return nil, fmt.Errorf("error checking availability: %w", err)
+		return nil, err
 	}
 
-	if !available {
-		return nil, fmt.Errorf("the requested time slot is not available")
+	screening, overridden, err := s.screenClient(req.ClientID, req.ServiceID, req.ScreeningOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := s.repo.GetServiceDuration(req.ServiceID)
+	if err != nil {
+		return nil, err
 	}
 
-	endTime, err := s.calculateEndTime(req.StartTime)
+	endTime, err := s.calculateEndTime(req.StartTime, duration)
 	if err != nil {
 		return nil, fmt.Errorf("error calculating end time: %w", err)
 	}
 
+	requirements, err := s.resourceService.GetServiceRequirements(req.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	attendedBy := req.AttendedBy
+	if attendedBy == "" {
+		attendedBy, err = s.assignSpecialist(req.ServiceID, req.ClientID, appointmentDate, req.StartTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.ensureQualified(attendedBy, req.ServiceID); err != nil {
+			return nil, err
+		}
+
+		available, err := s.repo.CheckAvailability(appointmentDate, req.StartTime, endTime, attendedBy, req.ServiceID, holdID)
+		if err != nil {
+			return nil, fmt.Errorf("error checking availability: %w", err)
+		}
+
+		if !available {
+			return nil, fmt.Errorf("the requested time slot is not available")
+		}
+	}
+
 	appointment := &Appointment{
 		ClientID:        req.ClientID,
 		ServiceID:       req.ServiceID,
 		AppointmentDate: appointmentDate,
 		StartTime:       req.StartTime,
 		EndTime:         endTime,
-		AttendedBy:      &req.AttendedBy,
+		AttendedBy:      &attendedBy,
 		Status:          string(StatusPending),
+		Channel:         string(channel),
+	}
+
+	if err := s.setInstants(appointment); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyReliabilityPolicy(appointment, channel); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateAppointment(appointment, requirements, holdID); err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.CreateAppointment(appointment); err != nil {
-		return nil, fmt.Errorf("error creating appointment: %w", err)
+	if err := s.reservePackageSession(appointment, req.ClientPackageID); err != nil {
+		return nil, err
 	}
 
+	s.auditScreeningOverride(appointment, req.ScreeningOverride, overridden)
+	appointment.ScreeningWarnings = screening.Warnings
+	s.publish(events.AppointmentCreated, appointment, nil)
+
 	return appointment, nil
 }
 
-func (s *AppointmentService) calculateEndTime(startTime string) (string, error) {
-	startHour, startMin, err := s.parseTime(startTime)
+// parseChannel validates a booking channel, defaulting to staff. Handlers
+// set the channel from the caller, see CallerChannel.
+func parseChannel(value string) (BookingChannel, error) {
+	if value == "" {
+		return ChannelStaff, nil
+	}
+
+	channel := BookingChannel(value)
+	if !channel.IsValid() {
+		return "", fmt.Errorf("invalid channel: %s", value)
+	}
+	return channel, nil
+}
+
+// CallerChannel tells which channel a request books on from the chatbot
+// key it carries. Without a key the caller is staff; a key that does not
+// match the configured one is rejected.
+func (s *AppointmentService) CallerChannel(chatbotKey string) (BookingChannel, error) {
+	if chatbotKey == "" {
+		return ChannelStaff, nil
+	}
+	expected := s.config.Chatbot.APIKey
+	if expected == "" || subtle.ConstantTimeCompare([]byte(chatbotKey), []byte(expected)) != 1 {
+		return "", fmt.Errorf("invalid chatbot key")
+	}
+	return ChannelChatbot, nil
+}
+
+// reservePackageSession pays for a new appointment from the client's
+// package, removing the appointment again if the package cannot cover it.
+func (s *AppointmentService) reservePackageSession(appointment *Appointment, clientPackageID *string) error {
+	if clientPackageID == nil || *clientPackageID == "" {
+		return nil
+	}
+
+	err := s.packageService.ReserveSession(*clientPackageID, appointment.ClientID, appointment.ServiceID, appointment.ID)
+	if err != nil {
+		if delErr := s.repo.DeleteAppointment(appointment.ID); delErr != nil {
+			log.Printf("Warning: Failed to remove appointment %s after package reservation failed: %v", appointment.ID, delErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// assignSpecialist picks a qualified specialist who is free at the requested
+// slot using the configured assignment strategy.
+func (s *AppointmentService) assignSpecialist(serviceID, clientID string, date time.Time, startTime, endTime string) (string, error) {
+	qualified, err := s.employeeService.GetQualifiedEmployees(serviceID)
+	if err != nil {
+		return "", fmt.Errorf("error getting qualified employees: %w", err)
+	}
+
+	var free []string
+	for _, employee := range qualified {
+		available, err := s.repo.CheckAvailability(date, startTime, endTime, employee.ID, serviceID, "")
+		if err != nil {
+			return "", fmt.Errorf("error checking availability: %w", err)
+		}
+		if available {
+			free = append(free, employee.ID)
+		}
+	}
+
+	if len(free) == 0 {
+		return "", fmt.Errorf("no qualified specialist is available for the requested time slot")
+	}
+
+	candidates, err := s.repo.GetAssignmentCandidates(free, date, serviceID, clientID)
 	if err != nil {
 		return "", err
 	}
 
-	totalMinutes := startHour*60 + startMin + 60
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no qualified specialist is available for the requested time slot")
+	}
 
-	endHour := totalMinutes / 60
-	endMin := totalMinutes % 60
+	return s.assignment.Select(candidates), nil
+}
+
+func (s *AppointmentService) ensureQualified(employeeID, serviceID string) error {
+	qualified, err := s.employeeService.IsQualified(employeeID, serviceID)
+	if err != nil {
+		return fmt.Errorf("error checking specialist qualification: %w", err)
+	}
 
-	if endHour >= 24 {
-		endHour = endHour % 24
+	if !qualified {
+		return fmt.Errorf("the requested specialist is not qualified for this service")
 	}
 
-	return fmt.Sprintf("%02d:%02d", endHour, endMin), nil
+	return nil
+}
+
+func (s *AppointmentService) calculateEndTime(startTime string, durationMinutes int) (string, error) {
+	startHour, startMin, err := s.parseTime(startTime)
+	if err != nil {
+		return "", err
+	}
+
+	totalMinutes := startHour*60 + startMin + durationMinutes
+
+	if totalMinutes >= 24*60 {
+		return "", fmt.Errorf("appointment must end before midnight")
+	}
+
+	return formatClock(totalMinutes), nil
 }
 
 func (s *AppointmentService) parseTime(timeStr string) (int, int, error) {
@@ -117,107 +305,223 @@ func (s *AppointmentService) GetAppointmentWithDetails(id string) (*AppointmentW
 	return s.repo.GetAppointmentWithDetails(id)
 }
 
-func (s *AppointmentService) UpdateAppointment(id string, req UpdateAppointmentRequest) (*Appointment, error) {
-	if req.Status != nil {
-		status := AppointmentStatus(*req.Status)
-		if !status.IsValid() {
-			return nil, fmt.Errorf("invalid status: %s", *req.Status)
-		}
+func (s *AppointmentService) GetAppointmentsByDateRange(startDate, endDate string) ([]AppointmentWithDetails, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date format, use YYYY-MM-DD: %w", err)
 	}
 
-	if req.AppointmentDate != nil {
-		appointmentDate, err := time.Parse("2006-01-02", *req.AppointmentDate)
-		if err != nil {
-			return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
-		}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date format, use YYYY-MM-DD: %w", err)
+	}
 
-		if appointmentDate.Before(time.Now().Truncate(24 * time.Hour)) {
-			return nil, fmt.Errorf("cannot update to past dates")
-		}
+	if start.After(end) {
+		return nil, fmt.Errorf("start date cannot be after end date")
 	}
 
-	if req.StartTime != nil && !s.isValidTimeFormat(*req.StartTime) {
-		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	return s.repo.GetAppointmentsByDateRange(start, end)
+}
+
+func (s *AppointmentService) GetAppointmentsByClient(clientID string) ([]AppointmentWithDetails, error) {
+	return s.repo.GetAppointmentsByClient(clientID)
+}
+
+
+// CheckAvailability reports whether the specialist is free at the given
+// time. When a service is given, its duration and resource requirements are
+// taken into account; otherwise a default-length slot is checked.
+func (s *AppointmentService) CheckAvailability(date, startTime, attendedBy, serviceID string) (bool, error) {
+	appointmentDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
 	}
 
-	if (req.AppointmentDate != nil || req.StartTime != nil || req.AttendedBy != nil) && req.Status == nil {
-		currentAppointment, err := s.repo.GetAppointmentByID(id)
+	if !s.isValidTimeFormat(startTime) {
+		return false, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	duration := defaultSlotMinutes
+	if serviceID != "" {
+		duration, err = s.repo.GetServiceDuration(serviceID)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+	}
 
-		date := currentAppointment.AppointmentDate
-		if req.AppointmentDate != nil {
-			date, _ = time.Parse("2006-01-02", *req.AppointmentDate)
-		}
+	endTime, err := s.calculateEndTime(startTime, duration)
+	if err != nil {
+		return false, err
+	}
 
-		startTime := currentAppointment.StartTime
-		if req.StartTime != nil {
-			startTime = *req.StartTime
-		}
+	available, err := s.repo.CheckAvailability(appointmentDate, startTime, endTime, attendedBy, serviceID, "")
+	if err != nil || !available || serviceID == "" {
+		return available, err
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(serviceID)
+	if err != nil {
+		return false, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	if err := s.repo.CheckResourceCapacity(appointmentDate, startTime, endTime, requirements, ""); err != nil {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// FindAvailableSlots lists the candidate start times of a service on a date
+// within business hours. A slot is available when every required resource
+// has capacity left and the requested specialist, or any qualified one, is
+// on shift outside their breaks and can take the service alongside their
+// bookings under each service's concurrency rules. Scheduled group sessions
+// of the service are listed too, with their seat counts.
+func (s *AppointmentService) FindAvailableSlots(serviceID, date, attendedBy string) ([]AvailabilitySlot, error) {
+	appointmentDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+	}
 
-		attendedBy := ""
-		if currentAppointment.AttendedBy != nil {
-			attendedBy = *currentAppointment.AttendedBy
+	duration, err := s.repo.GetServiceDuration(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var employeeIDs []string
+	if attendedBy != "" {
+		if err := s.ensureQualified(attendedBy, serviceID); err != nil {
+			return nil, err
 		}
-		if req.AttendedBy != nil {
-			attendedBy = *req.AttendedBy
+		employeeIDs = []string{attendedBy}
+	} else {
+		qualified, err := s.employeeService.GetQualifiedEmployees(serviceID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting qualified employees: %w", err)
+		}
+		for _, employee := range qualified {
+			employeeIDs = append(employeeIDs, employee.ID)
 		}
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
 
-		available, err := s.repo.CheckAvailability(date, startTime, attendedBy, id)
+	resourceIDs := make([]string, len(requirements))
+	for i, requirement := range requirements {
+		resourceIDs[i] = requirement.ResourceID
+	}
+
+	rule, err := s.repo.GetConcurrencyRule(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	busy, err := s.repo.GetBusySegments(appointmentDate, employeeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	shifts := make(map[string]agendaShift, len(employeeIDs))
+	for _, employeeID := range employeeIDs {
+		schedule, err := s.employeeService.GetWorkSchedule(employeeID)
 		if err != nil {
-			return nil, fmt.Errorf("error checking availability: %w", err)
+			return nil, err
 		}
 
-		if !available {
-			return nil, fmt.Errorf("the requested time slot is not available")
+		weekly, err := s.weeklyShifts(schedule)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	if err := s.repo.UpdateAppointment(id, req); err != nil {
-		return nil, fmt.Errorf("error updating appointment: %w", err)
+		// A day off has no shift and so covers no slot
+		shifts[employeeID] = weekly[appointmentDate.Weekday()]
 	}
 
-	return s.repo.GetAppointmentByID(id)
-}
+	bookings, err := s.repo.GetResourceBookings(appointmentDate, resourceIDs)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *AppointmentService) GetAppointmentsByDateRange(startDate, endDate string) ([]AppointmentWithDetails, error) {
-	start, err := time.Parse("2006-01-02", startDate)
+	opening, err := parseClock(s.config.Scheduling.OpeningTime)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start date format, use YYYY-MM-DD: %w", err)
+		return nil, fmt.Errorf("invalid opening time configuration: %w", err)
 	}
 
-	end, err := time.Parse("2006-01-02", endDate)
+	closing, err := parseClock(s.config.Scheduling.ClosingTime)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end date format, use YYYY-MM-DD: %w", err)
+		return nil, fmt.Errorf("invalid closing time configuration: %w", err)
 	}
 
-	if start.After(end) {
-		return nil, fmt.Errorf("start date cannot be after end date")
+	step := s.config.Scheduling.SlotIntervalMinutes
+	if step <= 0 {
+		step = duration
 	}
 
-	return s.repo.GetAppointmentsByDateRange(start, end)
-}
+	slots := []AvailabilitySlot{}
+	for start := opening; start+duration <= closing; start += step {
+		slot := timeRange{Start: start, End: start + duration}
 
-func (s *AppointmentService) GetAppointmentsByClient(clientID string) ([]AppointmentWithDetails, error) {
-	return s.repo.GetAppointmentsByClient(clientID)
-}
+		available := true
+		for _, requirement := range requirements {
+			if peakUsage(slot, bookings[requirement.ResourceID])+requirement.Quantity > requirement.Capacity {
+				available = false
+				break
+			}
+		}
 
+		if available {
+			available = false
+			for _, employeeID := range employeeIDs {
+				if shifts[employeeID].covers(slot) && fits(rule.segments(slot), busy[employeeID]) {
+					available = true
+					break
+				}
+			}
+		}
 
-func (s *AppointmentService) CheckAvailability(date, startTime, attendedBy string) (bool, error) {
-	appointmentDate, err := time.Parse("2006-01-02", date)
+		slots = append(slots, AvailabilitySlot{
+			Date:      appointmentDate,
+			StartTime: formatClock(slot.Start),
+			EndTime:   formatClock(slot.End),
+			Available: available,
+		})
+	}
+
+	sessions, err := s.repo.GetSessions(serviceID, date)
 	if err != nil {
-		return false, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+		return nil, err
 	}
 
-	if !s.isValidTimeFormat(startTime) {
-		return false, fmt.Errorf("invalid start time format, use HH:MM")
+	for i := range sessions {
+		session := &sessions[i]
+		if attendedBy != "" && session.AttendedBy != attendedBy {
+			continue
+		}
+
+		slots = append(slots, AvailabilitySlot{
+			Date:           appointmentDate,
+			StartTime:      session.StartTime[:5],
+			EndTime:        session.EndTime[:5],
+			Available:      session.SeatsAvailable > 0,
+			SessionID:      &session.ID,
+			Capacity:       &session.Capacity,
+			SeatsAvailable: &session.SeatsAvailable,
+		})
 	}
 
-	return s.repo.CheckAvailability(appointmentDate, startTime, attendedBy, "")
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].StartTime < slots[j].StartTime
+	})
+
+	return slots, nil
 }
 
-func (s *AppointmentService) CancelAppointment(id string, req CancelAppointmentRequest) error {
+// CancelAppointment cancels an appointment under the service's policy. A
+// non-nil version must match the appointment's current one.
+func (s *AppointmentService) CancelAppointment(id string, req CancelAppointmentRequest, version *int) error {
 	cancelledByType := CancelledByType(req.CancelledByType)
 	if !cancelledByType.IsValid() {
 		return fmt.Errorf("invalid cancelled_by_type: %s", req.CancelledByType)
@@ -229,16 +533,45 @@ func (s *AppointmentService) CancelAppointment(id string, req CancelAppointmentR
 		return fmt.Errorf("error getting appointment: %w", err)
 	}
 
+	if err := checkVersion(currentAppointment, version); err != nil {
+		return err
+	}
+
 	if currentAppointment.Status == string(StatusCancelled) {
 		return fmt.Errorf("appointment is already cancelled")
 	}
 
+	from := AppointmentStatus(currentAppointment.Status)
+	if err := canTransition(from, StatusCancelled); err != nil {
+		return err
+	}
+
+	outcome, err := s.evaluateCancellation(currentAppointment, cancelledByType)
+	if err != nil {
+		return err
+	}
+
+	var fee *float64
+	if outcome.Fee > 0 {
+		fee = &outcome.Fee
+	}
+
 	// Cancel the appointment
-	err = s.repo.CancelAppointment(id, req.CancelledBy, req.CancelledByType, req.Reason)
+	err = s.repo.CancelAppointment(id, from, version, req.CancelledBy, req.CancelledByType, req.Reason, outcome.Late, fee)
 	if err != nil {
 		return fmt.Errorf("error cancelling appointment: %w", err)
 	}
 
+	if outcome.ForfeitSession {
+		if err := s.packageService.ForfeitSession(id); err != nil {
+			return fmt.Errorf("error forfeiting package session: %w", err)
+		}
+	} else if err := s.packageService.RestoreSession(id); err != nil {
+		return fmt.Errorf("error restoring package session: %w", err)
+	}
+
+	s.publishByID(events.AppointmentCancelled, id, nil)
+
 	// Log the cancellation in audit
 	auditReq := audit.CreateAuditLogRequest{
 		TableName:     "appointments",
@@ -250,6 +583,8 @@ func (s *AppointmentService) CancelAppointment(id string, req CancelAppointmentR
 			"cancelled_by":        req.CancelledBy,
 			"cancelled_by_type":   req.CancelledByType,
 			"cancellation_reason": req.Reason,
+			"late_cancellation":   outcome.Late,
+			"cancellation_fee":    fee,
 		},
 		ChangedBy:     req.CancelledBy,
 		ChangedByType: audit.ChangedByType(req.CancelledByType),
@@ -258,26 +593,40 @@ func (s *AppointmentService) CancelAppointment(id string, req CancelAppointmentR
 
 	if err := s.auditService.LogAction(auditReq); err != nil {
 		// Log error but don't fail the cancellation
-		fmt.Printf("Warning: Failed to log audit entry for appointment cancellation: %v\n", err)
+		log.Printf("Warning: Failed to log audit entry for appointment cancellation: %v", err)
+	}
+
+	// A freed seat leaves the specialist booked by the session
+	if currentAppointment.AttendedBy != nil && currentAppointment.SessionID == nil {
+		s.offerSlot(currentAppointment.ServiceID, currentAppointment.AppointmentDate, currentAppointment.StartTime, currentAppointment.EndTime, *currentAppointment.AttendedBy)
 	}
 
 	return nil
 }
 
-func (s *AppointmentService) CancelByClient(appointmentID, clientDNI, reason string) error {
+func (s *AppointmentService) CancelByClient(appointmentID, clientDNI, reason string, version *int) error {
 	req := CancelAppointmentRequest{
 		CancelledBy:     clientDNI,
 		CancelledByType: string(CancelledByClient),
 		Reason:          reason,
 	}
-	return s.CancelAppointment(appointmentID, req)
+	return s.CancelAppointment(appointmentID, req, version)
 }
 
-func (s *AppointmentService) CancelByEmployee(appointmentID, employeeEmail, reason string) error {
+func (s *AppointmentService) CancelByEmployee(appointmentID, employeeEmail, reason string, version *int) error {
 	req := CancelAppointmentRequest{
 		CancelledBy:     employeeEmail,
 		CancelledByType: string(CancelledByEmployee),
 		Reason:          reason,
 	}
-	return s.CancelAppointment(appointmentID, req)
+	return s.CancelAppointment(appointmentID, req, version)
+}
+
+// checkVersion fails with etag.ErrModified when the caller read another
+// version of the appointment than the current one.
+func checkVersion(appointment *Appointment, version *int) error {
+	if version != nil && *version != appointment.Version {
+		return etag.ErrModified
+	}
+	return nil
 }
\ No newline at end of file
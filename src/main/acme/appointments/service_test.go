@@ -0,0 +1,55 @@
+package appointments
+
+import (
+	"acme/clock"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestPastDateRejectedInLima(t *testing.T) {
+	lima, err := time.LoadLocation("America/Lima")
+	if err != nil {
+		t.Fatalf("loading America/Lima: %v", err)
+	}
+
+	const pastDate = "cannot create appointments for past dates"
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		date    string
+		wantErr string
+	}{
+		{"yesterday", time.Date(2025, 3, 10, 9, 0, 0, 0, lima), "2025-03-09", pastDate},
+		{"today", time.Date(2025, 3, 10, 9, 0, 0, 0, lima), "2025-03-10", ""},
+		{"today at 19:00, already tomorrow in UTC", time.Date(2025, 3, 10, 19, 0, 0, 0, lima), "2025-03-10", ""},
+		{"today at 23:59", time.Date(2025, 3, 10, 23, 59, 0, 0, lima), "2025-03-10", ""},
+		{"yesterday at 21:00", time.Date(2025, 3, 10, 21, 0, 0, 0, lima), "2025-03-09", pastDate},
+		{"tomorrow at 21:00", time.Date(2025, 3, 10, 21, 0, 0, 0, lima), "2025-03-11", ""},
+		{"after midnight", time.Date(2025, 3, 11, 0, 5, 0, 0, lima), "2025-03-10", pastDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AppointmentService{clock: clock.Fixed(tt.now)}
+
+			// The invalid start time stops a date that passes the check
+			// before anything needs the database.
+			_, err := s.CreateAppointment(CreateAppointmentRequest{AppointmentDate: tt.date, StartTime: "later"})
+			if err == nil {
+				t.Fatal("CreateAppointment() succeeded, want an error")
+			}
+
+			if tt.wantErr != "" {
+				if err.Error() != tt.wantErr {
+					t.Errorf("CreateAppointment() error = %q, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err.Error() == pastDate {
+				t.Errorf("CreateAppointment() rejected %s as a past date at %v", tt.date, tt.now)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package appointments
+
+import (
+	"acme/audit"
+	"acme/events"
+	"acme/notifications"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CreateSession schedules a group session. The specialist and the service's
+// resources are booked for the slot once, however many clients enroll.
+func (s *AppointmentService) CreateSession(req CreateSessionRequest) (*Session, error) {
+	sessionDate, err := time.Parse("2006-01-02", req.SessionDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if sessionDate.Before(s.today()) {
+		return nil, fmt.Errorf("cannot create sessions for past dates")
+	}
+
+	if !s.isValidTimeFormat(req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	duration, err := s.repo.GetServiceDuration(req.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := s.calculateEndTime(req.StartTime, duration)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating end time: %w", err)
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(req.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	attendedBy := req.AttendedBy
+	if attendedBy == "" {
+		attendedBy, err = s.assignSpecialist(req.ServiceID, "", sessionDate, req.StartTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+	} else if err := s.ensureQualified(attendedBy, req.ServiceID); err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		ServiceID:   req.ServiceID,
+		AttendedBy:  attendedBy,
+		SessionDate: sessionDate,
+		StartTime:   req.StartTime,
+		EndTime:     endTime,
+		Capacity:    req.Capacity,
+	}
+
+	if session.StartsAt, err = s.instant(sessionDate, req.StartTime); err != nil {
+		return nil, err
+	}
+	if session.EndsAt, err = s.instant(sessionDate, endTime); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateSession(session, requirements); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *AppointmentService) GetSession(id string) (*Session, error) {
+	return s.repo.GetSessionByID(id)
+}
+
+func (s *AppointmentService) GetSessions(serviceID, date string) ([]Session, error) {
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+		}
+	}
+
+	return s.repo.GetSessions(serviceID, date)
+}
+
+func (s *AppointmentService) GetSessionRoster(id string) (*SessionRoster, error) {
+	session, err := s.repo.GetSessionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.repo.GetSessionEnrollments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionRoster{Session: *session, Enrollments: enrollments}, nil
+}
+
+// EnrollInSession books a seat for the client. The enrollment is an
+// appointment for the session's slot, so it follows the usual status,
+// cancellation and package rules.
+func (s *AppointmentService) EnrollInSession(sessionID string, req EnrollRequest) (*Appointment, error) {
+	channel, err := parseChannel(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.repo.GetSessionByID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != string(SessionScheduled) {
+		return nil, fmt.Errorf("session has been cancelled")
+	}
+
+	if !s.clock.Now().Before(session.StartsAt) {
+		return nil, fmt.Errorf("session has already started")
+	}
+
+	screening, overridden, err := s.screenClient(req.ClientID, session.ServiceID, req.ScreeningOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	appointment := &Appointment{
+		ClientID:        req.ClientID,
+		ServiceID:       session.ServiceID,
+		AppointmentDate: session.SessionDate,
+		StartTime:       session.StartTime,
+		EndTime:         session.EndTime,
+		StartsAt:        session.StartsAt,
+		EndsAt:          session.EndsAt,
+		AttendedBy:      &session.AttendedBy,
+		Status:          string(StatusPending),
+		SessionID:       &session.ID,
+		Channel:         string(channel),
+	}
+
+	if err := s.applyReliabilityPolicy(appointment, channel); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.EnrollInSession(appointment); err != nil {
+		return nil, err
+	}
+
+	if err := s.reservePackageSession(appointment, req.ClientPackageID); err != nil {
+		return nil, err
+	}
+
+	s.auditScreeningOverride(appointment, req.ScreeningOverride, overridden)
+	appointment.ScreeningWarnings = screening.Warnings
+	s.publish(events.AppointmentCreated, appointment, nil)
+
+	return appointment, nil
+}
+
+// CancelSession cancels a session that has not started along with its
+// enrollments. Package sessions are given back, and every enrolled client is
+// notified. Clients are never charged when the spa cancels.
+func (s *AppointmentService) CancelSession(id string, req CancelSessionRequest) (*Session, error) {
+	session, err := s.repo.GetSessionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status == string(SessionCancelled) {
+		return nil, fmt.Errorf("session is already cancelled")
+	}
+
+	if !s.clock.Now().Before(session.StartsAt) {
+		return nil, fmt.Errorf("cannot cancel a session that has already started")
+	}
+
+	cancelled, err := s.repo.CancelSession(id, req.CancelledBy, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.repo.GetSessionEnrollments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]AppointmentWithDetails, len(enrollments))
+	for _, enrollment := range enrollments {
+		byID[enrollment.ID] = enrollment
+	}
+
+	for _, appointmentID := range cancelled {
+		appointment := byID[appointmentID]
+
+		if err := s.packageService.RestoreSession(appointmentID); err != nil {
+			log.Printf("Warning: Failed to restore package session for enrollment %s: %v", appointmentID, err)
+		}
+
+		auditReq := audit.CreateAuditLogRequest{
+			TableName: "appointments",
+			RecordID:  appointmentID,
+			Action:    audit.ActionCancel,
+			NewValues: map[string]interface{}{
+				"status":              "cancelled",
+				"session_id":          id,
+				"cancelled_by":        req.CancelledBy,
+				"cancelled_by_type":   string(CancelledByEmployee),
+				"cancellation_reason": req.Reason,
+			},
+			ChangedBy:     req.CancelledBy,
+			ChangedByType: audit.ChangedByEmployee,
+			Reason:        &req.Reason,
+		}
+		if err := s.auditService.LogAction(auditReq); err != nil {
+			log.Printf("Warning: Failed to log audit entry for enrollment %s: %v", appointmentID, err)
+		}
+		s.publishByID(events.AppointmentCancelled, appointmentID, nil)
+
+		_, err = s.notificationService.Notify(notifications.NotifyRequest{
+			ClientID:      appointment.ClientID,
+			AppointmentID: &appointment.ID,
+			Kind:          notifications.KindSessionCancelled,
+			Subject:       "Session cancelled",
+			Message: fmt.Sprintf("We are sorry, %s on %s at %s has been cancelled: %s",
+				appointment.ServiceName, session.SessionDate.Format("2006-01-02"), session.StartTime[:5], req.Reason),
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to notify client %s of cancelled session %s: %v", appointment.ClientID, id, err)
+		}
+	}
+
+	return s.repo.GetSessionByID(id)
+}
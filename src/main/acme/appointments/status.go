@@ -0,0 +1,195 @@
+package appointments
+
+import (
+	"acme/audit"
+	"acme/events"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// transitionRule describes how an appointment may enter a status: the
+// statuses it can come from and what the caller must supply.
+type transitionRule struct {
+	From          []AppointmentStatus
+	RequireActor  bool
+	RequireReason bool
+}
+
+// transitions is the appointment state machine, keyed by target status:
+//
+//	pending → confirmed → checked_in → in_progress → completed
+//
+// Walk-ins may be checked in without confirming, a checked-in client may be
+// completed directly, and appointments that have not started can end as
+// cancelled or no_show. completed, cancelled and no_show are final.
+var transitions = map[AppointmentStatus]transitionRule{
+	StatusConfirmed:  {From: []AppointmentStatus{StatusPending}},
+	StatusCheckedIn:  {From: []AppointmentStatus{StatusPending, StatusConfirmed}, RequireActor: true},
+	StatusInProgress: {From: []AppointmentStatus{StatusCheckedIn}, RequireActor: true},
+	StatusCompleted:  {From: []AppointmentStatus{StatusCheckedIn, StatusInProgress}, RequireActor: true},
+	StatusCancelled:  {From: []AppointmentStatus{StatusPending, StatusConfirmed}, RequireActor: true, RequireReason: true},
+	StatusNoShow:     {From: []AppointmentStatus{StatusPending, StatusConfirmed}, RequireActor: true, RequireReason: true},
+}
+
+func canTransition(from, to AppointmentStatus) error {
+	rule, ok := transitions[to]
+	if !ok {
+		return fmt.Errorf("invalid status: %s", to)
+	}
+
+	for _, allowed := range rule.From {
+		if allowed == from {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot change appointment status from %s to %s", from, to)
+}
+
+func (rule transitionRule) validate(to AppointmentStatus, req StatusChangeRequest) error {
+	if rule.RequireActor && (req.ChangedBy == "" || req.ChangedByType == "") {
+		return fmt.Errorf("changed_by and changed_by_type are required to mark an appointment as %s", to)
+	}
+
+	if req.ChangedByType != "" {
+		switch audit.ChangedByType(req.ChangedByType) {
+		case audit.ChangedByClient, audit.ChangedByEmployee, audit.ChangedBySystem:
+		default:
+			return fmt.Errorf("invalid changed_by_type: %s", req.ChangedByType)
+		}
+	}
+
+	if rule.RequireReason && (req.Reason == nil || strings.TrimSpace(*req.Reason) == "") {
+		return fmt.Errorf("a reason is required to mark an appointment as %s", to)
+	}
+
+	return nil
+}
+
+// checkTiming applies the guards that depend on when the appointment is:
+// clients can only be checked in on the day of the appointment, and a
+// no-show can only be recorded once the start time has passed.
+func checkTiming(appointment *Appointment, to AppointmentStatus, now time.Time) error {
+	switch to {
+	case StatusCheckedIn:
+		if appointment.AppointmentDate.Format("2006-01-02") != now.Format("2006-01-02") {
+			return fmt.Errorf("clients can only be checked in on the day of the appointment")
+		}
+	case StatusNoShow:
+		if now.Before(appointment.StartsAt) {
+			return fmt.Errorf("cannot mark a no-show before the appointment starts")
+		}
+	}
+	return nil
+}
+
+// TransitionStatus moves an appointment to a new status through the state
+// machine and records the change in its status history. A non-nil version
+// must match the appointment's current one.
+func (s *AppointmentService) TransitionStatus(id string, to AppointmentStatus, req StatusChangeRequest, version *int) (*Appointment, error) {
+	if to == StatusCancelled {
+		return nil, fmt.Errorf("use the cancel endpoints to cancel an appointment")
+	}
+
+	rule, ok := transitions[to]
+	if !ok {
+		return nil, fmt.Errorf("invalid status: %s", to)
+	}
+
+	if err := rule.validate(to, req); err != nil {
+		return nil, err
+	}
+
+	appointment, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(appointment, version); err != nil {
+		return nil, err
+	}
+
+	from := AppointmentStatus(appointment.Status)
+	if err := canTransition(from, to); err != nil {
+		return nil, err
+	}
+
+	if err := checkTiming(appointment, to, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	// The treatment starts when the appointment goes in progress, or when a
+	// checked-in client is completed directly.
+	if to == StatusInProgress || (to == StatusCompleted && from == StatusCheckedIn) {
+		if err := s.consentService.RequireConsent(id, appointment.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.TransitionStatus(id, from, to, version, optional(req.ChangedBy), optional(req.ChangedByType), req.Reason); err != nil {
+		return nil, err
+	}
+
+	switch to {
+	case StatusCompleted:
+		if err := s.packageService.RedeemSession(id); err != nil {
+			return nil, fmt.Errorf("error redeeming package session: %w", err)
+		}
+	case StatusNoShow:
+		if err := s.packageService.ForfeitSession(id); err != nil {
+			return nil, fmt.Errorf("error forfeiting package session: %w", err)
+		}
+	}
+
+	s.auditTransition(appointment, to, req)
+
+	updated, err := s.repo.GetAppointmentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(events.AppointmentStatusChanged, updated, nil)
+
+	return updated, nil
+}
+
+// auditTransition records a status change in the audit log. Changes made
+// without an actor, such as confirmations, are attributed to the system.
+func (s *AppointmentService) auditTransition(appointment *Appointment, to AppointmentStatus, req StatusChangeRequest) {
+	changedBy, changedByType := req.ChangedBy, audit.ChangedByType(req.ChangedByType)
+	if changedBy == "" || changedByType == "" {
+		changedBy, changedByType = string(audit.ChangedBySystem), audit.ChangedBySystem
+	}
+
+	auditReq := audit.CreateAuditLogRequest{
+		TableName:     "appointments",
+		RecordID:      appointment.ID,
+		Action:        audit.ActionUpdate,
+		OldValues:     map[string]interface{}{"status": appointment.Status},
+		NewValues:     map[string]interface{}{"status": to},
+		ChangedBy:     changedBy,
+		ChangedByType: changedByType,
+		Reason:        req.Reason,
+	}
+
+	if err := s.auditService.LogAction(auditReq); err != nil {
+		log.Printf("Warning: Failed to log audit entry for appointment status change: %v", err)
+	}
+}
+
+func (s *AppointmentService) GetStatusHistory(id string) ([]StatusHistory, error) {
+	if _, err := s.repo.GetAppointmentByID(id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetStatusHistory(id)
+}
+
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
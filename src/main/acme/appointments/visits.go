@@ -0,0 +1,224 @@
+package appointments
+
+import (
+	"acme/events"
+	"acme/health"
+	"acme/resources"
+	"fmt"
+	"log"
+	"time"
+)
+
+// CreateVisit books a client's services back to back on one date. Every
+// line gets its own specialist, assigned when none is requested. If any line
+// cannot be booked, or a package cannot pay for it, nothing is booked.
+func (s *AppointmentService) CreateVisit(req CreateVisitRequest) (*Visit, error) {
+	appointmentDate, err := time.Parse("2006-01-02", req.AppointmentDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid appointment date format, use YYYY-MM-DD: %w", err)
+	}
+
+	if appointmentDate.Before(s.today()) {
+		return nil, fmt.Errorf("cannot create appointments for past dates")
+	}
+
+	if !s.isValidTimeFormat(req.StartTime) {
+		return nil, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	channel, err := parseChannel(req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]*Appointment, len(req.Lines))
+	requirements := make([][]resources.Requirement, len(req.Lines))
+	overridden := make([][]health.Condition, len(req.Lines))
+	var warnings []string
+	startTime := req.StartTime
+
+	for i, lineReq := range req.Lines {
+		screening, lineOverridden, err := s.screenClient(req.ClientID, lineReq.ServiceID, lineReq.ScreeningOverride)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		for _, warning := range screening.Warnings {
+			warnings = append(warnings, fmt.Sprintf("line %d: %s", i+1, warning))
+		}
+		overridden[i] = lineOverridden
+
+		line, lineRequirements, err := s.prepareVisitLine(req.ClientID, appointmentDate, startTime, lineReq)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		line.Channel = string(channel)
+		if i == 0 {
+			if err := s.applyReliabilityPolicy(line, channel); err != nil {
+				return nil, err
+			}
+		} else {
+			line.DepositRequired = lines[0].DepositRequired
+		}
+
+		lines[i] = line
+		requirements[i] = lineRequirements
+		startTime = line.EndTime
+	}
+
+	visit := &Visit{
+		ClientID:        req.ClientID,
+		AppointmentDate: appointmentDate,
+	}
+
+	if err := s.repo.CreateVisit(visit, lines, requirements); err != nil {
+		return nil, err
+	}
+
+	if err := s.reserveVisitPackages(visit.ID, lines, req.Lines); err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		s.auditScreeningOverride(line, req.Lines[i].ScreeningOverride, overridden[i])
+		s.publish(events.AppointmentCreated, line, nil)
+	}
+
+	booked, err := s.GetVisit(visit.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	booked.ScreeningWarnings = warnings
+	return booked, nil
+}
+
+// prepareVisitLine builds the appointment for one line starting at
+// startTime and picks its specialist.
+func (s *AppointmentService) prepareVisitLine(clientID string, date time.Time, startTime string, req VisitLineRequest) (*Appointment, []resources.Requirement, error) {
+	duration, err := s.repo.GetServiceDuration(req.ServiceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endTime, err := s.calculateEndTime(startTime, duration)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error calculating end time: %w", err)
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(req.ServiceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting service resources: %w", err)
+	}
+
+	attendedBy := req.AttendedBy
+	if attendedBy == "" {
+		attendedBy, err = s.assignSpecialist(req.ServiceID, clientID, date, startTime, endTime)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if err := s.ensureQualified(attendedBy, req.ServiceID); err != nil {
+			return nil, nil, err
+		}
+
+		available, err := s.repo.CheckAvailability(date, startTime, endTime, attendedBy, req.ServiceID, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("error checking availability: %w", err)
+		}
+		if !available {
+			return nil, nil, fmt.Errorf("the requested time slot is not available")
+		}
+	}
+
+	line := &Appointment{
+		ClientID:        clientID,
+		ServiceID:       req.ServiceID,
+		AppointmentDate: date,
+		StartTime:       startTime,
+		EndTime:         endTime,
+		AttendedBy:      &attendedBy,
+		Status:          string(StatusPending),
+	}
+
+	if err := s.setInstants(line); err != nil {
+		return nil, nil, err
+	}
+
+	return line, requirements, nil
+}
+
+// reserveVisitPackages pays for the lines that name a package. When one
+// reservation fails the ones already made are given back and the whole visit
+// is removed.
+func (s *AppointmentService) reserveVisitPackages(visitID string, lines []*Appointment, reqs []VisitLineRequest) error {
+	var reserved []string
+	for i, req := range reqs {
+		if req.ClientPackageID == nil || *req.ClientPackageID == "" {
+			continue
+		}
+
+		line := lines[i]
+		err := s.packageService.ReserveSession(*req.ClientPackageID, line.ClientID, line.ServiceID, line.ID)
+		if err == nil {
+			reserved = append(reserved, line.ID)
+			continue
+		}
+
+		for _, appointmentID := range reserved {
+			if restoreErr := s.packageService.RestoreSession(appointmentID); restoreErr != nil {
+				log.Printf("Warning: Failed to restore package session for appointment %s: %v", appointmentID, restoreErr)
+			}
+		}
+		if delErr := s.repo.DeleteVisit(visitID); delErr != nil {
+			log.Printf("Warning: Failed to remove visit %s after package reservation failed: %v", visitID, delErr)
+		}
+		return fmt.Errorf("line %d: %w", i+1, err)
+	}
+
+	return nil
+}
+
+// GetVisit returns the visit with its lines. The times span all lines and
+// the total counts the lines that are not cancelled.
+func (s *AppointmentService) GetVisit(id string) (*Visit, error) {
+	visit, err := s.repo.GetVisitByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range visit.Lines {
+		if i == 0 {
+			visit.StartTime = line.StartTime
+		}
+		visit.EndTime = line.EndTime
+
+		if line.Status != string(StatusCancelled) {
+			visit.Total += line.ServicePrice
+		}
+	}
+
+	return visit, nil
+}
+
+// CancelVisit cancels every line of the visit that can still be cancelled,
+// applying each service's policy, and returns the updated visit.
+func (s *AppointmentService) CancelVisit(id string, req CancelAppointmentRequest) (*Visit, error) {
+	visit, err := s.repo.GetVisitByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range visit.Lines {
+		status := AppointmentStatus(line.Status)
+		if status != StatusPending && status != StatusConfirmed {
+			continue
+		}
+
+		if err := s.CancelAppointment(line.ID, req, nil); err != nil {
+			return nil, fmt.Errorf("line %d: %w", *line.VisitPosition, err)
+		}
+	}
+
+	return s.GetVisit(id)
+}
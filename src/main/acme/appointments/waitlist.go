@@ -0,0 +1,241 @@
+package appointments
+
+import (
+	"acme/notifications"
+	"fmt"
+	"log"
+	"time"
+)
+
+func (s *AppointmentService) CreateWaitlistEntry(req CreateWaitlistEntryRequest) (*WaitlistEntry, error) {
+	dateFrom, err := time.Parse("2006-01-02", req.DateFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date_from format, use YYYY-MM-DD: %w", err)
+	}
+
+	dateTo, err := time.Parse("2006-01-02", req.DateTo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date_to format, use YYYY-MM-DD: %w", err)
+	}
+
+	if dateFrom.After(dateTo) {
+		return nil, fmt.Errorf("date_from cannot be after date_to")
+	}
+
+	if dateTo.Before(s.today()) {
+		return nil, fmt.Errorf("cannot join the waitlist for past dates")
+	}
+
+	for _, bound := range []*string{req.EarliestTime, req.LatestTime} {
+		if bound != nil && !s.isValidTimeFormat(*bound) {
+			return nil, fmt.Errorf("invalid time format, use HH:MM")
+		}
+	}
+
+	if req.EarliestTime != nil && req.LatestTime != nil && *req.EarliestTime > *req.LatestTime {
+		return nil, fmt.Errorf("earliest_time cannot be after latest_time")
+	}
+
+	if _, err := s.repo.GetServiceDuration(req.ServiceID); err != nil {
+		return nil, err
+	}
+
+	if req.AttendedBy != nil {
+		if err := s.ensureQualified(*req.AttendedBy, req.ServiceID); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &WaitlistEntry{
+		ClientID:     req.ClientID,
+		ServiceID:    req.ServiceID,
+		AttendedBy:   req.AttendedBy,
+		DateFrom:     dateFrom,
+		DateTo:       dateTo,
+		EarliestTime: req.EarliestTime,
+		LatestTime:   req.LatestTime,
+		Priority:     req.Priority,
+	}
+
+	if err := s.repo.CreateWaitlistEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetWaitlistEntry returns the entry together with the offer it currently
+// holds, if any.
+func (s *AppointmentService) GetWaitlistEntry(id string) (*WaitlistEntry, error) {
+	entry, err := s.repo.GetWaitlistEntryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Offer, err = s.repo.GetActiveHoldForEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *AppointmentService) GetWaitlist(serviceID, clientID string) ([]WaitlistEntry, error) {
+	return s.repo.GetWaitlist(serviceID, clientID)
+}
+
+// CancelWaitlistEntry takes a client off the waitlist. A pending offer is
+// released and passed on to the next client.
+func (s *AppointmentService) CancelWaitlistEntry(id string) error {
+	if _, err := s.repo.GetWaitlistEntryByID(id); err != nil {
+		return err
+	}
+
+	offer, err := s.repo.GetActiveHoldForEntry(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetWaitlistStatus(id, WaitlistCancelled, WaitlistWaiting, WaitlistOffered); err != nil {
+		return fmt.Errorf("waitlist entry is no longer open")
+	}
+
+	if offer != nil {
+		if err := s.repo.ReleaseHold(offer.ID); err != nil {
+			return err
+		}
+		s.passOffer(offer)
+	}
+
+	return nil
+}
+
+// AcceptOffer books the slot held for the entry on behalf of its client.
+func (s *AppointmentService) AcceptOffer(id string, req AcceptOfferRequest) (*Appointment, error) {
+	entry, err := s.repo.GetWaitlistEntryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := s.repo.GetActiveHoldForEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer == nil {
+		return nil, fmt.Errorf("no active offer for this waitlist entry")
+	}
+
+	appointment, err := s.bookHold(offer, entry.ClientID, req.ClientPackageID, req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetWaitlistStatus(id, WaitlistBooked, WaitlistOffered); err != nil {
+		log.Printf("Warning: Failed to close waitlist entry %s after booking: %v", id, err)
+	}
+
+	return appointment, nil
+}
+
+// DeclineOffer releases the slot held for the entry and offers it to the
+// next client. The entry stays on the waitlist for other slots.
+func (s *AppointmentService) DeclineOffer(id string) error {
+	if _, err := s.repo.GetWaitlistEntryByID(id); err != nil {
+		return err
+	}
+
+	offer, err := s.repo.GetActiveHoldForEntry(id)
+	if err != nil {
+		return err
+	}
+
+	if offer == nil {
+		return fmt.Errorf("no active offer for this waitlist entry")
+	}
+
+	return s.ReleaseHold(offer.ID)
+}
+
+// passOffer returns the client of a released or expired offer to the
+// waitlist and offers the slot to the next client in line.
+func (s *AppointmentService) passOffer(offer *Hold) {
+	if offer.WaitlistEntryID != nil {
+		// The entry may have been cancelled meanwhile; then it stays closed
+		_ = s.repo.SetWaitlistStatus(*offer.WaitlistEntryID, WaitlistWaiting, WaitlistOffered)
+	}
+
+	s.offerSlot(offer.ServiceID, offer.AppointmentDate, offer.StartTime, offer.EndTime, offer.AttendedBy)
+}
+
+// offerSlot holds a freed slot for the highest-priority matching client on
+// the waitlist. Failures are logged so they never undo the cancellation
+// that freed the slot.
+func (s *AppointmentService) offerSlot(serviceID string, date time.Time, startTime, endTime, attendedBy string) {
+	entry, err := s.repo.GetNextWaitlistCandidate(serviceID, date, startTime, attendedBy)
+	if err != nil {
+		log.Printf("Warning: Failed to find waitlist candidate: %v", err)
+		return
+	}
+
+	if entry == nil {
+		return
+	}
+
+	requirements, err := s.resourceService.GetServiceRequirements(serviceID)
+	if err != nil {
+		log.Printf("Warning: Failed to get resources for waitlist offer: %v", err)
+		return
+	}
+
+	hold := &Hold{
+		ServiceID:       serviceID,
+		ClientID:        &entry.ClientID,
+		AttendedBy:      attendedBy,
+		AppointmentDate: date,
+		StartTime:       startTime,
+		EndTime:         endTime,
+		WaitlistEntryID: &entry.ID,
+	}
+
+	if err := s.repo.CreateHold(hold, s.config.Scheduling.WaitlistOfferMinutes, requirements); err != nil {
+		log.Printf("Warning: Failed to hold slot for waitlist entry %s: %v", entry.ID, err)
+		return
+	}
+
+	if err := s.repo.SetWaitlistStatus(entry.ID, WaitlistOffered, WaitlistWaiting); err != nil {
+		log.Printf("Warning: Failed to offer slot to waitlist entry %s: %v", entry.ID, err)
+		if err := s.repo.ReleaseHold(hold.ID); err != nil {
+			log.Printf("Warning: Failed to release hold %s: %v", hold.ID, err)
+		}
+		return
+	}
+
+	log.Printf("Offered %s %s to waitlist entry %s until %s",
+		date.Format("2006-01-02"), startTime, entry.ID, hold.ExpiresAt.Format(time.RFC3339))
+
+	s.notifyOffer(entry, hold)
+}
+
+// notifyOffer tells the client about the slot held for them and how to
+// accept or decline it before the hold expires.
+func (s *AppointmentService) notifyOffer(entry *WaitlistEntry, hold *Hold) {
+	serviceName, err := s.repo.GetServiceName(hold.ServiceID)
+	if err != nil {
+		log.Printf("Warning: Failed to notify waitlist entry %s of its offer: %v", entry.ID, err)
+		return
+	}
+
+	_, err = s.notificationService.Notify(notifications.NotifyRequest{
+		ClientID: entry.ClientID,
+		Kind:     notifications.KindWaitlistOffer,
+		Subject:  "A slot has opened up",
+		Message: fmt.Sprintf("A %s slot on %s at %s is being held for you until %s. "+
+			"Accept it with POST /api/v1/waitlist/%s/accept or decline it with POST /api/v1/waitlist/%s/decline.",
+			serviceName, hold.AppointmentDate.Format("2006-01-02"), hold.StartTime[:5],
+			hold.ExpiresAt.In(s.clock.Location()).Format("2006-01-02 15:04"), entry.ID, entry.ID),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to notify waitlist entry %s of its offer: %v", entry.ID, err)
+	}
+}
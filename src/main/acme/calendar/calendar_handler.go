@@ -0,0 +1,151 @@
+package calendar
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contentType = "text/calendar; charset=utf-8"
+
+type CalendarHandler struct {
+	service *CalendarService
+}
+
+func NewCalendarHandler(service *CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// GetAppointmentCalendar godoc
+// @Summary Download an appointment as iCalendar
+// @Description Get an .ics file with the appointment, for the client's "add to calendar" link
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path string true "Appointment ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /appointments/{id}/calendar.ics [get]
+func (h *CalendarHandler) GetAppointmentCalendar(c *gin.Context) {
+	id := c.Param("id")
+
+	ics, err := h.service.AppointmentCalendar(id)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%s.ics"`, id))
+	c.Data(http.StatusOK, contentType, ics)
+}
+
+// CreateEmployeeFeed godoc
+// @Summary Create an employee calendar feed
+// @Description Issue a subscribable feed URL with the specialist's bookings; any previous URL stops working
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 201 {object} Feed
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/calendar-feed [post]
+func (h *CalendarHandler) CreateEmployeeFeed(c *gin.Context) {
+	h.createFeed(c, OwnerEmployee)
+}
+
+// RevokeEmployeeFeed godoc
+// @Summary Revoke an employee calendar feed
+// @Description Stop serving the specialist's feed URL
+// @Tags calendar
+// @Param id path string true "Employee ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/calendar-feed [delete]
+func (h *CalendarHandler) RevokeEmployeeFeed(c *gin.Context) {
+	h.revokeFeed(c, OwnerEmployee)
+}
+
+// CreateClientFeed godoc
+// @Summary Create a client calendar feed
+// @Description Issue a subscribable feed URL with the client's appointments; any previous URL stops working
+// @Tags calendar
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 201 {object} Feed
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/calendar-feed [post]
+func (h *CalendarHandler) CreateClientFeed(c *gin.Context) {
+	h.createFeed(c, OwnerClient)
+}
+
+// RevokeClientFeed godoc
+// @Summary Revoke a client calendar feed
+// @Description Stop serving the client's feed URL
+// @Tags calendar
+// @Param id path string true "Client ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/calendar-feed [delete]
+func (h *CalendarHandler) RevokeClientFeed(c *gin.Context) {
+	h.revokeFeed(c, OwnerClient)
+}
+
+func (h *CalendarHandler) createFeed(c *gin.Context, ownerType OwnerType) {
+	feed, err := h.service.CreateFeed(ownerType, c.Param("id"))
+	if err != nil {
+		switch err.Error() {
+		case "employee not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		case "client not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+func (h *CalendarHandler) revokeFeed(c *gin.Context, ownerType OwnerType) {
+	if err := h.service.RevokeFeed(ownerType, c.Param("id")); err != nil {
+		if err.Error() == "calendar feed not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetFeed godoc
+// @Summary Get a calendar feed
+// @Description Serve a subscribable iCalendar feed; the token in the URL is the only credential
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally followed by .ics"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Router /calendar-feeds/{token} [get]
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ics, err := h.service.FeedCalendar(token)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, ics)
+}
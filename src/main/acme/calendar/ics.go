@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// calendarFile is an RFC 5545 iCalendar object with its events' times
+// written in the business timezone.
+type calendarFile struct {
+	Name     string
+	Location *time.Location
+	Stamp    time.Time
+	Events   []event
+}
+
+func (f *calendarFile) render() []byte {
+	var buf bytes.Buffer
+	line := func(format string, args ...interface{}) {
+		writeLine(&buf, fmt.Sprintf(format, args...))
+	}
+
+	fixed, offset, abbreviation := fixedOffset(f.Location, f.Stamp)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ACME//Appointments//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:%s", escapeText(f.Name))
+	line("X-WR-TIMEZONE:%s", f.Location.String())
+
+	// Zones without daylight saving, such as America/Lima, are described
+	// with a single offset. Others fall back to UTC times.
+	if fixed {
+		line("BEGIN:VTIMEZONE")
+		line("TZID:%s", f.Location.String())
+		line("BEGIN:STANDARD")
+		line("DTSTART:19700101T000000")
+		line("TZOFFSETFROM:%s", formatOffset(offset))
+		line("TZOFFSETTO:%s", formatOffset(offset))
+		line("TZNAME:%s", abbreviation)
+		line("END:STANDARD")
+		line("END:VTIMEZONE")
+	}
+
+	stamp := f.Stamp.UTC().Format("20060102T150405Z")
+	for _, e := range f.Events {
+		line("BEGIN:VEVENT")
+		line("UID:%s", e.UID)
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART%s", f.dateTime(e.Date, e.StartTime, fixed))
+		line("DTEND%s", f.dateTime(e.Date, e.EndTime, fixed))
+		line("SEQUENCE:%d", e.Sequence)
+		line("STATUS:%s", e.Status)
+		line("SUMMARY:%s", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:%s", escapeText(e.Description))
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// dateTime formats a local date and clock time as a DTSTART or DTEND value,
+// including the separator after the property name.
+func (f *calendarFile) dateTime(date time.Time, clockTime string, fixed bool) string {
+	var hour, minute int
+	fmt.Sscanf(clockTime, "%d:%d", &hour, &minute)
+
+	year, month, day := date.Date()
+	local := time.Date(year, month, day, hour, minute, 0, 0, f.Location)
+	if fixed {
+		return ";TZID=" + f.Location.String() + ":" + local.Format("20060102T150405")
+	}
+	return ":" + local.UTC().Format("20060102T150405Z")
+}
+
+// fixedOffset reports whether the location keeps one UTC offset all year
+// round, checking the year of at and the next one.
+func fixedOffset(location *time.Location, at time.Time) (bool, int, string) {
+	abbreviation, offset := time.Date(at.Year(), 1, 1, 0, 0, 0, 0, location).Zone()
+	for month := 1; month <= 24; month++ {
+		if _, other := time.Date(at.Year(), time.Month(month), 1, 0, 0, 0, 0, location).Zone(); other != offset {
+			return false, 0, ""
+		}
+	}
+	return true, offset, abbreviation
+}
+
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine writes a content line, folding it so no line is longer than 75
+// octets without splitting a UTF-8 character.
+func writeLine(buf *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		buf.WriteString(s[:cut])
+		buf.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = 74
+	}
+	buf.WriteString(s)
+	buf.WriteString("\r\n")
+}
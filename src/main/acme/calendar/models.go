@@ -0,0 +1,50 @@
+package calendar
+
+import (
+	"time"
+)
+
+type OwnerType string
+
+const (
+	OwnerEmployee OwnerType = "employee"
+	OwnerClient   OwnerType = "client"
+)
+
+// Feed is a subscribable calendar of an employee's or a client's bookings.
+// Only a hash of the token is stored, so Token and URL are only filled in
+// when the feed is created.
+type Feed struct {
+	ID        string    `json:"id" db:"id"`
+	OwnerType string    `json:"owner_type" db:"owner_type"`
+	OwnerID   string    `json:"owner_id" db:"owner_id"`
+	Token     string    `json:"token,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// booking is an appointment or a group session as read for a calendar.
+type booking struct {
+	ID           string
+	Kind         string // appointment or session
+	Date         time.Time
+	StartTime    string
+	EndTime      string
+	Status       string
+	Sequence     int
+	ServiceName  string
+	ClientName   string
+	EmployeeName *string
+}
+
+// event is a booking as it is written to an iCalendar file.
+type event struct {
+	UID         string
+	Sequence    int
+	Date        time.Time
+	StartTime   string
+	EndTime     string
+	Status      string
+	Summary     string
+	Description string
+}
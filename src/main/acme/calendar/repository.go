@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetOwnerName returns the name of the employee or client a feed belongs to.
+func (r *Repository) GetOwnerName(ownerType OwnerType, ownerID string) (string, error) {
+	query := `SELECT name || ' ' || paternal_surname FROM employees WHERE id = $1`
+	if ownerType == OwnerClient {
+		query = `SELECT first_name || ' ' || last_name FROM clients WHERE id = $1`
+	}
+
+	var name string
+	if err := r.db.QueryRow(query, ownerID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("%s not found", ownerType)
+		}
+		return "", fmt.Errorf("error getting %s: %w", ownerType, err)
+	}
+
+	return name, nil
+}
+
+// SaveFeed creates the owner's feed, or replaces the token of the existing
+// one so the old link stops working.
+func (r *Repository) SaveFeed(feed *Feed, tokenHash string) error {
+	query := `
+		INSERT INTO calendar_feeds (owner_type, owner_id, token_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (owner_type, owner_id)
+		DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(query, feed.OwnerType, feed.OwnerID, tokenHash).Scan(&feed.ID, &feed.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving calendar feed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteFeed(ownerType OwnerType, ownerID string) error {
+	result, err := r.db.Exec(`DELETE FROM calendar_feeds WHERE owner_type = $1 AND owner_id = $2`, ownerType, ownerID)
+	if err != nil {
+		return fmt.Errorf("error deleting calendar feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("calendar feed not found")
+	}
+
+	return nil
+}
+
+func (r *Repository) GetFeedByTokenHash(tokenHash string) (*Feed, error) {
+	feed := &Feed{}
+	query := `SELECT id, owner_type, owner_id, created_at FROM calendar_feeds WHERE token_hash = $1`
+
+	err := r.db.QueryRow(query, tokenHash).Scan(&feed.ID, &feed.OwnerType, &feed.OwnerID, &feed.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, fmt.Errorf("error getting calendar feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// appointmentBookings selects appointments as bookings; queries alias the
+// appointments table as "a".
+const appointmentBookings = `
+		SELECT a.id, 'appointment', a.appointment_date, a.start_time, a.end_time, a.status, a.calendar_sequence,
+		       s.name, c.first_name || ' ' || c.last_name, e.name || ' ' || e.paternal_surname
+		FROM appointments a
+		JOIN services s ON s.id = a.service_id
+		JOIN clients c ON c.id = a.client_id
+		LEFT JOIN employees e ON e.id = a.attended_by`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBooking(row rowScanner, b *booking) error {
+	return row.Scan(
+		&b.ID,
+		&b.Kind,
+		&b.Date,
+		&b.StartTime,
+		&b.EndTime,
+		&b.Status,
+		&b.Sequence,
+		&b.ServiceName,
+		&b.ClientName,
+		&b.EmployeeName,
+	)
+}
+
+func (r *Repository) GetAppointmentBooking(appointmentID string) (*booking, error) {
+	b := &booking{}
+	if err := scanBooking(r.db.QueryRow(appointmentBookings+` WHERE a.id = $1`, appointmentID), b); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("appointment not found")
+		}
+		return nil, fmt.Errorf("error getting appointment: %w", err)
+	}
+
+	return b, nil
+}
+
+// GetClientBookings returns the client's appointments from a date on,
+// cancelled ones included.
+func (r *Repository) GetClientBookings(clientID string, since time.Time) ([]booking, error) {
+	query := appointmentBookings + `
+		WHERE a.client_id = $1 AND a.appointment_date >= $2
+		ORDER BY a.appointment_date ASC, a.start_time ASC`
+
+	return r.queryBookings(query, clientID, since)
+}
+
+// GetEmployeeBookings returns the specialist's private appointments and the
+// group sessions they lead from a date on, cancelled ones included.
+func (r *Repository) GetEmployeeBookings(employeeID string, since time.Time) ([]booking, error) {
+	query := appointmentBookings + `
+		WHERE a.attended_by = $1 AND a.appointment_date >= $2 AND a.session_id IS NULL
+		UNION ALL
+		SELECT cs.id, 'session', cs.session_date, cs.start_time, cs.end_time, cs.status,
+		       CASE WHEN cs.status = 'cancelled' THEN 1 ELSE 0 END,
+		       s.name, '', e.name || ' ' || e.paternal_surname
+		FROM class_sessions cs
+		JOIN services s ON s.id = cs.service_id
+		JOIN employees e ON e.id = cs.attended_by
+		WHERE cs.attended_by = $1 AND cs.session_date >= $2
+		ORDER BY 3 ASC, 4 ASC`
+
+	return r.queryBookings(query, employeeID, since)
+}
+
+func (r *Repository) queryBookings(query string, args ...interface{}) ([]booking, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying calendar bookings: %w", err)
+	}
+	defer rows.Close()
+
+	var bookings []booking
+	for rows.Next() {
+		var b booking
+		if err := scanBooking(rows, &b); err != nil {
+			return nil, fmt.Errorf("error scanning calendar booking: %w", err)
+		}
+		bookings = append(bookings, b)
+	}
+
+	return bookings, rows.Err()
+}
@@ -0,0 +1,149 @@
+package calendar
+
+import (
+	"acme/clock"
+	"acme/config"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type CalendarService struct {
+	repo   *Repository
+	config config.CalendarConfig
+	clock  clock.Clock
+}
+
+func NewService(repo *Repository, cfg config.CalendarConfig, clk clock.Clock) *CalendarService {
+	return &CalendarService{repo: repo, config: cfg, clock: clk}
+}
+
+// AppointmentCalendar returns an iCalendar file with the single appointment,
+// for the client to add to their calendar.
+func (s *CalendarService) AppointmentCalendar(appointmentID string) ([]byte, error) {
+	b, err := s.repo.GetAppointmentBooking(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &calendarFile{
+		Name:     b.ServiceName,
+		Location: s.clock.Location(),
+		Stamp:    s.clock.Now(),
+		Events:   []event{s.event(*b, OwnerClient)},
+	}
+
+	return file.render(), nil
+}
+
+// CreateFeed issues a new feed token for the employee or client, replacing
+// any previous one. The token is part of the URL and is only shown here.
+func (s *CalendarService) CreateFeed(ownerType OwnerType, ownerID string) (*Feed, error) {
+	if _, err := s.repo.GetOwnerName(ownerType, ownerID); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("error generating feed token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	feed := &Feed{
+		OwnerType: string(ownerType),
+		OwnerID:   ownerID,
+		Token:     token,
+		URL:       strings.TrimRight(s.config.PublicURL, "/") + "/calendar-feeds/" + token + ".ics",
+	}
+
+	if err := s.repo.SaveFeed(feed, hashToken(token)); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (s *CalendarService) RevokeFeed(ownerType OwnerType, ownerID string) error {
+	return s.repo.DeleteFeed(ownerType, ownerID)
+}
+
+// FeedCalendar returns the feed's bookings from FeedPastDays ago on. It is
+// built on every request, so subscribed calendars pick up moved and
+// cancelled bookings on their next refresh.
+func (s *CalendarService) FeedCalendar(token string) ([]byte, error) {
+	feed, err := s.repo.GetFeedByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	ownerType := OwnerType(feed.OwnerType)
+	name, err := s.repo.GetOwnerName(ownerType, feed.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := clock.Today(s.clock).AddDate(0, 0, -s.config.FeedPastDays)
+
+	var bookings []booking
+	if ownerType == OwnerEmployee {
+		bookings, err = s.repo.GetEmployeeBookings(feed.OwnerID, since)
+	} else {
+		bookings, err = s.repo.GetClientBookings(feed.OwnerID, since)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := &calendarFile{
+		Name:     "ACME - " + name,
+		Location: s.clock.Location(),
+		Stamp:    s.clock.Now(),
+	}
+	for _, b := range bookings {
+		file.Events = append(file.Events, s.event(b, ownerType))
+	}
+
+	return file.render(), nil
+}
+
+// event describes the booking from the point of view of the calendar's
+// owner: clients see the service and their specialist, specialists see the
+// service and the client.
+func (s *CalendarService) event(b booking, viewer OwnerType) event {
+	e := event{
+		UID:       fmt.Sprintf("%s-%s@%s", b.Kind, b.ID, s.config.UIDDomain),
+		Sequence:  b.Sequence,
+		Date:      b.Date,
+		StartTime: b.StartTime,
+		EndTime:   b.EndTime,
+		Summary:   b.ServiceName,
+	}
+
+	switch b.Status {
+	case "cancelled":
+		e.Status = "CANCELLED"
+	case "pending":
+		e.Status = "TENTATIVE"
+	default:
+		e.Status = "CONFIRMED"
+	}
+
+	switch {
+	case viewer == OwnerClient && b.EmployeeName != nil:
+		e.Description = "Specialist: " + *b.EmployeeName
+	case viewer == OwnerEmployee && b.Kind == "session":
+		e.Summary = b.ServiceName + " (group session)"
+	case viewer == OwnerEmployee:
+		e.Summary = b.ServiceName + " - " + b.ClientName
+	}
+
+	return e
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,12 @@
 package catalog
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"acme/etag"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +28,10 @@ func (h *CatalogHandler) CreateService(c *gin.Context) {
 
 	service, err := h.service.CreateService(req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid concurrency rule") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -44,7 +52,7 @@ func (h *CatalogHandler) GetServiceByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, service)
+	etag.JSON(c, http.StatusOK, etag.Version(service.Version), service)
 }
 
 // GetAllServices godoc
@@ -62,41 +70,72 @@ func (h *CatalogHandler) GetAllServices(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, services)
+	tag, err := etag.Of(services)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	etag.JSON(c, http.StatusOK, tag, services)
 }
 
 
 func (h *CatalogHandler) UpdateService(c *gin.Context) {
 	id := c.Param("id")
 
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req UpdateServiceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	service, err := h.service.UpdateService(id, req)
+	service, err := h.service.UpdateService(id, req, version)
 	if err != nil {
 		if err.Error() == "service not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 			return
 		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid concurrency rule") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", etag.Version(service.Version))
 	c.JSON(http.StatusOK, service)
 }
 
 func (h *CatalogHandler) DeleteService(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.service.DeleteService(id)
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.service.DeleteService(id, version)
 	if err != nil {
 		if err.Error() == "service not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
 			return
 		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -131,6 +170,12 @@ func (h *CatalogHandler) GetServicesByPriceRange(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, services)
+	tag, err := etag.Of(services)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	etag.JSON(c, http.StatusOK, tag, services)
 }
 
@@ -4,6 +4,19 @@ import (
 	"time"
 )
 
+// Concurrency modes say how a service occupies its specialist.
+const (
+	// ConcurrencyExclusive services keep the specialist from any other work.
+	ConcurrencyExclusive = "exclusive"
+	// ConcurrencyParallel services can overlap other parallel work of the
+	// specialist, up to MaxConcurrent bookings at once.
+	ConcurrencyParallel = "parallel"
+	// ConcurrencyProcessing services leave the specialist free for
+	// ProcessingMinutes, starting ProcessingStartMinutes into the service,
+	// e.g. while a mask sets.
+	ConcurrencyProcessing = "processing"
+)
+
 type Service struct {
 	ID                   string    `json:"id" db:"id"`
 	Name                 string    `json:"name" db:"name"`
@@ -14,8 +27,10 @@ type Service struct {
 	RecommendedFrequency *string   `json:"recommended_frequency" db:"recommended_frequency"`
 	Includes             *string   `json:"includes" db:"includes"`
 	Contraindications    *string   `json:"contraindications" db:"contraindications"`
+	Version              int       `json:"version" db:"version"`
 	CreatedAt            time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+	ConcurrencyRule
 }
 
 type CreateServiceRequest struct {
@@ -27,15 +42,34 @@ type CreateServiceRequest struct {
 	RecommendedFrequency *string `json:"recommended_frequency"`
 	Includes             *string `json:"includes"`
 	Contraindications    *string `json:"contraindications"`
+	// The concurrency fields are optional; services are exclusive by default.
+	ConcurrencyMode        *string `json:"concurrency_mode"`
+	MaxConcurrent          *int    `json:"max_concurrent"`
+	ProcessingStartMinutes *int    `json:"processing_start_minutes"`
+	ProcessingMinutes      *int    `json:"processing_minutes"`
 }
 
 type UpdateServiceRequest struct {
-	Name                 *string  `json:"name"`
-	Price                *float64 `json:"price"`
-	DurationMinutes      *int     `json:"duration_minutes"`
-	Description          *string  `json:"description"`
-	Benefits             *string  `json:"benefits"`
-	RecommendedFrequency *string  `json:"recommended_frequency"`
-	Includes             *string  `json:"includes"`
-	Contraindications    *string  `json:"contraindications"`
-}
\ No newline at end of file
+	Name                   *string  `json:"name"`
+	Price                  *float64 `json:"price"`
+	DurationMinutes        *int     `json:"duration_minutes"`
+	Description            *string  `json:"description"`
+	Benefits               *string  `json:"benefits"`
+	RecommendedFrequency   *string  `json:"recommended_frequency"`
+	Includes               *string  `json:"includes"`
+	Contraindications      *string  `json:"contraindications"`
+	ConcurrencyMode        *string  `json:"concurrency_mode"`
+	MaxConcurrent          *int     `json:"max_concurrent"`
+	ProcessingStartMinutes *int     `json:"processing_start_minutes"`
+	ProcessingMinutes      *int     `json:"processing_minutes"`
+}
+
+// ConcurrencyRule says whether the specialist can take other bookings while
+// performing the service. MaxConcurrent only applies to parallel services and
+// the processing gap only to processing ones.
+type ConcurrencyRule struct {
+	ConcurrencyMode        string `json:"concurrency_mode" db:"concurrency_mode"`
+	MaxConcurrent          int    `json:"max_concurrent" db:"max_concurrent"`
+	ProcessingStartMinutes int    `json:"processing_start_minutes" db:"processing_start_minutes"`
+	ProcessingMinutes      int    `json:"processing_minutes" db:"processing_minutes"`
+}
@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"acme/etag"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -17,9 +18,10 @@ func NewRepository(db *sql.DB) *Repository {
 func (r *Repository) CreateService(service *Service) error {
 	query := `
 		INSERT INTO services (name, price, duration_minutes, description, benefits, 
-		                     recommended_frequency, includes, contraindications)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at`
+		                     recommended_frequency, includes, contraindications,
+		                     concurrency_mode, max_concurrent, processing_start_minutes, processing_minutes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, version, created_at, updated_at`
 
 	err := r.db.QueryRow(
 		query,
@@ -31,8 +33,13 @@ func (r *Repository) CreateService(service *Service) error {
 		service.RecommendedFrequency,
 		service.Includes,
 		service.Contraindications,
+		service.ConcurrencyMode,
+		service.MaxConcurrent,
+		service.ProcessingStartMinutes,
+		service.ProcessingMinutes,
 	).Scan(
 		&service.ID,
+		&service.Version,
 		&service.CreatedAt,
 		&service.UpdatedAt,
 	)
@@ -49,7 +56,8 @@ func (r *Repository) GetServiceByID(id string) (*Service, error) {
 	query := `
 		SELECT id, name, price, duration_minutes, description, benefits, 
 		       recommended_frequency, includes, contraindications, 
-		       created_at, updated_at
+		       concurrency_mode, max_concurrent, processing_start_minutes, processing_minutes,
+		       version, created_at, updated_at
 		FROM services WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
@@ -62,6 +70,11 @@ func (r *Repository) GetServiceByID(id string) (*Service, error) {
 		&service.RecommendedFrequency,
 		&service.Includes,
 		&service.Contraindications,
+		&service.ConcurrencyMode,
+		&service.MaxConcurrent,
+		&service.ProcessingStartMinutes,
+		&service.ProcessingMinutes,
+		&service.Version,
 		&service.CreatedAt,
 		&service.UpdatedAt,
 	)
@@ -80,7 +93,8 @@ func (r *Repository) GetAllServices() ([]Service, error) {
 	query := `
 		SELECT id, name, price, duration_minutes, description, benefits, 
 		       recommended_frequency, includes, contraindications, 
-		       created_at, updated_at
+		       concurrency_mode, max_concurrent, processing_start_minutes, processing_minutes,
+		       version, created_at, updated_at
 		FROM services ORDER BY name ASC`
 
 	rows, err := r.db.Query(query)
@@ -102,6 +116,11 @@ func (r *Repository) GetAllServices() ([]Service, error) {
 			&service.RecommendedFrequency,
 			&service.Includes,
 			&service.Contraindications,
+			&service.ConcurrencyMode,
+			&service.MaxConcurrent,
+			&service.ProcessingStartMinutes,
+			&service.ProcessingMinutes,
+			&service.Version,
 			&service.CreatedAt,
 			&service.UpdatedAt,
 		)
@@ -114,7 +133,9 @@ func (r *Repository) GetAllServices() ([]Service, error) {
 	return services, nil
 }
 
-func (r *Repository) UpdateService(id string, updates UpdateServiceRequest) error {
+// UpdateService applies the changes. When version is set the update only
+// succeeds if the service is still at that version.
+func (r *Repository) UpdateService(id string, updates UpdateServiceRequest, version *int) error {
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -159,6 +180,26 @@ func (r *Repository) UpdateService(id string, updates UpdateServiceRequest) erro
 		args = append(args, updates.Contraindications)
 		argIndex++
 	}
+	if updates.ConcurrencyMode != nil {
+		setParts = append(setParts, fmt.Sprintf("concurrency_mode = $%d", argIndex))
+		args = append(args, *updates.ConcurrencyMode)
+		argIndex++
+	}
+	if updates.MaxConcurrent != nil {
+		setParts = append(setParts, fmt.Sprintf("max_concurrent = $%d", argIndex))
+		args = append(args, *updates.MaxConcurrent)
+		argIndex++
+	}
+	if updates.ProcessingStartMinutes != nil {
+		setParts = append(setParts, fmt.Sprintf("processing_start_minutes = $%d", argIndex))
+		args = append(args, *updates.ProcessingStartMinutes)
+		argIndex++
+	}
+	if updates.ProcessingMinutes != nil {
+		setParts = append(setParts, fmt.Sprintf("processing_minutes = $%d", argIndex))
+		args = append(args, *updates.ProcessingMinutes)
+		argIndex++
+	}
 
 	if len(setParts) == 0 {
 		return fmt.Errorf("no fields to update")
@@ -168,34 +209,58 @@ func (r *Repository) UpdateService(id string, updates UpdateServiceRequest) erro
 		strings.Join(setParts, ", "), argIndex)
 	args = append(args, id)
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	if version != nil {
+		query += fmt.Sprintf(" AND version = $%d", argIndex+1)
+		args = append(args, *version)
+	}
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	return r.checkVersioned(id, result, version)
 }
 
-func (r *Repository) DeleteService(id string) error {
-	query := `DELETE FROM services WHERE id = $1`
-	result, err := r.db.Exec(query, id)
+func (r *Repository) DeleteService(id string, version *int) error {
+	query := `DELETE FROM services WHERE id = $1 AND ($2::integer IS NULL OR version = $2)`
+	result, err := r.db.Exec(query, id, version)
 	if err != nil {
 		return fmt.Errorf("error deleting service: %w", err)
 	}
 
+	return r.checkVersioned(id, result, version)
+}
+
+// checkVersioned tells a missing service apart from one whose version no
+// longer matches when a statement affected no rows.
+func (r *Repository) checkVersioned(id string, result sql.Result, version *int) error {
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("error getting rows affected: %w", err)
 	}
 
-	if rowsAffected == 0 {
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	if version == nil {
 		return fmt.Errorf("service not found")
 	}
 
-	return nil
+	if _, err := r.GetServiceByID(id); err != nil {
+		return err
+	}
+
+	return etag.ErrModified
 }
 
 func (r *Repository) GetServicesByPriceRange(minPrice, maxPrice float64) ([]Service, error) {
 	query := `
 		SELECT id, name, price, duration_minutes, description, benefits, 
 		       recommended_frequency, includes, contraindications, 
-		       created_at, updated_at
+		       concurrency_mode, max_concurrent, processing_start_minutes, processing_minutes,
+		       version, created_at, updated_at
 		FROM services 
 		WHERE price BETWEEN $1 AND $2 
 		ORDER BY price ASC`
@@ -219,6 +284,11 @@ func (r *Repository) GetServicesByPriceRange(minPrice, maxPrice float64) ([]Serv
 			&service.RecommendedFrequency,
 			&service.Includes,
 			&service.Contraindications,
+			&service.ConcurrencyMode,
+			&service.MaxConcurrent,
+			&service.ProcessingStartMinutes,
+			&service.ProcessingMinutes,
+			&service.Version,
 			&service.CreatedAt,
 			&service.UpdatedAt,
 		)
@@ -1,6 +1,7 @@
 package catalog
 
 import (
+	"acme/etag"
 	"fmt"
 )
 
@@ -15,6 +16,12 @@ func NewService(repo *Repository) *CatalogService {
 }
 
 func (s *CatalogService) CreateService(req CreateServiceRequest) (*Service, error) {
+	rule, err := buildConcurrencyRule(ConcurrencyRule{ConcurrencyMode: ConcurrencyExclusive, MaxConcurrent: 1},
+		req.ConcurrencyMode, req.MaxConcurrent, req.ProcessingStartMinutes, req.ProcessingMinutes, req.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+
 	service := &Service{
 		Name:                 req.Name,
 		Price:                req.Price,
@@ -24,6 +31,7 @@ func (s *CatalogService) CreateService(req CreateServiceRequest) (*Service, erro
 		RecommendedFrequency: req.RecommendedFrequency,
 		Includes:             req.Includes,
 		Contraindications:    req.Contraindications,
+		ConcurrencyRule:      rule,
 	}
 
 	if err := s.repo.CreateService(service); err != nil {
@@ -41,16 +49,46 @@ func (s *CatalogService) GetAllServices() ([]Service, error) {
 	return s.repo.GetAllServices()
 }
 
-func (s *CatalogService) UpdateService(id string, req UpdateServiceRequest) (*Service, error) {
-	if err := s.repo.UpdateService(id, req); err != nil {
+// UpdateService applies the changes, optionally only if the service is still
+// at the given version.
+func (s *CatalogService) UpdateService(id string, req UpdateServiceRequest, version *int) (*Service, error) {
+	if req.DurationMinutes != nil || req.ConcurrencyMode != nil || req.MaxConcurrent != nil ||
+		req.ProcessingStartMinutes != nil || req.ProcessingMinutes != nil {
+		current, err := s.repo.GetServiceByID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := current.DurationMinutes
+		if req.DurationMinutes != nil {
+			duration = *req.DurationMinutes
+		}
+
+		rule, err := buildConcurrencyRule(current.ConcurrencyRule,
+			req.ConcurrencyMode, req.MaxConcurrent, req.ProcessingStartMinutes, req.ProcessingMinutes, duration)
+		if err != nil {
+			return nil, err
+		}
+
+		// Write the whole rule so fields a mode change resets are cleared
+		req.ConcurrencyMode = &rule.ConcurrencyMode
+		req.MaxConcurrent = &rule.MaxConcurrent
+		req.ProcessingStartMinutes = &rule.ProcessingStartMinutes
+		req.ProcessingMinutes = &rule.ProcessingMinutes
+	}
+
+	if err := s.repo.UpdateService(id, req, version); err != nil {
+		if err == etag.ErrModified || err.Error() == "service not found" {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error updating service: %w", err)
 	}
 
 	return s.repo.GetServiceByID(id)
 }
 
-func (s *CatalogService) DeleteService(id string) error {
-	return s.repo.DeleteService(id)
+func (s *CatalogService) DeleteService(id string, version *int) error {
+	return s.repo.DeleteService(id, version)
 }
 
 func (s *CatalogService) GetServicesByPriceRange(minPrice, maxPrice float64) ([]Service, error) {
@@ -62,4 +100,45 @@ func (s *CatalogService) GetServicesByPriceRange(minPrice, maxPrice float64) ([]
 	}
 
 	return s.repo.GetServicesByPriceRange(minPrice, maxPrice)
-}
\ No newline at end of file
+}
+
+// buildConcurrencyRule applies the requested changes to rule and checks the
+// result against the service duration. Fields that do not apply to the mode
+// are reset.
+func buildConcurrencyRule(rule ConcurrencyRule, mode *string, maxConcurrent, processingStart, processingMinutes *int, duration int) (ConcurrencyRule, error) {
+	if mode != nil {
+		rule.ConcurrencyMode = *mode
+	}
+	if maxConcurrent != nil {
+		rule.MaxConcurrent = *maxConcurrent
+	}
+	if processingStart != nil {
+		rule.ProcessingStartMinutes = *processingStart
+	}
+	if processingMinutes != nil {
+		rule.ProcessingMinutes = *processingMinutes
+	}
+
+	switch rule.ConcurrencyMode {
+	case ConcurrencyExclusive:
+		rule.MaxConcurrent = 1
+		rule.ProcessingStartMinutes, rule.ProcessingMinutes = 0, 0
+	case ConcurrencyParallel:
+		if rule.MaxConcurrent < 2 {
+			return rule, fmt.Errorf("invalid concurrency rule: parallel services need a max_concurrent of at least 2")
+		}
+		rule.ProcessingStartMinutes, rule.ProcessingMinutes = 0, 0
+	case ConcurrencyProcessing:
+		if rule.ProcessingMinutes <= 0 || rule.ProcessingStartMinutes < 0 {
+			return rule, fmt.Errorf("invalid concurrency rule: processing services need a positive processing_minutes and a non-negative processing_start_minutes")
+		}
+		if rule.ProcessingMinutes >= duration || rule.ProcessingStartMinutes+rule.ProcessingMinutes > duration {
+			return rule, fmt.Errorf("invalid concurrency rule: the processing time must fall within the service duration")
+		}
+		rule.MaxConcurrent = 1
+	default:
+		return rule, fmt.Errorf("invalid concurrency rule: concurrency_mode must be exclusive, parallel or processing")
+	}
+
+	return rule, nil
+}
@@ -2,12 +2,25 @@ package catalog
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"acme/appointments"
 	"acme/audit"
+	"acme/calendar"
+	"acme/clock"
 	"acme/config"
+	"acme/consent"
 	"acme/employees"
+	"acme/events"
+	"acme/health"
 	"acme/iam"
+	"acme/idempotency"
+	"acme/notifications"
+	"acme/packages"
+	"acme/policies"
+	"acme/resources"
+	"acme/treatments"
 )
 
 // ServiceFactory implements the Factory pattern for creating services
@@ -28,46 +41,115 @@ func (f *ServiceFactory) CreateServices() (*AppServices, error) {
 	catalogRepo := NewRepository(f.db)
 	appointmentsRepo := appointments.NewRepository(f.db)
 	employeesRepo := employees.NewRepository(f.db)
+	resourcesRepo := resources.NewRepository(f.db)
+	packagesRepo := packages.NewRepository(f.db)
+	policiesRepo := policies.NewRepository(f.db)
+	idempotencyRepo := idempotency.NewRepository(f.db)
+	notificationsRepo := notifications.NewRepository(f.db)
+	treatmentsRepo := treatments.NewRepository(f.db)
+	healthRepo := health.NewRepository(f.db)
+	consentRepo := consent.NewRepository(f.db)
+	calendarRepo := calendar.NewRepository(f.db)
 
 	// Create services with dependencies
 	auditService := audit.NewService(auditRepo)
 	iamService := iam.NewService(iamRepo, f.config)
 	catalogService := NewService(catalogRepo)
-	appointmentsService := appointments.NewService(appointmentsRepo, auditService)
 	employeesService := employees.NewService(employeesRepo)
+	resourcesService := resources.NewService(resourcesRepo)
+	policiesService := policies.NewService(policiesRepo, f.config)
+	idempotencyService := idempotency.NewService(idempotencyRepo)
+	notificationsService := notifications.NewService(notificationsRepo, notifications.NewLogChannel())
+	treatmentsService := treatments.NewService(treatmentsRepo)
+	healthService := health.NewService(healthRepo, f.config)
+
+	assignmentStrategy, err := appointments.NewAssignmentStrategy(f.config.Scheduling.AssignmentStrategy)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(f.config.Scheduling.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business timezone %q: %w", f.config.Scheduling.Timezone, err)
+	}
+	businessClock := clock.New(location)
+	packagesService := packages.NewService(packagesRepo, businessClock)
+	consentService := consent.NewService(consentRepo, consent.NewDiskStore(f.config.Consent.StorageDir), businessClock)
+	calendarService := calendar.NewService(calendarRepo, f.config.Calendar, businessClock)
+	eventBus := events.NewBus(f.config.Events, businessClock)
+	appointmentsService := appointments.NewService(appointmentsRepo, auditService, employeesService, resourcesService, packagesService, policiesService, notificationsService, healthService, consentService, eventBus, assignmentStrategy, businessClock, f.config)
 
 	return &AppServices{
-		Audit:        auditService,
-		IAM:          iamService,
-		Catalog:      catalogService,
-		Appointments: appointmentsService,
-		Employees:    employeesService,
+		Audit:         auditService,
+		IAM:           iamService,
+		Catalog:       catalogService,
+		Appointments:  appointmentsService,
+		Employees:     employeesService,
+		Resources:     resourcesService,
+		Packages:      packagesService,
+		Policies:      policiesService,
+		Idempotency:   idempotencyService,
+		Notifications: notificationsService,
+		Treatments:    treatmentsService,
+		Health:        healthService,
+		Consent:       consentService,
+		Calendar:      calendarService,
+		Events:        eventBus,
 	}, nil
 }
 
 // CreateHandlers creates all HTTP handlers
 func (f *ServiceFactory) CreateHandlers(services *AppServices) *AppHandlers {
 	return &AppHandlers{
-		IAM:          iam.NewIAMHandler(services.IAM),
-		Catalog:      NewCatalogHandler(services.Catalog),
-		Appointments: appointments.NewAppointmentsHandler(services.Appointments),
-		Employees:    employees.NewEmployeesHandler(services.Employees),
+		IAM:           iam.NewIAMHandler(services.IAM),
+		Catalog:       NewCatalogHandler(services.Catalog),
+		Appointments:  appointments.NewAppointmentsHandler(services.Appointments),
+		Employees:     employees.NewEmployeesHandler(services.Employees),
+		Resources:     resources.NewResourcesHandler(services.Resources),
+		Packages:      packages.NewPackagesHandler(services.Packages),
+		Policies:      policies.NewPoliciesHandler(services.Policies),
+		Idempotency:   idempotency.NewIdempotencyHandler(services.Idempotency),
+		Notifications: notifications.NewNotificationsHandler(services.Notifications),
+		Treatments:    treatments.NewTreatmentsHandler(services.Treatments),
+		Health:        health.NewHealthHandler(services.Health),
+		Consent:       consent.NewConsentHandler(services.Consent),
+		Calendar:      calendar.NewCalendarHandler(services.Calendar),
+		Events:        events.NewEventsHandler(services.Events),
 	}
 }
 
 // AppServices holds all application services
 type AppServices struct {
-	Audit        *audit.Service
-	IAM          *iam.IAMService
-	Catalog      *CatalogService
-	Appointments *appointments.AppointmentService
-	Employees    *employees.EmployeeService
+	Audit         *audit.Service
+	IAM           *iam.IAMService
+	Catalog       *CatalogService
+	Appointments  *appointments.AppointmentService
+	Employees     *employees.EmployeeService
+	Resources     *resources.ResourceService
+	Packages      *packages.PackageService
+	Policies      *policies.PolicyService
+	Idempotency   *idempotency.Service
+	Notifications *notifications.NotificationService
+	Treatments    *treatments.TreatmentService
+	Health        *health.HealthService
+	Consent       *consent.ConsentService
+	Calendar      *calendar.CalendarService
+	Events        *events.Bus
 }
 
 // AppHandlers holds all HTTP handlers
 type AppHandlers struct {
-	IAM          *iam.IAMHandler
-	Catalog      *CatalogHandler
-	Appointments *appointments.AppointmentsHandler
-	Employees    *employees.EmployeesHandler
+	IAM           *iam.IAMHandler
+	Catalog       *CatalogHandler
+	Appointments  *appointments.AppointmentsHandler
+	Employees     *employees.EmployeesHandler
+	Resources     *resources.ResourcesHandler
+	Packages      *packages.PackagesHandler
+	Policies      *policies.PoliciesHandler
+	Idempotency   *idempotency.IdempotencyHandler
+	Notifications *notifications.NotificationsHandler
+	Treatments    *treatments.TreatmentsHandler
+	Health        *health.HealthHandler
+	Consent       *consent.ConsentHandler
+	Calendar      *calendar.CalendarHandler
+	Events        *events.EventsHandler
 }
@@ -0,0 +1,55 @@
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the current time in the business timezone. Services take a
+// Clock instead of calling time.Now so that date rules can be exercised at
+// any moment.
+type Clock interface {
+	Now() time.Time
+	Location() *time.Location
+}
+
+type systemClock struct {
+	location *time.Location
+}
+
+// New returns the system clock in the given location.
+func New(location *time.Location) Clock {
+	return systemClock{location: location}
+}
+
+func (c systemClock) Now() time.Time {
+	return time.Now().In(c.location)
+}
+
+func (c systemClock) Location() *time.Location {
+	return c.location
+}
+
+type fixedClock struct {
+	now time.Time
+}
+
+// Fixed returns a clock that is always at now, in now's location.
+func Fixed(now time.Time) Clock {
+	return fixedClock{now: now}
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func (c fixedClock) Location() *time.Location {
+	return c.now.Location()
+}
+
+// Today returns the clock's current calendar date. Like dates parsed with
+// time.Parse("2006-01-02") and scanned from DATE columns it is midnight UTC,
+// so dates compare correctly whatever the business timezone.
+func Today(c Clock) time.Time {
+	year, month, day := c.Now().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,64 @@
+package clock
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestToday(t *testing.T) {
+	lima, err := time.LoadLocation("America/Lima")
+	if err != nil {
+		t.Fatalf("loading America/Lima: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"Lima morning", time.Date(2025, 3, 10, 9, 0, 0, 0, lima), "2025-03-10"},
+		{"Lima 19:00 is already the next day in UTC", time.Date(2025, 3, 10, 19, 0, 0, 0, lima), "2025-03-10"},
+		{"Lima 21:30", time.Date(2025, 3, 10, 21, 30, 0, 0, lima), "2025-03-10"},
+		{"Lima 23:59", time.Date(2025, 3, 10, 23, 59, 59, 0, lima), "2025-03-10"},
+		{"Lima midnight", time.Date(2025, 3, 11, 0, 0, 0, 0, lima), "2025-03-11"},
+		{"UTC instant read in Lima", time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC).In(lima), "2025-03-10"},
+		{"year end in Lima", time.Date(2025, 12, 31, 20, 0, 0, 0, lima), "2025-12-31"},
+		{"UTC clock", time.Date(2025, 3, 11, 2, 0, 0, 0, time.UTC), "2025-03-11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today := Today(Fixed(tt.now))
+
+			if got := today.Format("2006-01-02"); got != tt.want {
+				t.Errorf("Today() = %s, want %s", got, tt.want)
+			}
+			if today.Location() != time.UTC || today.Hour() != 0 || today.Minute() != 0 {
+				t.Errorf("Today() = %v, want midnight UTC", today)
+			}
+
+			parsed, _ := time.Parse("2006-01-02", tt.want)
+			if !today.Equal(parsed) {
+				t.Errorf("Today() = %v, want it equal to the parsed date %v", today, parsed)
+			}
+		})
+	}
+}
+
+func TestFixedLocation(t *testing.T) {
+	lima, err := time.LoadLocation("America/Lima")
+	if err != nil {
+		t.Fatalf("loading America/Lima: %v", err)
+	}
+
+	now := time.Date(2025, 3, 10, 19, 0, 0, 0, lima)
+	c := Fixed(now)
+
+	if !c.Now().Equal(now) {
+		t.Errorf("Now() = %v, want %v", c.Now(), now)
+	}
+	if c.Location() != lima {
+		t.Errorf("Location() = %v, want %v", c.Location(), lima)
+	}
+}
@@ -7,13 +7,23 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
-	RENIEC   ReniecConfig
-	App      AppConfig
+	RENIEC      ReniecConfig
+	App         AppConfig
+	Scheduling  SchedulingConfig
+	Reliability ReliabilityConfig
+	Health      HealthConfig
+	Consent     ConsentConfig
+	Calendar    CalendarConfig
+	Events      EventsConfig
+	Reminders   RemindersConfig
+	ActionLinks ActionLinksConfig
+	Chatbot     ChatbotConfig
 }
 
 type DatabaseConfig struct {
@@ -36,6 +46,72 @@ type ReniecConfig struct {
 	BaseURL string
 }
 
+type SchedulingConfig struct {
+	AssignmentStrategy   string // least_loaded, round_robin, preferred
+	OpeningTime          string // HH:MM
+	ClosingTime          string // HH:MM
+	SlotIntervalMinutes  int
+	MaxReschedules       int    // default for services without a policy
+	WaitlistOfferMinutes int    // how long a waitlist offer holds the slot
+	HoldMinutes          int    // default length of a tentative hold
+	Timezone             string // IANA zone the spa's dates and times are in
+}
+
+// ReliabilityConfig controls no-show detection and the booking policies
+// applied to clients with a poor attendance record. A threshold of 0
+// disables the policy.
+type ReliabilityConfig struct {
+	NoShowGraceMinutes           int // after the appointment ends
+	NoShowCheckIntervalMinutes   int
+	LateCancellationHours        int // default notice for services without a policy
+	DepositAfterNoShows          int
+	BlockSelfBookingAfterNoShows int
+}
+
+// HealthConfig controls the client health questionnaire used to screen
+// bookings for contraindications.
+type HealthConfig struct {
+	ProfileValidDays int // after which the client must review their answers
+}
+
+// ConsentConfig sets where signed consent documents are kept.
+type ConsentConfig struct {
+	StorageDir string
+}
+
+// CalendarConfig controls the iCalendar files and subscribable feeds.
+type CalendarConfig struct {
+	PublicURL    string // base of the feed links handed out, e.g. https://api.example.com/api/v1
+	UIDDomain    string // right-hand side of every event UID
+	FeedPastDays int    // how far back feeds reach; upcoming bookings are always included
+}
+
+// EventsConfig controls the real-time appointment event stream.
+type EventsConfig struct {
+	LogSize          int // events kept for clients resuming with Last-Event-ID
+	HeartbeatSeconds int // interval of the keep-alive comments sent on idle streams
+}
+
+// RemindersConfig controls the appointment reminders sent to clients.
+type RemindersConfig struct {
+	Offsets              []time.Duration // how long before an appointment each reminder is sent
+	CheckIntervalSeconds int             // 0 disables the scheduler
+	MaxAttempts          int             // delivery attempts before a reminder is marked failed
+}
+
+// ActionLinksConfig controls the confirm and cancel links sent to clients.
+type ActionLinksConfig struct {
+	BaseURL    string // the token is appended, e.g. https://acme.example/appointment-action/
+	Secret     string // HMAC key; links stop working when it changes
+	ValidHours int    // links also expire when the appointment starts
+}
+
+// ChatbotConfig holds the key the chatbot integration sends with its
+// requests. Bookings that carry it are made on the chatbot channel.
+type ChatbotConfig struct {
+	APIKey string // empty disables the chatbot channel
+}
+
 type AppConfig struct {
 	Environment string // development, production, testing
 	LogLevel    string
@@ -79,6 +155,51 @@ func Load() (*Config, error) {
 			APIKey:  getEnv("RENIEC_API_KEY", ""),
 			BaseURL: getEnv("RENIEC_BASE_URL", ""),
 		},
+		Scheduling: SchedulingConfig{
+			AssignmentStrategy:   getEnv("ASSIGNMENT_STRATEGY", "least_loaded"),
+			OpeningTime:          getEnv("OPENING_TIME", "09:00"),
+			ClosingTime:          getEnv("CLOSING_TIME", "19:00"),
+			SlotIntervalMinutes:  getIntEnv("SLOT_INTERVAL_MINUTES", 30),
+			MaxReschedules:       getIntEnv("MAX_RESCHEDULES", 2),
+			WaitlistOfferMinutes: getIntEnv("WAITLIST_OFFER_MINUTES", 30),
+			HoldMinutes:          getIntEnv("HOLD_MINUTES", 10),
+			Timezone:             getEnv("BUSINESS_TIMEZONE", "America/Lima"),
+		},
+		Reliability: ReliabilityConfig{
+			NoShowGraceMinutes:           getIntEnv("NO_SHOW_GRACE_MINUTES", 30),
+			NoShowCheckIntervalMinutes:   getIntEnv("NO_SHOW_CHECK_INTERVAL_MINUTES", 15),
+			LateCancellationHours:        getIntEnv("LATE_CANCELLATION_HOURS", 24),
+			DepositAfterNoShows:          getIntEnv("DEPOSIT_AFTER_NO_SHOWS", 2),
+			BlockSelfBookingAfterNoShows: getIntEnv("BLOCK_SELF_BOOKING_AFTER_NO_SHOWS", 3),
+		},
+		Health: HealthConfig{
+			ProfileValidDays: getIntEnv("HEALTH_PROFILE_VALID_DAYS", 180),
+		},
+		Consent: ConsentConfig{
+			StorageDir: getEnv("CONSENT_STORAGE_DIR", "data/consents"),
+		},
+		Calendar: CalendarConfig{
+			PublicURL:    getEnv("CALENDAR_PUBLIC_URL", "http://localhost:8080/api/v1"),
+			UIDDomain:    getEnv("CALENDAR_UID_DOMAIN", "acme.local"),
+			FeedPastDays: getIntEnv("CALENDAR_FEED_PAST_DAYS", 90),
+		},
+		Events: EventsConfig{
+			LogSize:          getIntEnv("EVENTS_LOG_SIZE", 500),
+			HeartbeatSeconds: getIntEnv("EVENTS_HEARTBEAT_SECONDS", 15),
+		},
+		Reminders: RemindersConfig{
+			Offsets:              getDurationsEnv("REMINDER_OFFSETS", []time.Duration{24 * time.Hour, 2 * time.Hour}),
+			CheckIntervalSeconds: getIntEnv("REMINDER_CHECK_INTERVAL_SECONDS", 60),
+			MaxAttempts:          getIntEnv("REMINDER_MAX_ATTEMPTS", 3),
+		},
+		ActionLinks: ActionLinksConfig{
+			BaseURL:    getEnv("ACTION_LINK_BASE_URL", "http://localhost:8080/api/v1/appointment-actions/"),
+			Secret:     getEnv("ACTION_LINK_SECRET", ""),
+			ValidHours: getIntEnv("ACTION_LINK_VALID_HOURS", 72),
+		},
+		Chatbot: ChatbotConfig{
+			APIKey: getEnv("CHATBOT_API_KEY", ""),
+		},
 	}
 
 	// Try multiple paths for app.properties
@@ -189,6 +310,112 @@ func Load() (*Config, error) {
 			config.RENIEC.APIKey = value
 		case "reniec.ruc.api.base.url":
 			config.RENIEC.BaseURL = value
+		case "scheduling.assignment.strategy":
+			if value != "" {
+				config.Scheduling.AssignmentStrategy = value
+			}
+		case "scheduling.opening.time":
+			if value != "" {
+				config.Scheduling.OpeningTime = value
+			}
+		case "scheduling.closing.time":
+			if value != "" {
+				config.Scheduling.ClosingTime = value
+			}
+		case "scheduling.slot.interval.minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				config.Scheduling.SlotIntervalMinutes = minutes
+			}
+		case "scheduling.max.reschedules":
+			if count, err := strconv.Atoi(value); err == nil {
+				config.Scheduling.MaxReschedules = count
+			}
+		case "scheduling.waitlist.offer.minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				config.Scheduling.WaitlistOfferMinutes = minutes
+			}
+		case "scheduling.timezone":
+			if value != "" {
+				config.Scheduling.Timezone = value
+			}
+		case "scheduling.hold.minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				config.Scheduling.HoldMinutes = minutes
+			}
+		case "reliability.no.show.grace.minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				config.Reliability.NoShowGraceMinutes = minutes
+			}
+		case "reliability.no.show.check.interval.minutes":
+			if minutes, err := strconv.Atoi(value); err == nil {
+				config.Reliability.NoShowCheckIntervalMinutes = minutes
+			}
+		case "reliability.late.cancellation.hours":
+			if hours, err := strconv.Atoi(value); err == nil {
+				config.Reliability.LateCancellationHours = hours
+			}
+		case "reliability.deposit.after.no.shows":
+			if count, err := strconv.Atoi(value); err == nil {
+				config.Reliability.DepositAfterNoShows = count
+			}
+		case "reliability.block.self.booking.after.no.shows":
+			if count, err := strconv.Atoi(value); err == nil {
+				config.Reliability.BlockSelfBookingAfterNoShows = count
+			}
+		case "health.profile.valid.days":
+			if days, err := strconv.Atoi(value); err == nil {
+				config.Health.ProfileValidDays = days
+			}
+		case "consent.storage.dir":
+			if value != "" {
+				config.Consent.StorageDir = value
+			}
+		case "calendar.public.url":
+			if value != "" {
+				config.Calendar.PublicURL = value
+			}
+		case "calendar.uid.domain":
+			if value != "" {
+				config.Calendar.UIDDomain = value
+			}
+		case "calendar.feed.past.days":
+			if days, err := strconv.Atoi(value); err == nil {
+				config.Calendar.FeedPastDays = days
+			}
+		case "events.log.size":
+			if size, err := strconv.Atoi(value); err == nil {
+				config.Events.LogSize = size
+			}
+		case "events.heartbeat.seconds":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				config.Events.HeartbeatSeconds = seconds
+			}
+		case "reminders.offsets":
+			if offsets, ok := parseDurations(value); ok {
+				config.Reminders.Offsets = offsets
+			}
+		case "reminders.check.interval.seconds":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				config.Reminders.CheckIntervalSeconds = seconds
+			}
+		case "reminders.max.attempts":
+			if attempts, err := strconv.Atoi(value); err == nil {
+				config.Reminders.MaxAttempts = attempts
+			}
+		case "action.links.base.url":
+			if value != "" {
+				config.ActionLinks.BaseURL = value
+			}
+		case "action.links.secret":
+			if value != "" {
+				config.ActionLinks.Secret = value
+			}
+		case "action.links.valid.hours":
+			if hours, err := strconv.Atoi(value); err == nil {
+				config.ActionLinks.ValidHours = hours
+			}
+		case "chatbot.api.key":
+			config.Chatbot.APIKey = value
 		}
 	}
 
@@ -215,6 +442,41 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+	return defaultValue
+}
+
+func getDurationsEnv(key string, defaultValue []time.Duration) []time.Duration {
+	if durations, ok := parseDurations(os.Getenv(key)); ok {
+		return durations
+	}
+	return defaultValue
+}
+
+// parseDurations parses a comma-separated list of positive durations such
+// as "24h,2h". An empty or invalid list is rejected.
+func parseDurations(value string) ([]time.Duration, bool) {
+	if strings.TrimSpace(value) == "" {
+		return nil, false
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || duration <= 0 {
+			return nil, false
+		}
+		durations = append(durations, duration)
+	}
+
+	return durations, true
+}
+
 // expandEnvVars expands environment variables in the format ${VAR_NAME}
 func expandEnvVars(value string) string {
 	re := regexp.MustCompile(`\$\{([^}]+)\}`)
@@ -0,0 +1,185 @@
+package consent
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsentHandler struct {
+	service *ConsentService
+}
+
+func NewConsentHandler(service *ConsentService) *ConsentHandler {
+	return &ConsentHandler{service: service}
+}
+
+// CreateConsentTemplate godoc
+// @Summary Publish a new consent form version
+// @Description Add the next version of a service's consent form; clients must sign the latest version before treatment
+// @Tags consent
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param template body CreateTemplateRequest true "Consent form"
+// @Success 201 {object} Template
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /services/{id}/consent-templates [post]
+func (h *ConsentHandler) CreateConsentTemplate(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.service.CreateTemplate(serviceID, req)
+	if err != nil {
+		if err.Error() == "service not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetConsentTemplates godoc
+// @Summary List a service's consent form versions
+// @Description List every version of the service's consent form, newest first
+// @Tags consent
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} Template
+// @Failure 500 {object} map[string]interface{}
+// @Router /services/{id}/consent-templates [get]
+func (h *ConsentHandler) GetConsentTemplates(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	templates, err := h.service.GetTemplates(serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// SignConsent godoc
+// @Summary Sign the consent form for an appointment
+// @Description Sign the current consent form of the appointment's service with a typed name and a drawn signature; the signed form is stored as a PDF
+// @Tags consent
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param signed_name formData string true "Client's typed name"
+// @Param signature formData file true "Drawn signature (PNG)"
+// @Success 201 {object} SignedConsent
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /appointments/{id}/consents [post]
+func (h *ConsentHandler) SignConsent(c *gin.Context) {
+	appointmentID := c.Param("id")
+
+	var req SignRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	signature, err := readSignature(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	consent, err := h.service.Sign(appointmentID, req, signature)
+	if err != nil {
+		switch err.Error() {
+		case "appointment not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		case "consent can only be signed before the treatment starts":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, consent)
+}
+
+// readSignature reads the uploaded signature file, reading one byte past
+// the limit so oversized drawings are rejected by the service.
+func readSignature(c *gin.Context) ([]byte, error) {
+	header, err := c.FormFile("signature")
+	if err != nil {
+		return nil, fmt.Errorf("signature file is required")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, fmt.Errorf("error reading signature: %w", err)
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxSignatureBytes+1))
+}
+
+// GetAppointmentConsents godoc
+// @Summary List the consents signed for an appointment
+// @Description List signed consent forms, newest first, and whether each is for the service's current form version
+// @Tags consent
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {array} SignedConsent
+// @Failure 500 {object} map[string]interface{}
+// @Router /appointments/{id}/consents [get]
+func (h *ConsentHandler) GetAppointmentConsents(c *gin.Context) {
+	appointmentID := c.Param("id")
+
+	consents, err := h.service.GetAppointmentConsents(appointmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, consents)
+}
+
+// GetConsentDocument godoc
+// @Summary Download a signed consent form
+// @Description Download the PDF stored when the consent was signed
+// @Tags consent
+// @Produce application/pdf
+// @Param id path string true "Signed consent ID"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /consents/{id}/document [get]
+func (h *ConsentHandler) GetConsentDocument(c *gin.Context) {
+	id := c.Param("id")
+
+	consent, pdf, err := h.service.GetDocument(id)
+	if err != nil {
+		switch err.Error() {
+		case "signed consent not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Signed consent not found"})
+		case "consent document not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Consent document not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="consent-%s.pdf"`, consent.ID))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
@@ -0,0 +1,54 @@
+package consent
+
+import (
+	"time"
+)
+
+// Template is one version of a service's consent form. Templates are never
+// edited; a new version replaces the previous one.
+type Template struct {
+	ID        string    `json:"id" db:"id"`
+	ServiceID string    `json:"service_id" db:"service_id"`
+	Version   int       `json:"version" db:"version"`
+	Title     string    `json:"title" db:"title"`
+	Body      string    `json:"body" db:"body"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateTemplateRequest struct {
+	Title string `json:"title" binding:"required"`
+	Body  string `json:"body" binding:"required"`
+}
+
+// SignedConsent is a client's signature of a template for an appointment.
+// The signed document is stored as a PDF that is never replaced.
+type SignedConsent struct {
+	ID              string    `json:"id" db:"id"`
+	TemplateID      string    `json:"template_id" db:"template_id"`
+	TemplateVersion int       `json:"template_version"`
+	ClientID        string    `json:"client_id" db:"client_id"`
+	AppointmentID   string    `json:"appointment_id" db:"appointment_id"`
+	SignedName      string    `json:"signed_name" db:"signed_name"`
+	DocumentKey     string    `json:"-" db:"document_key"`
+	DocumentSHA256  string    `json:"document_sha256" db:"document_sha256"`
+	SignedAt        time.Time `json:"signed_at" db:"signed_at"`
+	// Current is false once the service has a newer template version.
+	Current bool `json:"current"`
+}
+
+// SignRequest is the form posted with the drawn signature as a PNG file
+// in the "signature" field.
+type SignRequest struct {
+	SignedName string `form:"signed_name" binding:"required"`
+}
+
+type appointmentInfo struct {
+	ClientID        string
+	ClientName      string
+	ClientDNI       string
+	ServiceID       string
+	ServiceName     string
+	AppointmentDate time.Time
+	StartTime       string
+	Status          string
+}
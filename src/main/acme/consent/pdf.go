@@ -0,0 +1,234 @@
+package consent
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+)
+
+// The PDF writer below covers what a consent form needs: A4 pages of
+// Helvetica text followed by the signature image.
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 56.0
+
+	signatureWidth  = 220.0
+	signatureHeight = 90.0
+)
+
+// document is the content of a signed consent form.
+type document struct {
+	Template   *Template
+	Info       *appointmentInfo
+	SignedName string
+	SignedAt   time.Time
+	Signature  image.Image
+}
+
+type textLine struct {
+	text string
+	bold bool
+	size float64
+}
+
+func (d *document) lines() []textLine {
+	lines := []textLine{
+		{text: d.Template.Title, bold: true, size: 16},
+		{text: fmt.Sprintf("Version %d", d.Template.Version), size: 9},
+		{size: 10},
+	}
+
+	for _, paragraph := range strings.Split(d.Template.Body, "\n") {
+		for _, text := range wrap(paragraph, 10) {
+			lines = append(lines, textLine{text: text, size: 10})
+		}
+	}
+
+	return append(lines,
+		textLine{size: 10},
+		textLine{text: fmt.Sprintf("Client: %s (DNI %s)", d.Info.ClientName, d.Info.ClientDNI), size: 10},
+		textLine{text: fmt.Sprintf("Treatment: %s on %s at %s", d.Info.ServiceName,
+			d.Info.AppointmentDate.Format("2006-01-02"), d.Info.StartTime[:5]), size: 10},
+		textLine{text: "Signed by: " + d.SignedName, size: 10},
+		textLine{text: "Signed at: " + d.SignedAt.Format("2006-01-02 15:04 -07:00"), size: 10},
+		textLine{size: 10},
+		textLine{text: "Signature:", bold: true, size: 10},
+	)
+}
+
+// wrap splits a paragraph into lines that fit the page at the given font
+// size, estimating Helvetica's average character width.
+func wrap(paragraph string, size float64) []string {
+	maxChars := int((pageWidth - 2*margin) / (size * 0.52))
+
+	words := strings.Fields(paragraph)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if len([]rune(line))+1+len([]rune(word)) > maxChars {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+
+	return append(lines, line)
+}
+
+// render lays the document out over as many pages as it needs and returns
+// the PDF file.
+func (d *document) render() ([]byte, error) {
+	var pages []string
+	var page strings.Builder
+	y := pageHeight - margin
+
+	newPage := func() {
+		pages = append(pages, page.String())
+		page.Reset()
+		y = pageHeight - margin
+	}
+
+	for _, line := range d.lines() {
+		leading := line.size * 1.45
+		if y-leading < margin {
+			newPage()
+		}
+		y -= leading
+
+		if line.text == "" {
+			continue
+		}
+
+		font := "F1"
+		if line.bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&page, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", font, line.size, margin, y, pdfText(line.text))
+	}
+
+	bounds := d.Signature.Bounds()
+	scale := signatureWidth / float64(bounds.Dx())
+	if h := signatureHeight / float64(bounds.Dy()); h < scale {
+		scale = h
+	}
+	width, height := float64(bounds.Dx())*scale, float64(bounds.Dy())*scale
+
+	if y-height-8 < margin {
+		newPage()
+	}
+	y -= height + 4
+	fmt.Fprintf(&page, "q %.2f 0 0 %.2f %.1f %.1f cm /Im1 Do Q\n", width, height, margin, y)
+	fmt.Fprintf(&page, "%.1f %.1f m %.1f %.1f l S\n", margin, y-4, margin+signatureWidth, y-4)
+	pages = append(pages, page.String())
+
+	pixels, err := rgbPixels(d.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return writePDF(pages, bounds.Dx(), bounds.Dy(), pixels), nil
+}
+
+// writePDF assembles the objects: catalog, page tree, the two fonts, the
+// signature image, then a page and its content stream for every page.
+func writePDF(pages []string, imageWidth, imageHeight int, pixels []byte) []byte {
+	var buf bytes.Buffer
+	offsets := []int{0}
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, body)
+	}
+	stream := func(dict string, data []byte) {
+		object(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data))
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const firstPage = 6
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	stream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode",
+		imageWidth, imageHeight), pixels)
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> /XObject << /Im1 5 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		stream("", []byte(content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+
+	return buf.Bytes()
+}
+
+// rgbPixels flattens the image onto white and compresses the RGB samples.
+func rgbPixels(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	raw := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			white := 0xffff - a
+			raw = append(raw, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(raw); err != nil {
+		return nil, fmt.Errorf("error compressing signature: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("error compressing signature: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// pdfText escapes a string for a PDF literal in WinAnsi encoding. Latin-1
+// characters such as á or ñ are kept; others are approximated or dropped.
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r == '‘' || r == '’':
+			b.WriteByte('\'')
+		case r == '“' || r == '”':
+			b.WriteByte('"')
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		case r < 0x100:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
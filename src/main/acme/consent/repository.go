@@ -0,0 +1,247 @@
+package consent
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetAppointmentInfo(appointmentID string) (*appointmentInfo, error) {
+	info := &appointmentInfo{}
+	query := `
+		SELECT a.client_id, c.first_name || ' ' || c.last_name, c.dni, a.service_id, s.name,
+		       a.appointment_date, a.start_time, a.status
+		FROM appointments a
+		JOIN clients c ON c.id = a.client_id
+		JOIN services s ON s.id = a.service_id
+		WHERE a.id = $1`
+
+	err := r.db.QueryRow(query, appointmentID).Scan(
+		&info.ClientID,
+		&info.ClientName,
+		&info.ClientDNI,
+		&info.ServiceID,
+		&info.ServiceName,
+		&info.AppointmentDate,
+		&info.StartTime,
+		&info.Status,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("appointment not found")
+		}
+		return nil, fmt.Errorf("error getting appointment: %w", err)
+	}
+
+	return info, nil
+}
+
+// CreateTemplate stores the template as the service's next version.
+func (r *Repository) CreateTemplate(template *Template) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM services WHERE id = $1)`, template.ServiceID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking service: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("service not found")
+	}
+
+	query := `
+		INSERT INTO consent_templates (service_id, version, title, body)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3
+		FROM consent_templates WHERE service_id = $1
+		RETURNING id, version, created_at`
+
+	err := r.db.QueryRow(query, template.ServiceID, template.Title, template.Body).Scan(
+		&template.ID,
+		&template.Version,
+		&template.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating consent template: %w", err)
+	}
+
+	return nil
+}
+
+// GetTemplates lists the service's template versions, newest first.
+func (r *Repository) GetTemplates(serviceID string) ([]Template, error) {
+	query := `
+		SELECT id, service_id, version, title, body, created_at
+		FROM consent_templates WHERE service_id = $1
+		ORDER BY version DESC`
+
+	rows, err := r.db.Query(query, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting consent templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []Template{}
+	for rows.Next() {
+		var template Template
+		err := rows.Scan(
+			&template.ID,
+			&template.ServiceID,
+			&template.Version,
+			&template.Title,
+			&template.Body,
+			&template.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning consent template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+// GetCurrentTemplate returns the service's latest template, or nil when the
+// service does not require consent.
+func (r *Repository) GetCurrentTemplate(serviceID string) (*Template, error) {
+	template := &Template{}
+	query := `
+		SELECT id, service_id, version, title, body, created_at
+		FROM consent_templates WHERE service_id = $1
+		ORDER BY version DESC
+		LIMIT 1`
+
+	err := r.db.QueryRow(query, serviceID).Scan(
+		&template.ID,
+		&template.ServiceID,
+		&template.Version,
+		&template.Title,
+		&template.Body,
+		&template.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting consent template: %w", err)
+	}
+
+	return template, nil
+}
+
+// CreateConsent saves a signed consent and then runs store, committing only
+// if it succeeds, so no consent is left pointing at a missing document.
+func (r *Repository) CreateConsent(consent *SignedConsent, store func() error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO signed_consents (template_id, client_id, appointment_id, signed_name,
+		                             document_key, document_sha256, signed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err = tx.QueryRow(
+		query,
+		consent.TemplateID,
+		consent.ClientID,
+		consent.AppointmentID,
+		consent.SignedName,
+		consent.DocumentKey,
+		consent.DocumentSHA256,
+		consent.SignedAt,
+	).Scan(&consent.ID)
+	if err != nil {
+		return fmt.Errorf("error saving signed consent: %w", err)
+	}
+
+	if err := store(); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing signed consent: %w", err)
+	}
+
+	return nil
+}
+
+// consentColumns selects a signed consent with its template version and
+// whether that version is still the service's latest.
+const consentColumns = `
+		SELECT sc.id, sc.template_id, t.version, sc.client_id, sc.appointment_id, sc.signed_name,
+		       sc.document_key, sc.document_sha256, sc.signed_at,
+		       t.version = (SELECT MAX(version) FROM consent_templates WHERE service_id = t.service_id)
+		FROM signed_consents sc
+		JOIN consent_templates t ON t.id = sc.template_id`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConsent(row rowScanner, consent *SignedConsent) error {
+	return row.Scan(
+		&consent.ID,
+		&consent.TemplateID,
+		&consent.TemplateVersion,
+		&consent.ClientID,
+		&consent.AppointmentID,
+		&consent.SignedName,
+		&consent.DocumentKey,
+		&consent.DocumentSHA256,
+		&consent.SignedAt,
+		&consent.Current,
+	)
+}
+
+func (r *Repository) GetConsentByID(id string) (*SignedConsent, error) {
+	consent := &SignedConsent{}
+	if err := scanConsent(r.db.QueryRow(consentColumns+` WHERE sc.id = $1`, id), consent); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("signed consent not found")
+		}
+		return nil, fmt.Errorf("error getting signed consent: %w", err)
+	}
+
+	return consent, nil
+}
+
+// GetConsentsByAppointment lists the consents signed for an appointment,
+// newest first.
+func (r *Repository) GetConsentsByAppointment(appointmentID string) ([]SignedConsent, error) {
+	rows, err := r.db.Query(consentColumns+` WHERE sc.appointment_id = $1 ORDER BY sc.signed_at DESC`, appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting signed consents: %w", err)
+	}
+	defer rows.Close()
+
+	consents := []SignedConsent{}
+	for rows.Next() {
+		var consent SignedConsent
+		if err := scanConsent(rows, &consent); err != nil {
+			return nil, fmt.Errorf("error scanning signed consent: %w", err)
+		}
+		consents = append(consents, consent)
+	}
+
+	return consents, rows.Err()
+}
+
+// HasSigned reports whether the template was signed for the appointment.
+func (r *Repository) HasSigned(appointmentID, templateID string) (bool, error) {
+	var signed bool
+	query := `SELECT EXISTS(SELECT 1 FROM signed_consents WHERE appointment_id = $1 AND template_id = $2)`
+
+	if err := r.db.QueryRow(query, appointmentID, templateID).Scan(&signed); err != nil {
+		return false, fmt.Errorf("error checking signed consent: %w", err)
+	}
+
+	return signed, nil
+}
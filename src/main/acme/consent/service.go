@@ -0,0 +1,177 @@
+package consent
+
+import (
+	"acme/clock"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"strings"
+)
+
+const (
+	// maxSignatureBytes and maxSignaturePixels bound the uploaded drawing.
+	maxSignatureBytes  = 1 << 20
+	maxSignaturePixels = 2000 * 2000
+)
+
+type ConsentService struct {
+	repo  *Repository
+	store Store
+	clock clock.Clock
+}
+
+func NewService(repo *Repository, store Store, clk clock.Clock) *ConsentService {
+	return &ConsentService{repo: repo, store: store, clock: clk}
+}
+
+// CreateTemplate adds a new version of the service's consent form. Clients
+// must sign the new version before their next treatment starts.
+func (s *ConsentService) CreateTemplate(serviceID string, req CreateTemplateRequest) (*Template, error) {
+	template := &Template{
+		ServiceID: serviceID,
+		Title:     req.Title,
+		Body:      req.Body,
+	}
+
+	if err := s.repo.CreateTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *ConsentService) GetTemplates(serviceID string) ([]Template, error) {
+	return s.repo.GetTemplates(serviceID)
+}
+
+// Sign records the client's consent to the current template of the
+// appointment's service. The signed form is rendered to a PDF, and the
+// consent is saved with the PDF's checksum; the PDF is written last, so a
+// failed save leaves no document behind.
+func (s *ConsentService) Sign(appointmentID string, req SignRequest, signature []byte) (*SignedConsent, error) {
+	signedName := strings.TrimSpace(req.SignedName)
+	if signedName == "" {
+		return nil, fmt.Errorf("signed name is required")
+	}
+
+	info, err := s.repo.GetAppointmentInfo(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch info.Status {
+	case "pending", "confirmed", "checked_in":
+	default:
+		return nil, fmt.Errorf("consent can only be signed before the treatment starts")
+	}
+
+	template, err := s.repo.GetCurrentTemplate(info.ServiceID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, fmt.Errorf("service does not require a consent form")
+	}
+
+	if len(signature) > maxSignatureBytes {
+		return nil, fmt.Errorf("signature image is too large")
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(signature))
+	if err != nil {
+		return nil, fmt.Errorf("signature must be a PNG image")
+	}
+	if config.Width*config.Height > maxSignaturePixels {
+		return nil, fmt.Errorf("signature image is too large")
+	}
+	drawing, err := png.Decode(bytes.NewReader(signature))
+	if err != nil {
+		return nil, fmt.Errorf("signature must be a PNG image")
+	}
+
+	signedAt := s.clock.Now()
+	doc := &document{
+		Template:   template,
+		Info:       info,
+		SignedName: signedName,
+		SignedAt:   signedAt.In(s.clock.Location()),
+		Signature:  drawing,
+	}
+
+	pdf, err := doc.render()
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := sha256.Sum256(pdf)
+	consent := &SignedConsent{
+		TemplateID:      template.ID,
+		TemplateVersion: template.Version,
+		ClientID:        info.ClientID,
+		AppointmentID:   appointmentID,
+		SignedName:      signedName,
+		DocumentKey: fmt.Sprintf("%s/%s/v%d-%d.pdf", info.ClientID, appointmentID,
+			template.Version, signedAt.UnixNano()),
+		DocumentSHA256: hex.EncodeToString(checksum[:]),
+		SignedAt:       signedAt,
+		Current:        true,
+	}
+
+	err = s.repo.CreateConsent(consent, func() error {
+		return s.store.Put(consent.DocumentKey, pdf)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return consent, nil
+}
+
+func (s *ConsentService) GetAppointmentConsents(appointmentID string) ([]SignedConsent, error) {
+	return s.repo.GetConsentsByAppointment(appointmentID)
+}
+
+// GetDocument returns the signed PDF, refusing one that no longer matches
+// the checksum recorded when it was signed.
+func (s *ConsentService) GetDocument(consentID string) (*SignedConsent, []byte, error) {
+	consent, err := s.repo.GetConsentByID(consentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := s.store.Get(consent.DocumentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checksum := sha256.Sum256(pdf)
+	if hex.EncodeToString(checksum[:]) != consent.DocumentSHA256 {
+		return nil, nil, fmt.Errorf("consent document does not match its checksum")
+	}
+
+	return consent, pdf, nil
+}
+
+// RequireConsent reports an error unless the client has signed the current
+// consent form of the appointment's service. Services without a form need
+// no consent.
+func (s *ConsentService) RequireConsent(appointmentID, serviceID string) error {
+	template, err := s.repo.GetCurrentTemplate(serviceID)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return nil
+	}
+
+	signed, err := s.repo.HasSigned(appointmentID, template.ID)
+	if err != nil {
+		return err
+	}
+	if !signed {
+		return fmt.Errorf("the client must sign the current consent form before the treatment starts")
+	}
+
+	return nil
+}
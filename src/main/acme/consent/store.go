@@ -0,0 +1,66 @@
+package consent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Store keeps signed consent documents. A document is written once and can
+// never be replaced, so an object store with write-once keys fits as well as
+// a local directory.
+type Store interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+}
+
+// DiskStore keeps documents as read-only files under a directory.
+type DiskStore struct {
+	dir string
+}
+
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{dir: dir}
+}
+
+func (s *DiskStore) Put(key string, data []byte) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("error creating consent directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("consent document %s already exists", key)
+		}
+		return fmt.Errorf("error creating consent document: %w", err)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(path)
+		return fmt.Errorf("error writing consent document: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("error writing consent document: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DiskStore) Get(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("consent document not found")
+		}
+		return nil, fmt.Errorf("error reading consent document: %w", err)
+	}
+
+	return data, nil
+}
@@ -70,15 +70,399 @@ func CreateTables(db *sql.DB) error {
 			start_time TIME NOT NULL,
 			end_time TIME NOT NULL,
 			attended_by UUID REFERENCES employees(id),
-			status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'cancelled', 'completed')),
+			status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show')),
 			cancelled_by VARCHAR(100),
 			cancelled_by_type VARCHAR(20) CHECK (cancelled_by_type IN ('client', 'employee')),
 			cancellation_reason TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS employee_services (
+			employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (employee_id, service_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS seed_markers (
+			name VARCHAR(50) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS appointment_series (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE RESTRICT,
+			attended_by UUID REFERENCES employees(id),
+			start_date DATE NOT NULL,
+			start_time TIME NOT NULL,
+			rrule VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS series_id UUID REFERENCES appointment_series(id) ON DELETE SET NULL`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS series_index INTEGER`,
+
+		`ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check`,
+		`ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+			CHECK (status IN ('pending', 'confirmed', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show'))`,
+
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS deposit_required BOOLEAN NOT NULL DEFAULT FALSE`,
+
+		`CREATE TABLE IF NOT EXISTS client_booking_overrides (
+			client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+			granted_by VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS reschedule_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS original_date DATE`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS original_start_time TIME`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS late_cancellation BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS cancellation_fee DECIMAL(10,2)`,
+
+		`CREATE TABLE IF NOT EXISTS appointment_reschedules (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+			from_date DATE NOT NULL,
+			from_start_time TIME NOT NULL,
+			from_attended_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+			to_date DATE NOT NULL,
+			to_start_time TIME NOT NULL,
+			to_attended_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+			requested_by VARCHAR(255) NOT NULL,
+			requested_by_type VARCHAR(20) NOT NULL CHECK (requested_by_type IN ('client', 'employee')),
+			reason TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS service_policies (
+			service_id UUID PRIMARY KEY REFERENCES services(id) ON DELETE CASCADE,
+			min_notice_hours INTEGER NOT NULL CHECK (min_notice_hours >= 0),
+			max_reschedules INTEGER NOT NULL CHECK (max_reschedules >= 0),
+			late_cancellation_fee DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (late_cancellation_fee >= 0),
+			forfeit_package_session BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS appointment_status_history (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+			from_status VARCHAR(20),
+			to_status VARCHAR(20) NOT NULL,
+			changed_by VARCHAR(255),
+			changed_by_type VARCHAR(20) CHECK (changed_by_type IN ('client', 'employee', 'system')),
+			reason TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS resources (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) NOT NULL,
+			resource_type VARCHAR(20) NOT NULL CHECK (resource_type IN ('room', 'equipment')),
+			capacity INTEGER NOT NULL DEFAULT 1 CHECK (capacity > 0),
+			description TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS service_resources (
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			PRIMARY KEY (service_id, resource_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS appointment_resources (
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+			resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE RESTRICT,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			PRIMARY KEY (appointment_id, resource_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS packages (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			name VARCHAR(100) NOT NULL,
+			description TEXT,
+			price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+			validity_days INTEGER NOT NULL CHECK (validity_days > 0),
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS package_items (
+			package_id UUID NOT NULL REFERENCES packages(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE RESTRICT,
+			sessions INTEGER NOT NULL CHECK (sessions > 0),
+			PRIMARY KEY (package_id, service_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS client_packages (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+			package_id UUID NOT NULL REFERENCES packages(id) ON DELETE RESTRICT,
+			price_paid DECIMAL(10,2) NOT NULL,
+			purchased_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS client_package_balances (
+			client_package_id UUID NOT NULL REFERENCES client_packages(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE RESTRICT,
+			sessions_total INTEGER NOT NULL CHECK (sessions_total > 0),
+			sessions_used INTEGER NOT NULL DEFAULT 0 CHECK (sessions_used >= 0),
+			sessions_reserved INTEGER NOT NULL DEFAULT 0 CHECK (sessions_reserved >= 0),
+			PRIMARY KEY (client_package_id, service_id),
+			CHECK (sessions_used + sessions_reserved <= sessions_total)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS package_usages (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_package_id UUID NOT NULL REFERENCES client_packages(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE RESTRICT,
+			appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+			action VARCHAR(20) NOT NULL CHECK (action IN ('reserved', 'redeemed', 'restored', 'forfeited')),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS waitlist_entries (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			attended_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+			date_from DATE NOT NULL,
+			date_to DATE NOT NULL,
+			earliest_time TIME,
+			latest_time TIME,
+			priority INTEGER NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'offered', 'booked', 'cancelled')),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(appointment_date, start_time, attended_by)
+			CHECK (date_from <= date_to)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS appointment_holds (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			client_id UUID REFERENCES clients(id) ON DELETE CASCADE,
+			attended_by UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+			appointment_date DATE NOT NULL,
+			start_time TIME NOT NULL,
+			end_time TIME NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'converted', 'released', 'expired')),
+			waitlist_entry_id UUID REFERENCES waitlist_entries(id) ON DELETE CASCADE,
+			appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS class_sessions (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			attended_by UUID NOT NULL REFERENCES employees(id) ON DELETE RESTRICT,
+			session_date DATE NOT NULL,
+			start_time TIME NOT NULL,
+			end_time TIME NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			capacity INTEGER NOT NULL CHECK (capacity > 0),
+			status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'cancelled')),
+			cancelled_by VARCHAR(255),
+			cancellation_reason TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS visits (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+			appointment_date DATE NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS session_resources (
+			session_id UUID NOT NULL REFERENCES class_sessions(id) ON DELETE CASCADE,
+			resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE RESTRICT,
+			quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+			PRIMARY KEY (session_id, resource_id)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS notifications (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+			appointment_id UUID REFERENCES appointments(id) ON DELETE SET NULL,
+			kind VARCHAR(50) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			message TEXT NOT NULL,
+			channel VARCHAR(50) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+			error TEXT,
+			sent_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS treatment_records (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			appointment_id UUID NOT NULL UNIQUE REFERENCES appointments(id) ON DELETE CASCADE,
+			specialist_id UUID NOT NULL REFERENCES employees(id) ON DELETE RESTRICT,
+			products_used TEXT[] NOT NULL DEFAULT '{}',
+			skin_observations TEXT,
+			device_settings JSONB NOT NULL DEFAULT '{}',
+			recommendations TEXT,
+			notes TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS service_contraindications (
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			condition VARCHAR(50) NOT NULL,
+			severity VARCHAR(10) NOT NULL CHECK (severity IN ('block', 'warn')),
+			PRIMARY KEY (service_id, condition)
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS client_health_profiles (
+			client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+			conditions TEXT[] NOT NULL DEFAULT '{}',
+			allergies TEXT[] NOT NULL DEFAULT '{}',
+			notes TEXT,
+			declared_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS consent_templates (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			title VARCHAR(200) NOT NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (service_id, version)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS signed_consents (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			template_id UUID NOT NULL REFERENCES consent_templates(id) ON DELETE RESTRICT,
+			client_id UUID NOT NULL REFERENCES clients(id) ON DELETE RESTRICT,
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE RESTRICT,
+			signed_name VARCHAR(200) NOT NULL,
+			document_key VARCHAR(500) NOT NULL UNIQUE,
+			document_sha256 CHAR(64) NOT NULL,
+			signed_at TIMESTAMPTZ NOT NULL
+		)`,
+
+		// A specialist's weekly shifts; weekday 0 is Sunday
+		`CREATE TABLE IF NOT EXISTS employee_shifts (
+			employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+			weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+			start_time TIME NOT NULL,
+			end_time TIME NOT NULL,
+			PRIMARY KEY (employee_id, weekday),
+			CHECK (end_time > start_time)
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS employee_breaks (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			employee_id UUID NOT NULL,
+			weekday SMALLINT NOT NULL,
+			start_time TIME NOT NULL,
+			end_time TIME NOT NULL,
+			label VARCHAR(100),
+			FOREIGN KEY (employee_id, weekday) REFERENCES employee_shifts(employee_id, weekday) ON DELETE CASCADE,
+			CHECK (end_time > start_time)
+		)`,
+
+		// Subscribable calendar feeds; only a hash of each token is kept
+		`CREATE TABLE IF NOT EXISTS calendar_feeds (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			owner_type VARCHAR(20) NOT NULL CHECK (owner_type IN ('employee', 'client')),
+			owner_id UUID NOT NULL,
+			token_hash CHAR(64) NOT NULL UNIQUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (owner_type, owner_id)
+		)`,
+
+		// One reminder per confirmed appointment and offset; the scheduler
+		// keeps the rows in step with the appointments
+		`CREATE TABLE IF NOT EXISTS reminder_jobs (
+			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+			kind VARCHAR(20) NOT NULL DEFAULT 'reminder' CHECK (kind IN ('reminder', 'confirmation')),
+			offset_minutes INTEGER NOT NULL CHECK (offset_minutes > 0),
+			due_at TIMESTAMPTZ NOT NULL,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'sending', 'sent', 'failed', 'cancelled', 'skipped')),
+			attempts INTEGER NOT NULL DEFAULT 0,
+			locked_until TIMESTAMPTZ,
+			notification_id UUID REFERENCES notifications(id) ON DELETE SET NULL,
+			last_error TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (appointment_id, kind, offset_minutes)
+		)`,
+
+		// Single-use confirm and cancel links; the token itself is signed
+		// and only its ID is kept
+		`CREATE TABLE IF NOT EXISTS appointment_action_tokens (
+			id VARCHAR(32) PRIMARY KEY,
+			appointment_id UUID NOT NULL REFERENCES appointments(id) ON DELETE CASCADE,
+			action VARCHAR(20) NOT NULL CHECK (action IN ('confirm', 'cancel')),
+			expires_at TIMESTAMPTZ NOT NULL,
+			used_at TIMESTAMPTZ,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key VARCHAR(255) PRIMARY KEY,
+			fingerprint CHAR(64) NOT NULL,
+			status_code INTEGER,
+			content_type VARCHAR(100),
+			response_body BYTEA,
+			etag VARCHAR(100),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE clients ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+
+		// The instants an appointment starts and ends, in the business timezone
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ`,
+
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES class_sessions(id) ON DELETE RESTRICT`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS visit_id UUID REFERENCES visits(id) ON DELETE RESTRICT`,
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS visit_position INTEGER`,
+
+		// How the appointment was booked: staff or chatbot
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS channel VARCHAR(20) NOT NULL DEFAULT 'staff'`,
+
+		// The iCalendar SEQUENCE, raised when the appointment moves or is cancelled
+		`ALTER TABLE appointments ADD COLUMN IF NOT EXISTS calendar_sequence INTEGER NOT NULL DEFAULT 0`,
+
+		// How a service occupies its specialist: exclusively, alongside up to
+		// max_concurrent other parallel bookings, or with a processing gap
+		// during which the specialist is free
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS concurrency_mode VARCHAR(20) NOT NULL DEFAULT 'exclusive' CHECK (concurrency_mode IN ('exclusive', 'parallel', 'processing'))`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS max_concurrent INTEGER NOT NULL DEFAULT 1 CHECK (max_concurrent >= 1)`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS processing_start_minutes INTEGER NOT NULL DEFAULT 0 CHECK (processing_start_minutes >= 0)`,
+		`ALTER TABLE services ADD COLUMN IF NOT EXISTS processing_minutes INTEGER NOT NULL DEFAULT 0 CHECK (processing_minutes >= 0)`,
+
+		// A cancelled appointment must not keep its slot from being booked again,
+		// the enrollments of a group session share the session's slot, and
+		// parallel services may start together. Every booking and move locks the
+		// specialist row and re-checks availability instead
+		`ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_appointment_date_start_time_attended_by_key`,
+		`DROP INDEX IF EXISTS idx_appointments_active_slot`,
+		`DROP INDEX IF EXISTS idx_appointments_private_slot`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_appointments_session_client ON appointments(session_id, client_id) WHERE status != 'cancelled' AND session_id IS NOT NULL`,
+
 		`CREATE INDEX IF NOT EXISTS idx_clients_dni ON clients(dni)`,
 		`CREATE INDEX IF NOT EXISTS idx_clients_email ON clients(email)`,
 		`CREATE INDEX IF NOT EXISTS idx_employees_email ON employees(email)`,
@@ -88,6 +472,28 @@ func CreateTables(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_appointments_client ON appointments(client_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_appointments_status ON appointments(status)`,
 		`CREATE INDEX IF NOT EXISTS idx_appointments_attended_by ON appointments(attended_by)`,
+		`CREATE INDEX IF NOT EXISTS idx_employee_services_service ON employee_services(service_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_employee_breaks_shift ON employee_breaks(employee_id, weekday)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_resources_resource ON appointment_resources(resource_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointments_series ON appointments(series_id, series_index)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointments_starts_at ON appointments(starts_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointments_visit ON appointments(visit_id, visit_position)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointments_created_at ON appointments(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_class_sessions_date ON class_sessions(session_date, service_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_class_sessions_attended_by ON class_sessions(attended_by, session_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_notifications_client ON notifications(client_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_status_history_appointment ON appointment_status_history(appointment_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_reschedules_appointment ON appointment_reschedules(appointment_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_client_packages_client ON client_packages(client_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_package_usages_client_package ON package_usages(client_package_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_package_usages_appointment ON package_usages(appointment_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_waitlist_entries_service ON waitlist_entries(service_id, status)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_holds_slot ON appointment_holds(appointment_date, attended_by) WHERE status = 'active'`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_holds_waitlist_entry ON appointment_holds(waitlist_entry_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_signed_consents_appointment ON signed_consents(appointment_id, template_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_appointment_action_tokens_appointment ON appointment_action_tokens(appointment_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_reminder_jobs_due ON reminder_jobs(next_attempt_at) WHERE status IN ('scheduled', 'sending')`,
 
 		`CREATE OR REPLACE FUNCTION update_updated_at_column()
 		RETURNS TRIGGER AS $$
@@ -97,6 +503,27 @@ func CreateTables(db *sql.DB) error {
 		END;
 		$$ language 'plpgsql'`,
 
+		`CREATE OR REPLACE FUNCTION increment_version_column()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			NEW.version = OLD.version + 1;
+			RETURN NEW;
+		END;
+		$$ language 'plpgsql'`,
+
+		`CREATE OR REPLACE FUNCTION increment_calendar_sequence()
+		RETURNS TRIGGER AS $$
+		BEGIN
+			IF NEW.appointment_date IS DISTINCT FROM OLD.appointment_date
+				OR NEW.start_time IS DISTINCT FROM OLD.start_time
+				OR NEW.end_time IS DISTINCT FROM OLD.end_time
+				OR (NEW.status = 'cancelled' AND OLD.status != 'cancelled') THEN
+				NEW.calendar_sequence = OLD.calendar_sequence + 1;
+			END IF;
+			RETURN NEW;
+		END;
+		$$ language 'plpgsql'`,
+
 		`DROP TRIGGER IF EXISTS update_clients_updated_at ON clients`,
 		`CREATE TRIGGER update_clients_updated_at BEFORE UPDATE ON clients FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
 
@@ -108,6 +535,42 @@ func CreateTables(db *sql.DB) error {
 
 		`DROP TRIGGER IF EXISTS update_appointments_updated_at ON appointments`,
 		`CREATE TRIGGER update_appointments_updated_at BEFORE UPDATE ON appointments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_appointment_series_updated_at ON appointment_series`,
+		`CREATE TRIGGER update_appointment_series_updated_at BEFORE UPDATE ON appointment_series FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_resources_updated_at ON resources`,
+		`CREATE TRIGGER update_resources_updated_at BEFORE UPDATE ON resources FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_packages_updated_at ON packages`,
+		`CREATE TRIGGER update_packages_updated_at BEFORE UPDATE ON packages FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_service_policies_updated_at ON service_policies`,
+		`CREATE TRIGGER update_service_policies_updated_at BEFORE UPDATE ON service_policies FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS increment_appointments_version ON appointments`,
+		`CREATE TRIGGER increment_appointments_version BEFORE UPDATE ON appointments FOR EACH ROW EXECUTE FUNCTION increment_version_column()`,
+
+		`DROP TRIGGER IF EXISTS increment_appointments_calendar_sequence ON appointments`,
+		`CREATE TRIGGER increment_appointments_calendar_sequence BEFORE UPDATE ON appointments FOR EACH ROW EXECUTE FUNCTION increment_calendar_sequence()`,
+
+		`DROP TRIGGER IF EXISTS increment_clients_version ON clients`,
+		`CREATE TRIGGER increment_clients_version BEFORE UPDATE ON clients FOR EACH ROW EXECUTE FUNCTION increment_version_column()`,
+
+		`DROP TRIGGER IF EXISTS increment_services_version ON services`,
+		`CREATE TRIGGER increment_services_version BEFORE UPDATE ON services FOR EACH ROW EXECUTE FUNCTION increment_version_column()`,
+
+		`DROP TRIGGER IF EXISTS update_waitlist_entries_updated_at ON waitlist_entries`,
+		`CREATE TRIGGER update_waitlist_entries_updated_at BEFORE UPDATE ON waitlist_entries FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_treatment_records_updated_at ON treatment_records`,
+		`CREATE TRIGGER update_treatment_records_updated_at BEFORE UPDATE ON treatment_records FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_class_sessions_updated_at ON class_sessions`,
+		`CREATE TRIGGER update_class_sessions_updated_at BEFORE UPDATE ON class_sessions FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
+
+		`DROP TRIGGER IF EXISTS update_reminder_jobs_updated_at ON reminder_jobs`,
+		`CREATE TRIGGER update_reminder_jobs_updated_at BEFORE UPDATE ON reminder_jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()`,
 	}
 
 	for _, query := range queries {
@@ -119,6 +582,23 @@ func CreateTables(db *sql.DB) error {
 	return nil
 }
 
+// BackfillAppointmentInstants sets starts_at and ends_at on appointments
+// booked before they were stored, reading their dates and times in the given
+// timezone.
+func BackfillAppointmentInstants(db *sql.DB, timezone string) error {
+	query := `
+		UPDATE appointments
+		SET starts_at = (appointment_date + start_time) AT TIME ZONE $1,
+		    ends_at = (appointment_date + end_time) AT TIME ZONE $1
+		WHERE starts_at IS NULL OR ends_at IS NULL`
+
+	if _, err := db.Exec(query, timezone); err != nil {
+		return fmt.Errorf("error backfilling appointment instants: %w", err)
+	}
+
+	return nil
+}
+
 func SeedData(db *sql.DB) error {
 	checkQuery := `SELECT COUNT(*) FROM services`
 	var count int
@@ -128,7 +608,7 @@ func SeedData(db *sql.DB) error {
 	}
 
 	if count > 0 {
-		return nil
+		return seedScheduling(db)
 	}
 
 	// Seed employees first
@@ -185,5 +665,156 @@ func SeedData(db *sql.DB) error {
 		}
 	}
 
+	return seedScheduling(db)
+}
+
+// seedScheduling seeds the scheduling data that was introduced after the
+// original catalog, so existing databases pick it up on their next start.
+func seedScheduling(db *sql.DB) error {
+	if err := seedEmployeeServices(db); err != nil {
+		return err
+	}
+	if err := seedResources(db); err != nil {
+		return err
+	}
+	if err := seedPackages(db); err != nil {
+		return err
+	}
+	return seedContraindications(db)
+}
+
+// seedEmployeeServices qualifies every employee for every service, which
+// matches how bookings worked before the skill matrix existed. It runs once,
+// recorded in seed_markers, so skills removed later are not added back. A
+// database that already has skills was seeded before the marker existed.
+func seedEmployeeServices(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error seeding employee services: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO seed_markers (name) VALUES ('employee_services') ON CONFLICT (name) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("error recording employee services seed: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error recording employee services seed: %w", err)
+	}
+	if claimed == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO employee_services (employee_id, service_id)
+		SELECT e.id, s.id FROM employees e CROSS JOIN services s
+		WHERE NOT EXISTS (SELECT 1 FROM employee_services)`
+
+	if _, err := tx.Exec(query); err != nil {
+		return fmt.Errorf("error seeding employee services: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func seedResources(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM resources`).Scan(&count); err != nil {
+		return fmt.Errorf("error checking if resources exist: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	resourceQueries := []string{
+		`INSERT INTO resources (name, resource_type, capacity, description) VALUES 
+		('Cabina de masajes', 'room', 2, 'Cabinas para masajes y tratamientos corporales'),
+		('Equipo de radiofrecuencia', 'equipment', 1, 'Equipo de radiofrecuencia facial')`,
+
+		`INSERT INTO service_resources (service_id, resource_id, quantity)
+		SELECT s.id, r.id, 1 FROM services s, resources r
+		WHERE s.name IN ('Masaje Relajante Corporal', 'Tratamiento Corporal Reductivo') AND r.name = 'Cabina de masajes'`,
+
+		`INSERT INTO service_resources (service_id, resource_id, quantity)
+		SELECT s.id, r.id, 1 FROM services s, resources r
+		WHERE s.name = 'Radiofrecuencia Facial' AND r.name = 'Equipo de radiofrecuencia'`,
+	}
+
+	for _, query := range resourceQueries {
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("error seeding resources: %w", err)
+		}
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+func seedPackages(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM packages`).Scan(&count); err != nil {
+		return fmt.Errorf("error checking if packages exist: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	packageQueries := []string{
+		`INSERT INTO packages (name, description, price, validity_days) VALUES 
+		('Pack de 8 sesiones reductivas', '8 sesiones de Tratamiento Corporal Reductivo a precio especial', 1000.00, 120)`,
+
+		`INSERT INTO package_items (package_id, service_id, sessions)
+		SELECT p.id, s.id, 8 FROM packages p, services s
+		WHERE p.name = 'Pack de 8 sesiones reductivas' AND s.name = 'Tratamiento Corporal Reductivo'`,
+	}
+
+	for _, query := range packageQueries {
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("error seeding packages: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// seedContraindications maps the free-text contraindications of the seeded
+// services to the health questionnaire, so screening covers them. Conditions
+// outside the questionnaire, such as open wounds or fever, stay in the text.
+func seedContraindications(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM service_contraindications`).Scan(&count); err != nil {
+		return fmt.Errorf("error checking if contraindications exist: %w", err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO service_contraindications (service_id, condition, severity)
+		SELECT s.id, c.condition, c.severity FROM services s
+		JOIN (VALUES
+			('Limpieza Facial Profunda', 'rosacea', 'block'),
+			('Facial Anti-edad', 'pregnancy', 'block'),
+			('Masaje Relajante Corporal', 'pregnancy', 'warn'),
+			('Tratamiento Corporal Reductivo', 'pregnancy', 'block'),
+			('Tratamiento Corporal Reductivo', 'heart_condition', 'block'),
+			('Tratamiento Corporal Reductivo', 'varicose_veins', 'block'),
+			('Manicure Completo', 'skin_infection', 'block'),
+			('Pedicure Completo', 'skin_infection', 'block'),
+			('Depilación con Cera Piernas Completas', 'recent_sunburn', 'block'),
+			('Microdermoabrasión', 'rosacea', 'block'),
+			('Radiofrecuencia Facial', 'pregnancy', 'block'),
+			('Radiofrecuencia Facial', 'pacemaker', 'block'),
+			('Radiofrecuencia Facial', 'metal_implants', 'block')
+		) AS c (service_name, condition, severity) ON c.service_name = s.name
+		ON CONFLICT DO NOTHING`
+
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("error seeding contraindications: %w", err)
+	}
+
+	return nil
+}
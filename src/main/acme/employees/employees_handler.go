@@ -57,4 +57,174 @@ func (h *EmployeesHandler) GetEmployeeByID(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, employee)
-}
\ No newline at end of file
+}
+
+// GetEmployeeSkills godoc
+// @Summary Get employee skills
+// @Description List the services an employee is qualified to perform
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 200 {array} Skill
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/services [get]
+func (h *EmployeesHandler) GetEmployeeSkills(c *gin.Context) {
+	id := c.Param("id")
+
+	skills, err := h.service.GetSkills(id)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, skills)
+}
+
+// AddEmployeeSkill godoc
+// @Summary Add employee skill
+// @Description Qualify an employee to perform a service
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param skill body AddSkillRequest true "Skill data"
+// @Success 201 {object} Skill
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/services [post]
+func (h *EmployeesHandler) AddEmployeeSkill(c *gin.Context) {
+	id := c.Param("id")
+
+	var req AddSkillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skill, err := h.service.AddSkill(id, req)
+	if err != nil {
+		switch err.Error() {
+		case "employee not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		case "service not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, skill)
+}
+
+// RemoveEmployeeSkill godoc
+// @Summary Remove employee skill
+// @Description Stop an employee from being assigned a service
+// @Tags employees
+// @Param id path string true "Employee ID"
+// @Param service_id path string true "Service ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /employees/{id}/services/{service_id} [delete]
+func (h *EmployeesHandler) RemoveEmployeeSkill(c *gin.Context) {
+	id := c.Param("id")
+	serviceID := c.Param("service_id")
+
+	err := h.service.RemoveSkill(id, serviceID)
+	if err != nil {
+		if err.Error() == "skill not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Skill not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetEmployeesByService godoc
+// @Summary Get qualified employees for a service
+// @Description List the employees qualified to perform a service
+// @Tags employees
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} Employee
+// @Failure 500 {object} map[string]interface{}
+// @Router /services/{id}/employees [get]
+func (h *EmployeesHandler) GetEmployeesByService(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	employees, err := h.service.GetQualifiedEmployees(serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, employees)
+}
+
+// GetWorkSchedule godoc
+// @Summary Get employee work schedule
+// @Description Get the employee's weekly shifts and breaks; an empty list means the business opening hours apply
+// @Tags employees
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Success 200 {array} WorkDay
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /employees/{id}/work-schedule [get]
+func (h *EmployeesHandler) GetWorkSchedule(c *gin.Context) {
+	id := c.Param("id")
+
+	days, err := h.service.GetWorkSchedule(id)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, days)
+}
+
+// SetWorkSchedule godoc
+// @Summary Set employee work schedule
+// @Description Replace the employee's weekly shifts and breaks; weekdays left out are days off
+// @Tags employees
+// @Accept json
+// @Produce json
+// @Param id path string true "Employee ID"
+// @Param schedule body SetWorkScheduleRequest true "Weekly schedule"
+// @Success 200 {array} WorkDay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /employees/{id}/work-schedule [put]
+func (h *EmployeesHandler) SetWorkSchedule(c *gin.Context) {
+	id := c.Param("id")
+
+	var req SetWorkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	days, err := h.service.SetWorkSchedule(id, req)
+	if err != nil {
+		if err.Error() == "employee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, days)
+}
@@ -40,4 +40,43 @@ type UpdateEmployeeRequest struct {
 	Role             *string `json:"role"`
 	Phone            *string `json:"phone"`
 	Email            *string `json:"email"`
-}
\ No newline at end of file
+}
+
+type Skill struct {
+	EmployeeID  string    `json:"employee_id" db:"employee_id"`
+	ServiceID   string    `json:"service_id" db:"service_id"`
+	ServiceName string    `json:"service_name"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type AddSkillRequest struct {
+	ServiceID string `json:"service_id" binding:"required"`
+}
+
+// WorkDay is a specialist's shift on one day of the week and the breaks
+// taken during it. Weekday follows time.Weekday, so 0 is Sunday.
+type WorkDay struct {
+	Weekday   int     `json:"weekday"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Breaks    []Break `json:"breaks"`
+}
+
+type Break struct {
+	StartTime string  `json:"start_time" binding:"required"`
+	EndTime   string  `json:"end_time" binding:"required"`
+	Label     *string `json:"label"`
+}
+
+type WorkDayRequest struct {
+	Weekday   *int    `json:"weekday" binding:"required"`
+	StartTime string  `json:"start_time" binding:"required"`
+	EndTime   string  `json:"end_time" binding:"required"`
+	Breaks    []Break `json:"breaks" binding:"dive"`
+}
+
+// SetWorkScheduleRequest replaces the specialist's whole week. Days that are
+// left out are days off.
+type SetWorkScheduleRequest struct {
+	Days []WorkDayRequest `json:"days" binding:"dive"`
+}
@@ -78,3 +78,210 @@ func (r *Repository) GetEmployeeByID(id string) (*Employee, error) {
 
 	return employee, nil
 }
+
+func (r *Repository) GetSkillsByEmployee(employeeID string) ([]Skill, error) {
+	query := `
+		SELECT es.employee_id, es.service_id, s.name, es.created_at
+		FROM employee_services es
+		JOIN services s ON es.service_id = s.id
+		WHERE es.employee_id = $1
+		ORDER BY s.name ASC`
+
+	rows, err := r.db.Query(query, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying employee skills: %w", err)
+	}
+	defer rows.Close()
+
+	var skills []Skill
+	for rows.Next() {
+		var skill Skill
+		err := rows.Scan(
+			&skill.EmployeeID,
+			&skill.ServiceID,
+			&skill.ServiceName,
+			&skill.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning employee skill: %w", err)
+		}
+		skills = append(skills, skill)
+	}
+
+	return skills, nil
+}
+
+func (r *Repository) AddSkill(skill *Skill) error {
+	query := `
+		INSERT INTO employee_services (employee_id, service_id)
+		SELECT $1, id FROM services WHERE id = $2
+		ON CONFLICT (employee_id, service_id) DO UPDATE SET employee_id = EXCLUDED.employee_id
+		RETURNING created_at`
+
+	err := r.db.QueryRow(query, skill.EmployeeID, skill.ServiceID).Scan(&skill.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("service not found")
+		}
+		return fmt.Errorf("error adding employee skill: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) RemoveSkill(employeeID, serviceID string) error {
+	query := `DELETE FROM employee_services WHERE employee_id = $1 AND service_id = $2`
+	result, err := r.db.Exec(query, employeeID, serviceID)
+	if err != nil {
+		return fmt.Errorf("error removing employee skill: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("skill not found")
+	}
+
+	return nil
+}
+
+func (r *Repository) GetEmployeesByService(serviceID string) ([]Employee, error) {
+	query := `
+		SELECT e.id, e.name, e.paternal_surname, e.maternal_surname, e.role, e.phone, e.email, 
+		       e.created_at, e.updated_at
+		FROM employees e
+		JOIN employee_services es ON es.employee_id = e.id
+		WHERE es.service_id = $1
+		ORDER BY e.name ASC`
+
+	rows, err := r.db.Query(query, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying employees by service: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []Employee
+	for rows.Next() {
+		var employee Employee
+		err := rows.Scan(
+			&employee.ID,
+			&employee.Name,
+			&employee.PaternalSurname,
+			&employee.MaternalSurname,
+			&employee.Role,
+			&employee.Phone,
+			&employee.Email,
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning employee: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	return employees, nil
+}
+
+func (r *Repository) IsQualified(employeeID, serviceID string) (bool, error) {
+	query := `SELECT COUNT(*) FROM employee_services WHERE employee_id = $1 AND service_id = $2`
+
+	var count int
+	if err := r.db.QueryRow(query, employeeID, serviceID).Scan(&count); err != nil {
+		return false, fmt.Errorf("error checking employee skill: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// GetWorkSchedule returns the employee's shifts ordered by weekday, each
+// with its breaks.
+func (r *Repository) GetWorkSchedule(employeeID string) ([]WorkDay, error) {
+	query := `
+		SELECT s.weekday, s.start_time, s.end_time, b.start_time, b.end_time, b.label
+		FROM employee_shifts s
+		LEFT JOIN employee_breaks b ON b.employee_id = s.employee_id AND b.weekday = s.weekday
+		WHERE s.employee_id = $1
+		ORDER BY s.weekday ASC, b.start_time ASC`
+
+	rows, err := r.db.Query(query, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying work schedule: %w", err)
+	}
+	defer rows.Close()
+
+	days := []WorkDay{}
+	for rows.Next() {
+		var day WorkDay
+		var breakStart, breakEnd, label sql.NullString
+		if err := rows.Scan(&day.Weekday, &day.StartTime, &day.EndTime, &breakStart, &breakEnd, &label); err != nil {
+			return nil, fmt.Errorf("error scanning work schedule: %w", err)
+		}
+
+		if len(days) == 0 || days[len(days)-1].Weekday != day.Weekday {
+			day.StartTime = day.StartTime[:5]
+			day.EndTime = day.EndTime[:5]
+			day.Breaks = []Break{}
+			days = append(days, day)
+		}
+
+		if breakStart.Valid {
+			b := Break{StartTime: breakStart.String[:5], EndTime: breakEnd.String[:5]}
+			if label.Valid {
+				b.Label = &label.String
+			}
+			current := &days[len(days)-1]
+			current.Breaks = append(current.Breaks, b)
+		}
+	}
+
+	return days, rows.Err()
+}
+
+// SetWorkSchedule replaces every shift and break of the employee.
+func (r *Repository) SetWorkSchedule(employeeID string, days []WorkDay) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Bookings lock the employee too, so each is checked against either the
+	// old or the new schedule
+	if _, err := tx.Exec(`SELECT id FROM employees WHERE id = $1 FOR UPDATE`, employeeID); err != nil {
+		return fmt.Errorf("error locking employee: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM employee_shifts WHERE employee_id = $1`, employeeID); err != nil {
+		return fmt.Errorf("error clearing work schedule: %w", err)
+	}
+
+	for _, day := range days {
+		_, err := tx.Exec(
+			`INSERT INTO employee_shifts (employee_id, weekday, start_time, end_time) VALUES ($1, $2, $3, $4)`,
+			employeeID, day.Weekday, day.StartTime, day.EndTime,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving shift: %w", err)
+		}
+
+		for _, b := range day.Breaks {
+			_, err := tx.Exec(
+				`INSERT INTO employee_breaks (employee_id, weekday, start_time, end_time, label) VALUES ($1, $2, $3, $4, $5)`,
+				employeeID, day.Weekday, b.StartTime, b.EndTime, b.Label,
+			)
+			if err != nil {
+				return fmt.Errorf("error saving break: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing work schedule: %w", err)
+	}
+
+	return nil
+}
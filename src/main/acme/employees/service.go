@@ -1,5 +1,11 @@
 package employees
 
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
 type EmployeeService struct {
 	repo *Repository
 }
@@ -15,4 +21,136 @@ func (s *EmployeeService) GetAllEmployees() ([]Employee, error) {
 
 func (s *EmployeeService) GetEmployeeByID(id string) (*Employee, error) {
 	return s.repo.GetEmployeeByID(id)
-}
\ No newline at end of file
+}
+
+func (s *EmployeeService) GetSkills(employeeID string) ([]Skill, error) {
+	if _, err := s.repo.GetEmployeeByID(employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSkillsByEmployee(employeeID)
+}
+
+func (s *EmployeeService) AddSkill(employeeID string, req AddSkillRequest) (*Skill, error) {
+	if _, err := s.repo.GetEmployeeByID(employeeID); err != nil {
+		return nil, err
+	}
+
+	skill := &Skill{
+		EmployeeID: employeeID,
+		ServiceID:  req.ServiceID,
+	}
+
+	if err := s.repo.AddSkill(skill); err != nil {
+		if err.Error() == "service not found" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("error adding skill: %w", err)
+	}
+
+	return skill, nil
+}
+
+func (s *EmployeeService) RemoveSkill(employeeID, serviceID string) error {
+	return s.repo.RemoveSkill(employeeID, serviceID)
+}
+
+// GetQualifiedEmployees returns the employees that can perform the given service.
+func (s *EmployeeService) GetQualifiedEmployees(serviceID string) ([]Employee, error) {
+	return s.repo.GetEmployeesByService(serviceID)
+}
+
+func (s *EmployeeService) IsQualified(employeeID, serviceID string) (bool, error) {
+	return s.repo.IsQualified(employeeID, serviceID)
+}
+
+// GetWorkSchedule returns the employee's weekly shifts. An empty schedule
+// means none has been set and the business opening hours apply.
+func (s *EmployeeService) GetWorkSchedule(employeeID string) ([]WorkDay, error) {
+	if _, err := s.repo.GetEmployeeByID(employeeID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetWorkSchedule(employeeID)
+}
+
+// SetWorkSchedule replaces the employee's weekly shifts. Breaks must fall
+// within their shift and not overlap each other.
+func (s *EmployeeService) SetWorkSchedule(employeeID string, req SetWorkScheduleRequest) ([]WorkDay, error) {
+	if _, err := s.repo.GetEmployeeByID(employeeID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	days := make([]WorkDay, 0, len(req.Days))
+	for _, dayReq := range req.Days {
+		weekday := *dayReq.Weekday
+		if weekday < 0 || weekday > 6 {
+			return nil, fmt.Errorf("invalid weekday %d, use 0 (Sunday) to 6 (Saturday)", weekday)
+		}
+		if seen[weekday] {
+			return nil, fmt.Errorf("weekday %d is listed more than once", weekday)
+		}
+		seen[weekday] = true
+
+		start, end, err := parseShiftTimes(dayReq.StartTime, dayReq.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("weekday %d: %w", weekday, err)
+		}
+
+		breaks := make([]Break, len(dayReq.Breaks))
+		for i, b := range dayReq.Breaks {
+			breakStart, breakEnd, err := parseShiftTimes(b.StartTime, b.EndTime)
+			if err != nil {
+				return nil, fmt.Errorf("weekday %d break: %w", weekday, err)
+			}
+			breaks[i] = Break{StartTime: breakStart.Format("15:04"), EndTime: breakEnd.Format("15:04"), Label: b.Label}
+		}
+		sort.Slice(breaks, func(i, j int) bool {
+			return breaks[i].StartTime < breaks[j].StartTime
+		})
+
+		previousEnd := start.Format("15:04")
+		for _, b := range breaks {
+			if b.StartTime < previousEnd || b.EndTime > end.Format("15:04") {
+				return nil, fmt.Errorf("weekday %d: breaks must fall within the shift and not overlap", weekday)
+			}
+			previousEnd = b.EndTime
+		}
+
+		days = append(days, WorkDay{
+			Weekday:   weekday,
+			StartTime: start.Format("15:04"),
+			EndTime:   end.Format("15:04"),
+			Breaks:    breaks,
+		})
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Weekday < days[j].Weekday
+	})
+
+	if err := s.repo.SetWorkSchedule(employeeID, days); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func parseShiftTimes(startTime, endTime string) (time.Time, time.Time, error) {
+	start, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return start, start, fmt.Errorf("invalid start time format, use HH:MM")
+	}
+
+	end, err := time.Parse("15:04", endTime)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid end time format, use HH:MM")
+	}
+
+	if !end.After(start) {
+		return start, end, fmt.Errorf("end time must be after start time")
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,81 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrModified is returned by repositories when an update or delete expected
+// a version that is no longer current.
+var ErrModified = errors.New("resource has been modified, reload it and try again")
+
+// Version is the entity tag of a versioned resource.
+func Version(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// Of derives an entity tag from the JSON representation of value, for
+// responses such as lists that have no version of their own.
+func Of(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// JSON writes value with its entity tag, or 304 Not Modified when the
+// request's If-None-Match already names the tag.
+func JSON(c *gin.Context, status int, tag string, value interface{}) {
+	c.Header("ETag", tag)
+
+	if matches(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(status, value)
+}
+
+// IfMatch returns the version required by the request's If-Match header, or
+// nil when the header is absent or "*".
+func IfMatch(c *gin.Context) (*int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		return nil, fmt.Errorf("invalid If-Match header, use the ETag of the resource")
+	}
+
+	return &version, nil
+}
+
+// matches reports whether an If-None-Match header names the tag. Weak tags
+// compare equal to their strong form.
+func matches(header, tag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"acme/clock"
+	"acme/config"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// it is dropped. A dropped client reconnects and resumes from the log.
+const subscriberBuffer = 64
+
+// Bus fans appointment events out to the open streams of this process and
+// keeps the last LogSize of them so reconnecting clients can catch up.
+//
+// Event IDs are prefixed with the bus's start time, so an ID handed out
+// before a restart is recognised as unknown rather than mistaken for a
+// recent one.
+type Bus struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	log         []Event
+	subscribers map[*Subscription]struct{}
+	config      config.EventsConfig
+	clock       clock.Clock
+}
+
+func NewBus(cfg config.EventsConfig, clk clock.Clock) *Bus {
+	if cfg.LogSize < 0 {
+		cfg.LogSize = 0
+	}
+
+	return &Bus{
+		epoch:       strconv.FormatInt(clk.Now().UnixNano(), 36),
+		subscribers: make(map[*Subscription]struct{}),
+		config:      cfg,
+		clock:       clk,
+	}
+}
+
+// Subscription receives the events matching its filter until it is closed
+// or falls too far behind, in which case Events is closed.
+type Subscription struct {
+	Events <-chan Event
+
+	events chan Event
+	filter Filter
+}
+
+// Publish assigns the event its ID and delivers it. It never blocks.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	e.seq = b.seq
+	e.ID = fmt.Sprintf("%s-%d", b.epoch, b.seq)
+	e.OccurredAt = b.clock.Now()
+
+	if b.config.LogSize > 0 {
+		if len(b.log) == b.config.LogSize {
+			copy(b.log, b.log[1:])
+			b.log = b.log[:len(b.log)-1]
+		}
+		b.log = append(b.log, e)
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.matches(e) {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// Subscribe opens a subscription. When lastEventID is set, the logged
+// events after it are returned for replay; resumed is false if the ID is
+// no longer in the log, and the client should reload its data instead.
+func (b *Bus) Subscribe(filter Filter, lastEventID string) (sub *Subscription, missed []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+	sub = &Subscription{Events: events, events: events, filter: filter}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	after, ok := b.parseID(lastEventID)
+	if !ok || after > b.seq || after < b.seq-uint64(len(b.log)) {
+		return sub, nil, false
+	}
+
+	for _, e := range b.log {
+		if e.seq > after && filter.matches(e) {
+			missed = append(missed, e)
+		}
+	}
+
+	return sub, missed, true
+}
+
+// Unsubscribe closes the subscription if the bus has not already dropped it.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Bus) Heartbeat() time.Duration {
+	if b.config.HeartbeatSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(b.config.HeartbeatSeconds) * time.Second
+}
+
+func (b *Bus) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
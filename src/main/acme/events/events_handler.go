@@ -0,0 +1,94 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventsHandler struct {
+	bus *Bus
+}
+
+func NewEventsHandler(bus *Bus) *EventsHandler {
+	return &EventsHandler{bus: bus}
+}
+
+// StreamAppointments godoc
+// @Summary Stream appointment changes
+// @Description Server-Sent Events stream of created, updated, cancelled and status-changed appointments. Reconnecting clients resume with the Last-Event-ID header; a "reset" event means events were missed and the data should be reloaded
+// @Tags events
+// @Produce text/event-stream
+// @Param date query string false "Only appointments on this date (YYYY-MM-DD)"
+// @Param employee_id query string false "Only appointments of this specialist"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {object} Event
+// @Failure 400 {object} map[string]interface{}
+// @Router /events/appointments [get]
+func (h *EventsHandler) StreamAppointments(c *gin.Context) {
+	filter := Filter{Date: c.Query("date"), EmployeeID: c.Query("employee_id")}
+	if filter.Date != "" {
+		if _, err := time.Parse("2006-01-02", filter.Date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	// EventSource sends the header on reconnects; the query parameter lets
+	// a page resume after a reload.
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	sub, missed, resumed := h.bus.Subscribe(filter, lastEventID)
+	defer h.bus.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if !resumed {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, e := range missed {
+		writeEvent(w, e)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.bus.Heartbeat())
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case e, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// catches up from the log.
+				return
+			}
+			writeEvent(w, e)
+			w.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, e Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
@@ -0,0 +1,53 @@
+package events
+
+import "time"
+
+type EventType string
+
+const (
+	AppointmentCreated       EventType = "appointment.created"
+	AppointmentUpdated       EventType = "appointment.updated"
+	AppointmentCancelled     EventType = "appointment.cancelled"
+	AppointmentStatusChanged EventType = "appointment.status_changed"
+)
+
+// Event reports a change to an appointment. When an appointment is moved
+// or handed to another specialist, the previous date and specialist are set
+// too, so screens showing either side of the move are told about it.
+type Event struct {
+	ID                 string      `json:"id"`
+	Type               EventType   `json:"type"`
+	AppointmentID      string      `json:"appointment_id"`
+	Date               string      `json:"date"`
+	EmployeeID         *string     `json:"employee_id"`
+	PreviousDate       *string     `json:"previous_date,omitempty"`
+	PreviousEmployeeID *string     `json:"previous_employee_id,omitempty"`
+	Status             string      `json:"status"`
+	Appointment        interface{} `json:"appointment"`
+	OccurredAt         time.Time   `json:"occurred_at"`
+
+	seq uint64
+}
+
+// Filter selects the events of a date, a specialist or both. Empty fields
+// match everything.
+type Filter struct {
+	Date       string
+	EmployeeID string
+}
+
+func (f Filter) matches(e Event) bool {
+	if f.Date != "" && e.Date != f.Date && (e.PreviousDate == nil || *e.PreviousDate != f.Date) {
+		return false
+	}
+
+	if f.EmployeeID != "" && !sameID(e.EmployeeID, f.EmployeeID) && !sameID(e.PreviousEmployeeID, f.EmployeeID) {
+		return false
+	}
+
+	return true
+}
+
+func sameID(id *string, want string) bool {
+	return id != nil && *id == want
+}
@@ -0,0 +1,157 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	service *HealthService
+}
+
+func NewHealthHandler(service *HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// GetServiceContraindications godoc
+// @Summary Get a service's contraindications
+// @Description List the health conditions that block or warn when a client books the service
+// @Tags health
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} Contraindication
+// @Failure 500 {object} map[string]interface{}
+// @Router /services/{id}/contraindications [get]
+func (h *HealthHandler) GetServiceContraindications(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	contraindications, err := h.service.GetContraindications(serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, contraindications)
+}
+
+// SetServiceContraindications godoc
+// @Summary Set a service's contraindications
+// @Description Replace the conditions screened for when the service is booked, each with severity block or warn
+// @Tags health
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param contraindications body SetContraindicationsRequest true "Contraindications"
+// @Success 200 {array} Contraindication
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /services/{id}/contraindications [put]
+func (h *HealthHandler) SetServiceContraindications(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	var req SetContraindicationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	contraindications, err := h.service.SetContraindications(serviceID, req)
+	if err != nil {
+		if err.Error() == "service not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, contraindications)
+}
+
+// GetHealthProfile godoc
+// @Summary Get a client's health profile
+// @Description Get the client's answers to the health questionnaire and whether they have expired
+// @Tags health
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} Profile
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/health-profile [get]
+func (h *HealthHandler) GetHealthProfile(c *gin.Context) {
+	clientID := c.Param("id")
+
+	profile, err := h.service.GetProfile(clientID)
+	if err != nil {
+		if err.Error() == "health profile not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Health profile not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeclareHealthProfile godoc
+// @Summary Record a client's health profile
+// @Description Replace the client's answers to the health questionnaire; they stay valid for HEALTH_PROFILE_VALID_DAYS
+// @Tags health
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param profile body DeclareProfileRequest true "Questionnaire answers"
+// @Success 200 {object} Profile
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/health-profile [put]
+func (h *HealthHandler) DeclareHealthProfile(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req DeclareProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.DeclareProfile(clientID, req)
+	if err != nil {
+		if err.Error() == "client not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ScreenClient godoc
+// @Summary Screen a client for a service
+// @Description Check the client's declared conditions against the service's contraindications before booking
+// @Tags health
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param service_id query string true "Service ID"
+// @Success 200 {object} Screening
+// @Failure 400 {object} map[string]interface{}
+// @Router /clients/{id}/health-screening [get]
+func (h *HealthHandler) ScreenClient(c *gin.Context) {
+	clientID := c.Param("id")
+	serviceID := c.Query("service_id")
+
+	if serviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service_id is required"})
+		return
+	}
+
+	screening, err := h.service.Screen(clientID, serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, screening)
+}
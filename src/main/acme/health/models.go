@@ -0,0 +1,106 @@
+package health
+
+import (
+	"time"
+)
+
+// Condition is an entry of the questionnaire clients answer and services are
+// screened against.
+type Condition string
+
+const (
+	ConditionPregnancy      Condition = "pregnancy"
+	ConditionBreastfeeding  Condition = "breastfeeding"
+	ConditionPacemaker      Condition = "pacemaker"
+	ConditionMetalImplants  Condition = "metal_implants"
+	ConditionEpilepsy       Condition = "epilepsy"
+	ConditionDiabetes       Condition = "diabetes"
+	ConditionHeartCondition Condition = "heart_condition"
+	ConditionBloodThinners  Condition = "blood_thinners"
+	ConditionIsotretinoin   Condition = "isotretinoin"
+	ConditionRosacea        Condition = "rosacea"
+	ConditionEczema         Condition = "eczema"
+	ConditionSkinInfection  Condition = "skin_infection"
+	ConditionRecentSunburn  Condition = "recent_sunburn"
+	ConditionPhotosensitive Condition = "photosensitivity"
+	ConditionKeloidScarring Condition = "keloid_scarring"
+	ConditionVaricoseVeins  Condition = "varicose_veins"
+)
+
+func (c Condition) IsValid() bool {
+	switch c {
+	case ConditionPregnancy, ConditionBreastfeeding, ConditionPacemaker, ConditionMetalImplants,
+		ConditionEpilepsy, ConditionDiabetes, ConditionHeartCondition, ConditionBloodThinners,
+		ConditionIsotretinoin, ConditionRosacea, ConditionEczema, ConditionSkinInfection,
+		ConditionRecentSunburn, ConditionPhotosensitive, ConditionKeloidScarring, ConditionVaricoseVeins:
+		return true
+	}
+	return false
+}
+
+// Severity says what happens when a client with the condition books the
+// service: block refuses the booking, warn lets it through with a warning.
+type Severity string
+
+const (
+	SeverityBlock Severity = "block"
+	SeverityWarn  Severity = "warn"
+)
+
+func (s Severity) IsValid() bool {
+	return s == SeverityBlock || s == SeverityWarn
+}
+
+// Contraindication is a condition that conflicts with a service.
+type Contraindication struct {
+	Condition Condition `json:"condition" binding:"required"`
+	Severity  Severity  `json:"severity" binding:"required"`
+}
+
+type SetContraindicationsRequest struct {
+	Contraindications []Contraindication `json:"contraindications" binding:"dive"`
+}
+
+// Profile is a client's answers to the health questionnaire. Answers expire
+// and must be reviewed with the client again.
+type Profile struct {
+	ClientID   string      `json:"client_id" db:"client_id"`
+	Conditions []Condition `json:"conditions" db:"conditions"`
+	Allergies  []string    `json:"allergies" db:"allergies"`
+	Notes      *string     `json:"notes" db:"notes"`
+	DeclaredAt time.Time   `json:"declared_at" db:"declared_at"`
+	ExpiresAt  time.Time   `json:"expires_at" db:"expires_at"`
+	Expired    bool        `json:"expired"`
+}
+
+type DeclareProfileRequest struct {
+	Conditions []Condition `json:"conditions"`
+	// Allergies are free text, e.g. "latex" or "lidocaine".
+	Allergies []string `json:"allergies"`
+	Notes     *string  `json:"notes"`
+}
+
+// Conflict is a declared condition that contraindicates the service.
+type Conflict struct {
+	Condition Condition `json:"condition"`
+	Severity  Severity  `json:"severity"`
+}
+
+// Screening is the outcome of checking a client against a service.
+type Screening struct {
+	Conflicts []Conflict `json:"conflicts"`
+	// Warnings explain the warn conflicts and anything else staff should
+	// check, such as a missing or expired questionnaire.
+	Warnings []string `json:"warnings"`
+}
+
+// Blocked lists the conditions that refuse the booking.
+func (s *Screening) Blocked() []Condition {
+	var blocked []Condition
+	for _, conflict := range s.Conflicts {
+		if conflict.Severity == SeverityBlock {
+			blocked = append(blocked, conflict.Condition)
+		}
+	}
+	return blocked
+}
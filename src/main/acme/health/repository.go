@@ -0,0 +1,148 @@
+package health
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetContraindications(serviceID string) ([]Contraindication, error) {
+	query := `
+		SELECT condition, severity FROM service_contraindications
+		WHERE service_id = $1
+		ORDER BY condition`
+
+	rows, err := r.db.Query(query, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting contraindications: %w", err)
+	}
+	defer rows.Close()
+
+	contraindications := []Contraindication{}
+	for rows.Next() {
+		var contraindication Contraindication
+		if err := rows.Scan(&contraindication.Condition, &contraindication.Severity); err != nil {
+			return nil, fmt.Errorf("error scanning contraindication: %w", err)
+		}
+		contraindications = append(contraindications, contraindication)
+	}
+
+	return contraindications, rows.Err()
+}
+
+// SetContraindications replaces the service's contraindications.
+func (r *Repository) SetContraindications(serviceID string, contraindications []Contraindication) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM services WHERE id = $1)`, serviceID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking service: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("service not found")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM service_contraindications WHERE service_id = $1`, serviceID); err != nil {
+		return fmt.Errorf("error clearing contraindications: %w", err)
+	}
+
+	for _, contraindication := range contraindications {
+		_, err := tx.Exec(
+			`INSERT INTO service_contraindications (service_id, condition, severity) VALUES ($1, $2, $3)`,
+			serviceID, contraindication.Condition, contraindication.Severity,
+		)
+		if err != nil {
+			return fmt.Errorf("error saving contraindication: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProfile returns the client's questionnaire, or nil when they have never
+// answered it.
+func (r *Repository) GetProfile(clientID string) (*Profile, error) {
+	profile := &Profile{}
+	var conditions []string
+	query := `
+		SELECT client_id, conditions, allergies, notes, declared_at, expires_at,
+		       expires_at <= CURRENT_TIMESTAMP
+		FROM client_health_profiles WHERE client_id = $1`
+
+	err := r.db.QueryRow(query, clientID).Scan(
+		&profile.ClientID,
+		pq.Array(&conditions),
+		pq.Array(&profile.Allergies),
+		&profile.Notes,
+		&profile.DeclaredAt,
+		&profile.ExpiresAt,
+		&profile.Expired,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting health profile: %w", err)
+	}
+
+	profile.Conditions = make([]Condition, len(conditions))
+	for i, condition := range conditions {
+		profile.Conditions[i] = Condition(condition)
+	}
+
+	return profile, nil
+}
+
+// SaveProfile records the client's answers, valid for validDays from now.
+func (r *Repository) SaveProfile(profile *Profile, validDays int) error {
+	var exists bool
+	if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM clients WHERE id = $1)`, profile.ClientID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking client: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("client not found")
+	}
+
+	conditions := make([]string, len(profile.Conditions))
+	for i, condition := range profile.Conditions {
+		conditions[i] = string(condition)
+	}
+
+	query := `
+		INSERT INTO client_health_profiles (client_id, conditions, allergies, notes, declared_at, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP + make_interval(days => $5))
+		ON CONFLICT (client_id) DO UPDATE SET
+			conditions = EXCLUDED.conditions,
+			allergies = EXCLUDED.allergies,
+			notes = EXCLUDED.notes,
+			declared_at = EXCLUDED.declared_at,
+			expires_at = EXCLUDED.expires_at
+		RETURNING declared_at, expires_at`
+
+	err := r.db.QueryRow(
+		query,
+		profile.ClientID,
+		pq.Array(conditions),
+		pq.Array(profile.Allergies),
+		profile.Notes,
+		validDays,
+	).Scan(&profile.DeclaredAt, &profile.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error saving health profile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"acme/config"
+	"fmt"
+	"strings"
+)
+
+type HealthService struct {
+	repo   *Repository
+	config *config.Config
+}
+
+func NewService(repo *Repository, cfg *config.Config) *HealthService {
+	return &HealthService{repo: repo, config: cfg}
+}
+
+func (s *HealthService) GetContraindications(serviceID string) ([]Contraindication, error) {
+	return s.repo.GetContraindications(serviceID)
+}
+
+func (s *HealthService) SetContraindications(serviceID string, req SetContraindicationsRequest) ([]Contraindication, error) {
+	seen := make(map[Condition]bool)
+	for _, contraindication := range req.Contraindications {
+		if !contraindication.Condition.IsValid() {
+			return nil, fmt.Errorf("invalid condition %q", contraindication.Condition)
+		}
+		if !contraindication.Severity.IsValid() {
+			return nil, fmt.Errorf("invalid severity %q, use block or warn", contraindication.Severity)
+		}
+		if seen[contraindication.Condition] {
+			return nil, fmt.Errorf("condition %q is listed more than once", contraindication.Condition)
+		}
+		seen[contraindication.Condition] = true
+	}
+
+	if err := s.repo.SetContraindications(serviceID, req.Contraindications); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetContraindications(serviceID)
+}
+
+func (s *HealthService) GetProfile(clientID string) (*Profile, error) {
+	profile, err := s.repo.GetProfile(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile == nil {
+		return nil, fmt.Errorf("health profile not found")
+	}
+
+	return profile, nil
+}
+
+// DeclareProfile replaces the client's answers and restarts their validity.
+func (s *HealthService) DeclareProfile(clientID string, req DeclareProfileRequest) (*Profile, error) {
+	for _, condition := range req.Conditions {
+		if !condition.IsValid() {
+			return nil, fmt.Errorf("invalid condition %q", condition)
+		}
+	}
+
+	profile := &Profile{
+		ClientID:   clientID,
+		Conditions: req.Conditions,
+		Allergies:  req.Allergies,
+		Notes:      req.Notes,
+	}
+
+	if profile.Conditions == nil {
+		profile.Conditions = []Condition{}
+	}
+	if profile.Allergies == nil {
+		profile.Allergies = []string{}
+	}
+
+	if err := s.repo.SaveProfile(profile, s.config.Health.ProfileValidDays); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Screen checks the client's declared conditions against the service. An
+// expired questionnaire is still used, but staff are warned to review it.
+func (s *HealthService) Screen(clientID, serviceID string) (*Screening, error) {
+	contraindications, err := s.repo.GetContraindications(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.GetProfile(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	screening := &Screening{Conflicts: []Conflict{}, Warnings: []string{}}
+
+	if profile == nil {
+		if len(contraindications) > 0 {
+			screening.Warnings = append(screening.Warnings, "client has not completed the health questionnaire")
+		}
+		return screening, nil
+	}
+
+	if profile.Expired {
+		screening.Warnings = append(screening.Warnings,
+			fmt.Sprintf("client's health questionnaire expired on %s, review it with them", profile.ExpiresAt.Format("2006-01-02")))
+	}
+
+	declared := make(map[Condition]bool, len(profile.Conditions))
+	for _, condition := range profile.Conditions {
+		declared[condition] = true
+	}
+
+	for _, contraindication := range contraindications {
+		if !declared[contraindication.Condition] {
+			continue
+		}
+
+		screening.Conflicts = append(screening.Conflicts, Conflict(contraindication))
+		if contraindication.Severity == SeverityWarn {
+			screening.Warnings = append(screening.Warnings,
+				fmt.Sprintf("client has declared %s, proceed with caution", contraindication.Condition))
+		}
+	}
+
+	if len(profile.Allergies) > 0 {
+		screening.Warnings = append(screening.Warnings,
+			fmt.Sprintf("client has declared allergies: %s", strings.Join(profile.Allergies, ", ")))
+	}
+
+	return screening, nil
+}
@@ -1,8 +1,11 @@
 package iam
 
 import (
+	"errors"
 	"net/http"
 
+	"acme/etag"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -58,7 +61,7 @@ func (h *IAMHandler) GetClientByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, client)
+	etag.JSON(c, http.StatusOK, etag.Version(client.Version), client)
 }
 
 func (h *IAMHandler) GetClientByDNI(c *gin.Context) {
@@ -78,7 +81,7 @@ func (h *IAMHandler) GetClientByDNI(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, client)
+	etag.JSON(c, http.StatusOK, etag.Version(client.Version), client)
 }
 
 func (h *IAMHandler) UpdateClient(c *gin.Context) {
@@ -88,22 +91,33 @@ func (h *IAMHandler) UpdateClient(c *gin.Context) {
 		return
 	}
 
+	version, err := etag.IfMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var req UpdateClientRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	client, err := h.service.UpdateClient(id, req)
+	client, err := h.service.UpdateClient(id, req, version)
 	if err != nil {
 		if err.Error() == "client not found" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Client not found"})
 			return
 		}
+		if errors.Is(err, etag.ErrModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", etag.Version(client.Version))
 	c.JSON(http.StatusOK, client)
 }
 
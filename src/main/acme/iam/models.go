@@ -15,6 +15,7 @@ type Client struct {
 	RegistrationDate time.Time `json:"registration_date" db:"registration_date"`
 	ReniecValidated  bool      `json:"reniec_validated" db:"reniec_validated"`
 	FullName         string    `json:"full_name"`
+	Version          int       `json:"version" db:"version"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
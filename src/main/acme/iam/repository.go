@@ -1,8 +1,10 @@
 package iam
 
 import (
+	"acme/etag"
 	"database/sql"
 	"fmt"
+	"strings"
 )
 
 type Repository struct {
@@ -17,7 +19,7 @@ func (r *Repository) CreateClient(client *Client) error {
 	query := `
 		INSERT INTO clients (first_name, last_name, second_last_name, dni, email, phone, reniec_validated)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, registration_date, created_at, updated_at`
+		RETURNING id, registration_date, version, created_at, updated_at`
 
 	err := r.db.QueryRow(
 		query,
@@ -31,6 +33,7 @@ func (r *Repository) CreateClient(client *Client) error {
 	).Scan(
 		&client.ID,
 		&client.RegistrationDate,
+		&client.Version,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 	)
@@ -50,7 +53,7 @@ func (r *Repository) GetClientByID(id string) (*Client, error) {
 	client := &Client{}
 	query := `
 		SELECT id, first_name, last_name, second_last_name, dni, email, phone, 
-		       registration_date, reniec_validated, created_at, updated_at
+		       registration_date, reniec_validated, version, created_at, updated_at
 		FROM clients WHERE id = $1`
 
 	err := r.db.QueryRow(query, id).Scan(
@@ -63,6 +66,7 @@ func (r *Repository) GetClientByID(id string) (*Client, error) {
 		&client.Phone,
 		&client.RegistrationDate,
 		&client.ReniecValidated,
+		&client.Version,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 	)
@@ -85,7 +89,7 @@ func (r *Repository) GetClientByDNI(dni string) (*Client, error) {
 	client := &Client{}
 	query := `
 		SELECT id, first_name, last_name, second_last_name, dni, email, phone, 
-		       registration_date, reniec_validated, created_at, updated_at
+		       registration_date, reniec_validated, version, created_at, updated_at
 		FROM clients WHERE dni = $1`
 
 	err := r.db.QueryRow(query, dni).Scan(
@@ -98,6 +102,7 @@ func (r *Repository) GetClientByDNI(dni string) (*Client, error) {
 		&client.Phone,
 		&client.RegistrationDate,
 		&client.ReniecValidated,
+		&client.Version,
 		&client.CreatedAt,
 		&client.UpdatedAt,
 	)
@@ -116,7 +121,9 @@ func (r *Repository) GetClientByDNI(dni string) (*Client, error) {
 	return client, nil
 }
 
-func (r *Repository) UpdateClient(id string, updates UpdateClientRequest) error {
+// UpdateClient applies the changes. When version is set the update only
+// succeeds if the client is still at that version.
+func (r *Repository) UpdateClient(id string, updates UpdateClientRequest, version *int) error {
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -152,16 +159,32 @@ func (r *Repository) UpdateClient(id string, updates UpdateClientRequest) error
 	}
 
 	query := fmt.Sprintf("UPDATE clients SET %s WHERE id = $%d", 
-		fmt.Sprintf("%s", setParts[0]), argIndex)
-	for i := 1; i < len(setParts); i++ {
-		query = fmt.Sprintf("UPDATE clients SET %s, %s WHERE id = $%d", 
-			setParts[0], setParts[i], argIndex)
-	}
-	
+		strings.Join(setParts, ", "), argIndex)
 	args = append(args, id)
 
-	_, err := r.db.Exec(query, args...)
-	return err
+	if version != nil {
+		query += fmt.Sprintf(" AND version = $%d", argIndex+1)
+		args = append(args, *version)
+	}
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		if _, err := r.GetClientByID(id); err != nil {
+			return err
+		}
+		return etag.ErrModified
+	}
+
+	return nil
 }
 
 func (r *Repository) UpdateReniecValidation(id string, validated bool) error {
@@ -173,7 +196,7 @@ func (r *Repository) UpdateReniecValidation(id string, validated bool) error {
 func (r *Repository) GetAllClients() ([]Client, error) {
 	query := `
 		SELECT id, first_name, last_name, second_last_name, dni, email, phone, 
-		       registration_date, reniec_validated, created_at, updated_at
+		       registration_date, reniec_validated, version, created_at, updated_at
 		FROM clients ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query)
@@ -195,6 +218,7 @@ func (r *Repository) GetAllClients() ([]Client, error) {
 			&client.Phone,
 			&client.RegistrationDate,
 			&client.ReniecValidated,
+			&client.Version,
 			&client.CreatedAt,
 			&client.UpdatedAt,
 		)
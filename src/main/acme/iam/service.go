@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"acme/config"
+	"acme/etag"
 )
 
 type IAMService struct {
@@ -125,8 +126,13 @@ func (s *IAMService) GetClientByDNI(dni string) (*Client, error) {
 	return s.repo.GetClientByDNI(dni)
 }
 
-func (s *IAMService) UpdateClient(id string, req UpdateClientRequest) (*Client, error) {
-	if err := s.repo.UpdateClient(id, req); err != nil {
+// UpdateClient applies the changes, optionally only if the client is still
+// at the given version.
+func (s *IAMService) UpdateClient(id string, req UpdateClientRequest, version *int) (*Client, error) {
+	if err := s.repo.UpdateClient(id, req, version); err != nil {
+		if err == etag.ErrModified || err.Error() == "client not found" {
+			return nil, err
+		}
 		return nil, fmt.Errorf("error updating client: %w", err)
 	}
 
@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderKey is the request header carrying the client's idempotency key.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed marks responses replayed from a stored record.
+const HeaderReplayed = "Idempotent-Replayed"
+
+type IdempotencyHandler struct {
+	service *Service
+}
+
+func NewIdempotencyHandler(service *Service) *IdempotencyHandler {
+	return &IdempotencyHandler{service: service}
+}
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// Handle is middleware for mutating requests that carry an Idempotency-Key
+// header. The first request with a key runs normally and its response is
+// stored; retries with the same key and body get the stored response back,
+// and a key reused with a different request is rejected with 422.
+func (h *IdempotencyHandler) Handle(c *gin.Context) {
+	key := c.GetHeader(HeaderKey)
+	if key == "" || !isMutating(c.Request.Method) {
+		c.Next()
+		return
+	}
+
+	if len(key) > MaxKeyLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	record, err := h.service.Begin(key, Fingerprint(c.Request.Method, c.Request.URL.RequestURI(), body))
+	if err != nil {
+		if err.Error() == "idempotency key was already used with a different request" {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if record != nil {
+		if record.StatusCode == nil {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this idempotency key is still being processed"})
+			return
+		}
+
+		contentType := "application/json; charset=utf-8"
+		if record.ContentType != nil {
+			contentType = *record.ContentType
+		}
+
+		c.Header(HeaderReplayed, "true")
+		if record.ETag != nil {
+			c.Header("ETag", *record.ETag)
+		}
+		c.Data(*record.StatusCode, contentType, record.ResponseBody)
+		c.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	// A panic unwinds past the code after c.Next; free the key so a retry
+	// is not rejected as still being processed.
+	finished := false
+	defer func() {
+		if !finished {
+			if err := h.service.Abandon(key); err != nil {
+				log.Printf("Warning: Failed to release idempotency key %s: %v", key, err)
+			}
+		}
+	}()
+
+	c.Next()
+	finished = true
+
+	status := recorder.Status()
+	if err := h.service.Complete(key, status, recorder.Header().Get("Content-Type"), recorder.Header().Get("ETag"), recorder.body.Bytes()); err != nil {
+		log.Printf("Warning: Failed to store response for idempotency key %s: %v", key, err)
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
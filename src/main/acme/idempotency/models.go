@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// Record is a stored request and the response it produced. StatusCode is
+// nil while the original request is still being processed.
+type Record struct {
+	Key          string    `json:"key" db:"key"`
+	Fingerprint  string    `json:"fingerprint" db:"fingerprint"`
+	StatusCode   *int      `json:"status_code" db:"status_code"`
+	ContentType  *string   `json:"content_type" db:"content_type"`
+	ETag         *string   `json:"etag" db:"etag"`
+	ResponseBody []byte    `json:"-" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
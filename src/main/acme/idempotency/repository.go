@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Reserve claims the key for a new request. It returns false when the key is
+// already taken by a request that has not expired. An expired key is
+// claimed again.
+func (r *Repository) Reserve(key, fingerprint string, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			content_type = NULL,
+			etag = NULL,
+			response_body = NULL,
+			created_at = CURRENT_TIMESTAMP,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+		RETURNING key`
+
+	var claimed string
+	err := r.db.QueryRow(query, key, fingerprint, ttl.Seconds()).Scan(&claimed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("error reserving idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *Repository) GetRecord(key string) (*Record, error) {
+	record := &Record{}
+	query := `
+		SELECT key, fingerprint, status_code, content_type, etag, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE key = $1`
+
+	err := r.db.QueryRow(query, key).Scan(
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ETag,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("idempotency key not found")
+		}
+		return nil, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+
+	return record, nil
+}
+
+// SaveResponse stores the response of the request that reserved the key.
+func (r *Repository) SaveResponse(key string, statusCode int, contentType, etag string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys SET status_code = $2, content_type = $3, etag = NULLIF($4, ''), response_body = $5
+		WHERE key = $1`
+
+	if _, err := r.db.Exec(query, key, statusCode, contentType, etag, body); err != nil {
+		return fmt.Errorf("error saving idempotent response: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Release(key string) error {
+	if _, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteExpired() (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
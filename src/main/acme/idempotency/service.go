@@ -0,0 +1,98 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// recordTTL is how long a key and its response are kept for replay.
+const recordTTL = 24 * time.Hour
+
+// cleanupInterval is how often expired keys are deleted.
+const cleanupInterval = time.Hour
+
+// MaxKeyLength matches the size of the key column.
+const MaxKeyLength = 255
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Fingerprint identifies a request by its method, URI including the query
+// string, and body, so a key reused for a different request can be told
+// apart from a retry.
+func Fingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", method, uri)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin claims the key for the request. When the key was already used it
+// returns the stored record instead, which the caller must replay or reject.
+func (s *Service) Begin(key, fingerprint string) (*Record, error) {
+	claimed, err := s.repo.Reserve(key, fingerprint, recordTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimed {
+		return nil, nil
+	}
+
+	record, err := s.repo.GetRecord(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("idempotency key was already used with a different request")
+	}
+
+	return record, nil
+}
+
+// Complete stores the response for replay. Server errors are not stored so
+// the client can retry them with the same key.
+func (s *Service) Complete(key string, statusCode int, contentType, etag string, body []byte) error {
+	if statusCode >= 500 {
+		return s.repo.Release(key)
+	}
+
+	return s.repo.SaveResponse(key, statusCode, contentType, etag, body)
+}
+
+// Abandon frees the key of a request that did not finish, e.g. because its
+// handler panicked, so the client can retry it.
+func (s *Service) Abandon(key string) error {
+	return s.repo.Release(key)
+}
+
+// StartCleanup periodically deletes expired keys. It stops when ctx is
+// cancelled.
+func (s *Service) StartCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.repo.DeleteExpired(); err != nil {
+				log.Printf("Idempotency key cleanup failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
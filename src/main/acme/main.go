@@ -17,6 +17,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
@@ -67,6 +68,10 @@ func main() {
 		log.Fatal("Failed to create tables:", err)
 	}
 
+	if err := database.BackfillAppointmentInstants(db, cfg.Scheduling.Timezone); err != nil {
+		log.Fatal("Failed to backfill appointment times:", err)
+	}
+
 	log.Println("Seeding initial data...")
 	if err := database.SeedData(db); err != nil {
 		log.Fatal("Failed to seed data:", err)
@@ -79,6 +84,18 @@ func main() {
 		log.Fatal("Failed to create services:", err)
 	}
 
+	// Flag appointments the client did not attend
+	services.Appointments.StartNoShowDetection(context.Background())
+
+	// Expire unconfirmed holds and pass waitlist offers on
+	services.Appointments.StartHoldSweeper(context.Background())
+
+	// Remind clients of their confirmed appointments
+	services.Appointments.StartReminderScheduler(context.Background())
+
+	// Forget idempotency keys once they can no longer be replayed
+	services.Idempotency.StartCleanup(context.Background())
+
 	// Create handlers
 	handlers := serviceFactory.CreateHandlers(services)
 
@@ -0,0 +1,47 @@
+package notifications
+
+import (
+	"time"
+)
+
+// Notification is a message to a client. It is stored before it is handed
+// to the delivery channel, so every message sent can be looked up later.
+type Notification struct {
+	ID            string     `json:"id" db:"id"`
+	ClientID      string     `json:"client_id" db:"client_id"`
+	AppointmentID *string    `json:"appointment_id" db:"appointment_id"`
+	Kind          string     `json:"kind" db:"kind"`
+	Subject       string     `json:"subject" db:"subject"`
+	Message       string     `json:"message" db:"message"`
+	Channel       string     `json:"channel" db:"channel"`
+	Status        string     `json:"status" db:"status"`
+	Error         *string    `json:"error" db:"error"`
+	SentAt        *time.Time `json:"sent_at" db:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+type Kind string
+
+const (
+	KindSessionCancelled    Kind = "session_cancelled"
+	KindAppointmentReminder Kind = "appointment_reminder"
+	KindConfirmationRequest Kind = "confirmation_request"
+	KindWaitlistOffer       Kind = "waitlist_offer"
+	KindAppointmentMoved    Kind = "appointment_moved"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed"
+)
+
+type NotifyRequest struct {
+	ClientID      string
+	AppointmentID *string
+	Kind          Kind
+	Subject       string
+	Message       string
+}
@@ -0,0 +1,36 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationsHandler struct {
+	service *NotificationService
+}
+
+func NewNotificationsHandler(service *NotificationService) *NotificationsHandler {
+	return &NotificationsHandler{service: service}
+}
+
+// GetClientNotifications godoc
+// @Summary List a client's notifications
+// @Description List the messages sent to a client, newest first, with their delivery status
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {array} Notification
+// @Failure 500 {object} map[string]interface{}
+// @Router /clients/{id}/notifications [get]
+func (h *NotificationsHandler) GetClientNotifications(c *gin.Context) {
+	clientID := c.Param("id")
+
+	notifications, err := h.service.GetClientNotifications(clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
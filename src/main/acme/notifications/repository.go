@@ -0,0 +1,131 @@
+package notifications
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateNotification(notification *Notification) error {
+	query := `
+		INSERT INTO notifications (client_id, appointment_id, kind, subject, message, channel, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		notification.ClientID,
+		notification.AppointmentID,
+		notification.Kind,
+		notification.Subject,
+		notification.Message,
+		notification.Channel,
+		notification.Status,
+	).Scan(&notification.ID, &notification.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating notification: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDelivered records the outcome of handing the notification to its
+// channel. A nil deliveryErr means it was sent.
+func (r *Repository) MarkDelivered(notification *Notification, deliveryErr error) error {
+	notification.Status = string(StatusSent)
+	notification.Error = nil
+	if deliveryErr != nil {
+		message := deliveryErr.Error()
+		notification.Status = string(StatusFailed)
+		notification.Error = &message
+	}
+
+	query := `
+		UPDATE notifications
+		SET status = $2, error = $3, sent_at = CASE WHEN $2 = 'sent' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1
+		RETURNING sent_at`
+
+	err := r.db.QueryRow(query, notification.ID, notification.Status, notification.Error).Scan(&notification.SentAt)
+	if err != nil {
+		return fmt.Errorf("error updating notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetNotificationsByClient(clientID string) ([]Notification, error) {
+	query := `
+		SELECT id, client_id, appointment_id, kind, subject, message, channel, status, error, sent_at, created_at
+		FROM notifications
+		WHERE client_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var notification Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.ClientID,
+			&notification.AppointmentID,
+			&notification.Kind,
+			&notification.Subject,
+			&notification.Message,
+			&notification.Channel,
+			&notification.Status,
+			&notification.Error,
+			&notification.SentAt,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (r *Repository) GetNotificationByID(id string) (*Notification, error) {
+	query := `
+		SELECT id, client_id, appointment_id, kind, subject, message, channel, status, error, sent_at, created_at
+		FROM notifications
+		WHERE id = $1`
+
+	notification := &Notification{}
+	err := r.db.QueryRow(query, id).Scan(
+		&notification.ID,
+		&notification.ClientID,
+		&notification.AppointmentID,
+		&notification.Kind,
+		&notification.Subject,
+		&notification.Message,
+		&notification.Channel,
+		&notification.Status,
+		&notification.Error,
+		&notification.SentAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("notification not found")
+		}
+		return nil, fmt.Errorf("error getting notification: %w", err)
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"fmt"
+	"log"
+)
+
+// Channel delivers notifications to clients, e.g. by e-mail or WhatsApp.
+type Channel interface {
+	Name() string
+	Send(notification *Notification) error
+}
+
+// logChannel writes notifications to the application log. It is used until
+// a real delivery channel is configured.
+type logChannel struct{}
+
+// NewLogChannel returns a channel that only logs what would be sent.
+func NewLogChannel() Channel {
+	return logChannel{}
+}
+
+func (logChannel) Name() string {
+	return "log"
+}
+
+func (logChannel) Send(notification *Notification) error {
+	log.Printf("Notification to client %s: %s: %s", notification.ClientID, notification.Subject, notification.Message)
+	return nil
+}
+
+type NotificationService struct {
+	repo    *Repository
+	channel Channel
+}
+
+func NewService(repo *Repository, channel Channel) *NotificationService {
+	return &NotificationService{
+		repo:    repo,
+		channel: channel,
+	}
+}
+
+// Notify stores the notification and delivers it. A delivery failure is
+// recorded on the notification rather than returned.
+func (s *NotificationService) Notify(req NotifyRequest) (*Notification, error) {
+	notification, err := s.Enqueue(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.deliver(notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// Enqueue stores the notification without sending it. Callers that must
+// not send a message twice keep its ID and hand it to Deliver, so a retry
+// after a crash resends the same notification instead of a new one.
+func (s *NotificationService) Enqueue(req NotifyRequest) (*Notification, error) {
+	if req.ClientID == "" {
+		return nil, fmt.Errorf("client_id is required")
+	}
+
+	notification := &Notification{
+		ClientID:      req.ClientID,
+		AppointmentID: req.AppointmentID,
+		Kind:          string(req.Kind),
+		Subject:       req.Subject,
+		Message:       req.Message,
+		Channel:       s.channel.Name(),
+		Status:        string(StatusPending),
+	}
+
+	if err := s.repo.CreateNotification(notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// Deliver sends a stored notification unless it has already been sent.
+// The outcome is recorded on the returned notification.
+func (s *NotificationService) Deliver(id string) (*Notification, error) {
+	notification, err := s.repo.GetNotificationByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if notification.Status == string(StatusSent) {
+		return notification, nil
+	}
+
+	if err := s.deliver(notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func (s *NotificationService) deliver(notification *Notification) error {
+	return s.repo.MarkDelivered(notification, s.channel.Send(notification))
+}
+
+func (s *NotificationService) GetClientNotifications(clientID string) ([]Notification, error) {
+	return s.repo.GetNotificationsByClient(clientID)
+}
@@ -0,0 +1,101 @@
+package packages
+
+import (
+	"time"
+)
+
+type Package struct {
+	ID           string        `json:"id" db:"id"`
+	Name         string        `json:"name" db:"name"`
+	Description  *string       `json:"description" db:"description"`
+	Price        float64       `json:"price" db:"price"`
+	ValidityDays int           `json:"validity_days" db:"validity_days"`
+	Active       bool          `json:"active" db:"active"`
+	Items        []PackageItem `json:"items"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+type PackageItem struct {
+	ServiceID   string `json:"service_id" db:"service_id"`
+	ServiceName string `json:"service_name"`
+	Sessions    int    `json:"sessions" db:"sessions"`
+}
+
+type PackageItemRequest struct {
+	ServiceID string `json:"service_id" binding:"required"`
+	Sessions  int    `json:"sessions" binding:"required,min=1"`
+}
+
+type CreatePackageRequest struct {
+	Name         string               `json:"name" binding:"required"`
+	Description  *string              `json:"description"`
+	Price        float64              `json:"price" binding:"required,min=0"`
+	ValidityDays int                  `json:"validity_days" binding:"required,min=1"`
+	Items        []PackageItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type UpdatePackageRequest struct {
+	Name         *string  `json:"name"`
+	Description  *string  `json:"description"`
+	Price        *float64 `json:"price"`
+	ValidityDays *int     `json:"validity_days"`
+	Active       *bool    `json:"active"`
+}
+
+// ClientPackage is a package purchased by a client, with a balance per
+// service it covers.
+type ClientPackage struct {
+	ID          string    `json:"id" db:"id"`
+	ClientID    string    `json:"client_id" db:"client_id"`
+	PackageID   string    `json:"package_id" db:"package_id"`
+	PackageName string    `json:"package_name"`
+	PricePaid   float64   `json:"price_paid" db:"price_paid"`
+	PurchasedAt time.Time `json:"purchased_at" db:"purchased_at"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	Status      string    `json:"status"`
+	Balances    []Balance `json:"balances"`
+}
+
+// Balance tracks the sessions of one service in a purchased package.
+// Reserved sessions are held by booked appointments that are not completed
+// yet; they become used on completion or are released on cancellation.
+type Balance struct {
+	ServiceID         string `json:"service_id" db:"service_id"`
+	ServiceName       string `json:"service_name"`
+	SessionsTotal     int    `json:"sessions_total" db:"sessions_total"`
+	SessionsUsed      int    `json:"sessions_used" db:"sessions_used"`
+	SessionsReserved  int    `json:"sessions_reserved" db:"sessions_reserved"`
+	SessionsRemaining int    `json:"sessions_remaining"`
+}
+
+type PurchasePackageRequest struct {
+	PackageID string `json:"package_id" binding:"required"`
+}
+
+type Usage struct {
+	ID              string    `json:"id" db:"id"`
+	ClientPackageID string    `json:"client_package_id" db:"client_package_id"`
+	ServiceID       string    `json:"service_id" db:"service_id"`
+	ServiceName     string    `json:"service_name"`
+	AppointmentID   *string   `json:"appointment_id" db:"appointment_id"`
+	Action          string    `json:"action" db:"action"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+type UsageAction string
+
+const (
+	UsageReserved  UsageAction = "reserved"
+	UsageRedeemed  UsageAction = "redeemed"
+	UsageRestored  UsageAction = "restored"
+	UsageForfeited UsageAction = "forfeited"
+)
+
+type ClientPackageStatus string
+
+const (
+	ClientPackageActive    ClientPackageStatus = "active"
+	ClientPackageExpired   ClientPackageStatus = "expired"
+	ClientPackageExhausted ClientPackageStatus = "exhausted"
+)
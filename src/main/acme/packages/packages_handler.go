@@ -0,0 +1,181 @@
+package packages
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PackagesHandler struct {
+	service *PackageService
+}
+
+func NewPackagesHandler(service *PackageService) *PackagesHandler {
+	return &PackagesHandler{service: service}
+}
+
+// CreatePackage godoc
+// @Summary Create a session package
+// @Description Create a prepaid bundle of sessions of one or more services
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param package body CreatePackageRequest true "Package data"
+// @Success 201 {object} Package
+// @Failure 400 {object} map[string]interface{}
+// @Router /packages [post]
+func (h *PackagesHandler) CreatePackage(c *gin.Context) {
+	var req CreatePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pkg, err := h.service.CreatePackage(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, pkg)
+}
+
+// GetAllPackages godoc
+// @Summary Get all packages
+// @Description Get the list of session packages with their services
+// @Tags packages
+// @Produce json
+// @Success 200 {array} Package
+// @Failure 500 {object} map[string]interface{}
+// @Router /packages [get]
+func (h *PackagesHandler) GetAllPackages(c *gin.Context) {
+	packages, err := h.service.GetAllPackages()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, packages)
+}
+
+func (h *PackagesHandler) GetPackageByID(c *gin.Context) {
+	id := c.Param("id")
+
+	pkg, err := h.service.GetPackageByID(id)
+	if err != nil {
+		if err.Error() == "package not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pkg)
+}
+
+func (h *PackagesHandler) UpdatePackage(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdatePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pkg, err := h.service.UpdatePackage(id, req)
+	if err != nil {
+		if err.Error() == "package not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, pkg)
+}
+
+// PurchasePackage godoc
+// @Summary Sell a package to a client
+// @Description Record a client's purchase and open a session balance per service
+// @Tags packages
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param purchase body PurchasePackageRequest true "Package to purchase"
+// @Success 201 {object} ClientPackage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/packages [post]
+func (h *PackagesHandler) PurchasePackage(c *gin.Context) {
+	clientID := c.Param("id")
+
+	var req PurchasePackageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clientPackage, err := h.service.PurchasePackage(clientID, req)
+	if err != nil {
+		if err.Error() == "package not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Package not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, clientPackage)
+}
+
+// GetClientPackages godoc
+// @Summary Get a client's packages
+// @Description Get the client's active packages with remaining sessions; pass all=true to include expired and used up ones
+// @Tags packages
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param all query bool false "Include expired and exhausted packages"
+// @Success 200 {array} ClientPackage
+// @Failure 500 {object} map[string]interface{}
+// @Router /clients/{id}/packages [get]
+func (h *PackagesHandler) GetClientPackages(c *gin.Context) {
+	clientID := c.Param("id")
+	activeOnly := c.Query("all") != "true"
+
+	clientPackages, err := h.service.GetClientPackages(clientID, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, clientPackages)
+}
+
+// GetUsageHistory godoc
+// @Summary Get package usage history
+// @Description Get every reservation, redemption and restoration of a client's package
+// @Tags packages
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param client_package_id path string true "Client package ID"
+// @Success 200 {array} Usage
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/packages/{client_package_id}/usage [get]
+func (h *PackagesHandler) GetUsageHistory(c *gin.Context) {
+	clientID := c.Param("id")
+	clientPackageID := c.Param("client_package_id")
+
+	usages, err := h.service.GetUsageHistory(clientID, clientPackageID)
+	if err != nil {
+		if err.Error() == "client package not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Client package not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, usages)
+}
@@ -0,0 +1,528 @@
+package packages
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreatePackage(pkg *Package) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO packages (name, description, price, validity_days)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, active, created_at, updated_at`
+
+	err = tx.QueryRow(
+		query,
+		pkg.Name,
+		pkg.Description,
+		pkg.Price,
+		pkg.ValidityDays,
+	).Scan(
+		&pkg.ID,
+		&pkg.Active,
+		&pkg.CreatedAt,
+		&pkg.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating package: %w", err)
+	}
+
+	for _, item := range pkg.Items {
+		_, err := tx.Exec(
+			`INSERT INTO package_items (package_id, service_id, sessions) VALUES ($1, $2, $3)`,
+			pkg.ID, item.ServiceID, item.Sessions,
+		)
+		if err != nil {
+			return fmt.Errorf("error creating package item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing package: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetPackageByID(id string) (*Package, error) {
+	pkg := &Package{}
+	query := `
+		SELECT id, name, description, price, validity_days, active, created_at, updated_at
+		FROM packages WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&pkg.ID,
+		&pkg.Name,
+		&pkg.Description,
+		&pkg.Price,
+		&pkg.ValidityDays,
+		&pkg.Active,
+		&pkg.CreatedAt,
+		&pkg.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("package not found")
+		}
+		return nil, fmt.Errorf("error getting package: %w", err)
+	}
+
+	pkg.Items, err = r.getPackageItems(pkg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+func (r *Repository) GetAllPackages() ([]Package, error) {
+	query := `
+		SELECT id, name, description, price, validity_days, active, created_at, updated_at
+		FROM packages ORDER BY name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying packages: %w", err)
+	}
+	defer rows.Close()
+
+	var packages []Package
+	for rows.Next() {
+		var pkg Package
+		err := rows.Scan(
+			&pkg.ID,
+			&pkg.Name,
+			&pkg.Description,
+			&pkg.Price,
+			&pkg.ValidityDays,
+			&pkg.Active,
+			&pkg.CreatedAt,
+			&pkg.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning package: %w", err)
+		}
+		packages = append(packages, pkg)
+	}
+	rows.Close()
+
+	for i := range packages {
+		packages[i].Items, err = r.getPackageItems(packages[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return packages, nil
+}
+
+func (r *Repository) getPackageItems(packageID string) ([]PackageItem, error) {
+	query := `
+		SELECT pi.service_id, s.name, pi.sessions
+		FROM package_items pi
+		JOIN services s ON pi.service_id = s.id
+		WHERE pi.package_id = $1
+		ORDER BY s.name ASC`
+
+	rows, err := r.db.Query(query, packageID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying package items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []PackageItem{}
+	for rows.Next() {
+		var item PackageItem
+		if err := rows.Scan(&item.ServiceID, &item.ServiceName, &item.Sessions); err != nil {
+			return nil, fmt.Errorf("error scanning package item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *Repository) UpdatePackage(id string, updates UpdatePackageRequest) error {
+	setParts := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if updates.Name != nil {
+		setParts = append(setParts, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, *updates.Name)
+		argIndex++
+	}
+	if updates.Description != nil {
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, updates.Description)
+		argIndex++
+	}
+	if updates.Price != nil {
+		setParts = append(setParts, fmt.Sprintf("price = $%d", argIndex))
+		args = append(args, *updates.Price)
+		argIndex++
+	}
+	if updates.ValidityDays != nil {
+		setParts = append(setParts, fmt.Sprintf("validity_days = $%d", argIndex))
+		args = append(args, *updates.ValidityDays)
+		argIndex++
+	}
+	if updates.Active != nil {
+		setParts = append(setParts, fmt.Sprintf("active = $%d", argIndex))
+		args = append(args, *updates.Active)
+		argIndex++
+	}
+
+	if len(setParts) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	query := fmt.Sprintf("UPDATE packages SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "), argIndex)
+	args = append(args, id)
+
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("package not found")
+	}
+
+	return nil
+}
+
+// PurchasePackage records a client's purchase and opens a balance for every
+// service in the package.
+func (r *Repository) PurchasePackage(clientPackage *ClientPackage, items []PackageItem) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO client_packages (client_id, package_id, price_paid, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, purchased_at`
+
+	err = tx.QueryRow(
+		query,
+		clientPackage.ClientID,
+		clientPackage.PackageID,
+		clientPackage.PricePaid,
+		clientPackage.ExpiresAt,
+	).Scan(
+		&clientPackage.ID,
+		&clientPackage.PurchasedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error purchasing package: %w", err)
+	}
+
+	for _, item := range items {
+		_, err := tx.Exec(
+			`INSERT INTO client_package_balances (client_package_id, service_id, sessions_total) VALUES ($1, $2, $3)`,
+			clientPackage.ID, item.ServiceID, item.Sessions,
+		)
+		if err != nil {
+			return fmt.Errorf("error creating package balance: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing package purchase: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetClientPackageByID(id string) (*ClientPackage, error) {
+	clientPackage := &ClientPackage{}
+	query := `
+		SELECT cp.id, cp.client_id, cp.package_id, p.name, cp.price_paid, cp.purchased_at, cp.expires_at
+		FROM client_packages cp
+		JOIN packages p ON cp.package_id = p.id
+		WHERE cp.id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&clientPackage.ID,
+		&clientPackage.ClientID,
+		&clientPackage.PackageID,
+		&clientPackage.PackageName,
+		&clientPackage.PricePaid,
+		&clientPackage.PurchasedAt,
+		&clientPackage.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("client package not found")
+		}
+		return nil, fmt.Errorf("error getting client package: %w", err)
+	}
+
+	clientPackage.Balances, err = r.getBalances(clientPackage.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return clientPackage, nil
+}
+
+func (r *Repository) GetClientPackages(clientID string) ([]ClientPackage, error) {
+	query := `
+		SELECT cp.id, cp.client_id, cp.package_id, p.name, cp.price_paid, cp.purchased_at, cp.expires_at
+		FROM client_packages cp
+		JOIN packages p ON cp.package_id = p.id
+		WHERE cp.client_id = $1
+		ORDER BY cp.expires_at ASC`
+
+	rows, err := r.db.Query(query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying client packages: %w", err)
+	}
+	defer rows.Close()
+
+	var clientPackages []ClientPackage
+	for rows.Next() {
+		var clientPackage ClientPackage
+		err := rows.Scan(
+			&clientPackage.ID,
+			&clientPackage.ClientID,
+			&clientPackage.PackageID,
+			&clientPackage.PackageName,
+			&clientPackage.PricePaid,
+			&clientPackage.PurchasedAt,
+			&clientPackage.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning client package: %w", err)
+		}
+		clientPackages = append(clientPackages, clientPackage)
+	}
+	rows.Close()
+
+	for i := range clientPackages {
+		clientPackages[i].Balances, err = r.getBalances(clientPackages[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clientPackages, nil
+}
+
+func (r *Repository) getBalances(clientPackageID string) ([]Balance, error) {
+	query := `
+		SELECT b.service_id, s.name, b.sessions_total, b.sessions_used, b.sessions_reserved
+		FROM client_package_balances b
+		JOIN services s ON b.service_id = s.id
+		WHERE b.client_package_id = $1
+		ORDER BY s.name ASC`
+
+	rows, err := r.db.Query(query, clientPackageID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying package balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []Balance{}
+	for rows.Next() {
+		var balance Balance
+		err := rows.Scan(
+			&balance.ServiceID,
+			&balance.ServiceName,
+			&balance.SessionsTotal,
+			&balance.SessionsUsed,
+			&balance.SessionsReserved,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning package balance: %w", err)
+		}
+		balance.SessionsRemaining = balance.SessionsTotal - balance.SessionsUsed - balance.SessionsReserved
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
+
+// ReserveSession holds one session of the service for an appointment. The
+// balance row is locked so concurrent bookings cannot overdraw it.
+func (r *Repository) ReserveSession(clientPackageID, clientID, serviceID, appointmentID string, now time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ownerID string
+	var expiresAt time.Time
+	err = tx.QueryRow(`SELECT client_id, expires_at FROM client_packages WHERE id = $1`, clientPackageID).Scan(&ownerID, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("client package not found")
+		}
+		return fmt.Errorf("error getting client package: %w", err)
+	}
+
+	if ownerID != clientID {
+		return fmt.Errorf("package does not belong to this client")
+	}
+
+	if !now.Before(expiresAt) {
+		return fmt.Errorf("package has expired")
+	}
+
+	var remaining int
+	err = tx.QueryRow(`
+		SELECT sessions_total - sessions_used - sessions_reserved
+		FROM client_package_balances
+		WHERE client_package_id = $1 AND service_id = $2
+		FOR UPDATE`, clientPackageID, serviceID).Scan(&remaining)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("package does not include this service")
+		}
+		return fmt.Errorf("error getting package balance: %w", err)
+	}
+
+	if remaining < 1 {
+		return fmt.Errorf("no sessions remaining in package for this service")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE client_package_balances SET sessions_reserved = sessions_reserved + 1
+		WHERE client_package_id = $1 AND service_id = $2`, clientPackageID, serviceID)
+	if err != nil {
+		return fmt.Errorf("error reserving package session: %w", err)
+	}
+
+	if err := insertUsage(tx, clientPackageID, serviceID, appointmentID, UsageReserved); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing package reservation: %w", err)
+	}
+
+	return nil
+}
+
+// SettleSession resolves the session reserved for an appointment: it is
+// either consumed (redeemed or forfeited) or given back (restored). It does
+// nothing when the appointment holds no unresolved reservation.
+func (r *Repository) SettleSession(appointmentID string, action UsageAction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var clientPackageID, serviceID string
+	err = tx.QueryRow(`
+		SELECT u.client_package_id, u.service_id FROM package_usages u
+		WHERE u.appointment_id = $1 AND u.action = 'reserved'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM package_usages s
+		      WHERE s.appointment_id = u.appointment_id AND s.action != 'reserved' AND s.created_at >= u.created_at)
+		ORDER BY u.created_at DESC LIMIT 1
+		FOR UPDATE`, appointmentID).Scan(&clientPackageID, &serviceID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("error getting package reservation: %w", err)
+	}
+
+	update := `UPDATE client_package_balances SET sessions_reserved = sessions_reserved - 1`
+	if action == UsageRedeemed || action == UsageForfeited {
+		update += `, sessions_used = sessions_used + 1`
+	}
+	update += ` WHERE client_package_id = $1 AND service_id = $2`
+
+	if _, err := tx.Exec(update, clientPackageID, serviceID); err != nil {
+		return fmt.Errorf("error settling package session: %w", err)
+	}
+
+	if err := insertUsage(tx, clientPackageID, serviceID, appointmentID, action); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing package settlement: %w", err)
+	}
+
+	return nil
+}
+
+func insertUsage(tx *sql.Tx, clientPackageID, serviceID, appointmentID string, action UsageAction) error {
+	_, err := tx.Exec(
+		`INSERT INTO package_usages (client_package_id, service_id, appointment_id, action) VALUES ($1, $2, $3, $4)`,
+		clientPackageID, serviceID, appointmentID, string(action),
+	)
+	if err != nil {
+		return fmt.Errorf("error recording package usage: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) GetUsageHistory(clientPackageID string) ([]Usage, error) {
+	query := `
+		SELECT u.id, u.client_package_id, u.service_id, s.name, u.appointment_id, u.action, u.created_at
+		FROM package_usages u
+		JOIN services s ON u.service_id = s.id
+		WHERE u.client_package_id = $1
+		ORDER BY u.created_at DESC`
+
+	rows, err := r.db.Query(query, clientPackageID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying package usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []Usage
+	for rows.Next() {
+		var usage Usage
+		err := rows.Scan(
+			&usage.ID,
+			&usage.ClientPackageID,
+			&usage.ServiceID,
+			&usage.ServiceName,
+			&usage.AppointmentID,
+			&usage.Action,
+			&usage.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning package usage: %w", err)
+		}
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
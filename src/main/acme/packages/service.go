@@ -0,0 +1,173 @@
+package packages
+
+import (
+	"acme/clock"
+	"fmt"
+	"time"
+)
+
+type PackageService struct {
+	repo  *Repository
+	clock clock.Clock
+}
+
+func NewService(repo *Repository, clk clock.Clock) *PackageService {
+	return &PackageService{repo: repo, clock: clk}
+}
+
+func (s *PackageService) CreatePackage(req CreatePackageRequest) (*Package, error) {
+	seen := map[string]bool{}
+	items := make([]PackageItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ServiceID] {
+			return nil, fmt.Errorf("service %s is listed more than once", item.ServiceID)
+		}
+		seen[item.ServiceID] = true
+		items = append(items, PackageItem{ServiceID: item.ServiceID, Sessions: item.Sessions})
+	}
+
+	pkg := &Package{
+		Name:         req.Name,
+		Description:  req.Description,
+		Price:        req.Price,
+		ValidityDays: req.ValidityDays,
+		Items:        items,
+	}
+
+	if err := s.repo.CreatePackage(pkg); err != nil {
+		return nil, fmt.Errorf("error creating package: %w", err)
+	}
+
+	return s.repo.GetPackageByID(pkg.ID)
+}
+
+func (s *PackageService) GetPackageByID(id string) (*Package, error) {
+	return s.repo.GetPackageByID(id)
+}
+
+func (s *PackageService) GetAllPackages() ([]Package, error) {
+	return s.repo.GetAllPackages()
+}
+
+func (s *PackageService) UpdatePackage(id string, req UpdatePackageRequest) (*Package, error) {
+	if req.Price != nil && *req.Price < 0 {
+		return nil, fmt.Errorf("price cannot be negative")
+	}
+
+	if req.ValidityDays != nil && *req.ValidityDays < 1 {
+		return nil, fmt.Errorf("validity_days must be at least 1")
+	}
+
+	if err := s.repo.UpdatePackage(id, req); err != nil {
+		return nil, fmt.Errorf("error updating package: %w", err)
+	}
+
+	return s.repo.GetPackageByID(id)
+}
+
+// PurchasePackage sells a package to a client at its current price. The
+// expiry is counted from the moment of purchase.
+func (s *PackageService) PurchasePackage(clientID string, req PurchasePackageRequest) (*ClientPackage, error) {
+	pkg, err := s.repo.GetPackageByID(req.PackageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pkg.Active {
+		return nil, fmt.Errorf("package is not available for sale")
+	}
+
+	clientPackage := &ClientPackage{
+		ClientID:  clientID,
+		PackageID: pkg.ID,
+		PricePaid: pkg.Price,
+		ExpiresAt: s.clock.Now().AddDate(0, 0, pkg.ValidityDays),
+	}
+
+	if err := s.repo.PurchasePackage(clientPackage, pkg.Items); err != nil {
+		return nil, err
+	}
+
+	return s.GetClientPackage(clientPackage.ID)
+}
+
+func (s *PackageService) GetClientPackage(id string) (*ClientPackage, error) {
+	clientPackage, err := s.repo.GetClientPackageByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	clientPackage.Status = string(packageStatus(clientPackage, s.clock.Now()))
+	return clientPackage, nil
+}
+
+// GetClientPackages returns the client's packages; with activeOnly, expired
+// and fully used packages are left out.
+func (s *PackageService) GetClientPackages(clientID string, activeOnly bool) ([]ClientPackage, error) {
+	clientPackages, err := s.repo.GetClientPackages(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	result := []ClientPackage{}
+	for _, clientPackage := range clientPackages {
+		clientPackage.Status = string(packageStatus(&clientPackage, now))
+		if activeOnly && clientPackage.Status != string(ClientPackageActive) {
+			continue
+		}
+		result = append(result, clientPackage)
+	}
+
+	return result, nil
+}
+
+func (s *PackageService) GetUsageHistory(clientID, clientPackageID string) ([]Usage, error) {
+	clientPackage, err := s.repo.GetClientPackageByID(clientPackageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientPackage.ClientID != clientID {
+		return nil, fmt.Errorf("client package not found")
+	}
+
+	return s.repo.GetUsageHistory(clientPackageID)
+}
+
+// ReserveSession holds a session of the package for a newly booked
+// appointment.
+func (s *PackageService) ReserveSession(clientPackageID, clientID, serviceID, appointmentID string) error {
+	return s.repo.ReserveSession(clientPackageID, clientID, serviceID, appointmentID, s.clock.Now())
+}
+
+// RedeemSession consumes the session reserved by a completed appointment.
+func (s *PackageService) RedeemSession(appointmentID string) error {
+	return s.repo.SettleSession(appointmentID, UsageRedeemed)
+}
+
+// RestoreSession gives the session reserved by a cancelled appointment back
+// to the client's balance.
+func (s *PackageService) RestoreSession(appointmentID string) error {
+	return s.repo.SettleSession(appointmentID, UsageRestored)
+}
+
+// ForfeitSession consumes the reserved session without the service being
+// delivered, e.g. when the client does not show up.
+func (s *PackageService) ForfeitSession(appointmentID string) error {
+	return s.repo.SettleSession(appointmentID, UsageForfeited)
+}
+
+func packageStatus(clientPackage *ClientPackage, now time.Time) ClientPackageStatus {
+	if !now.Before(clientPackage.ExpiresAt) {
+		return ClientPackageExpired
+	}
+
+	for _, balance := range clientPackage.Balances {
+		if balance.SessionsUsed < balance.SessionsTotal {
+			return ClientPackageActive
+		}
+	}
+
+	return ClientPackageExhausted
+}
@@ -0,0 +1,31 @@
+package policies
+
+import (
+	"time"
+)
+
+// Policy sets the cancellation and rescheduling rules of a service. Services
+// without a stored policy use the configured defaults.
+type Policy struct {
+	ServiceID             string     `json:"service_id" db:"service_id"`
+	MinNoticeHours        int        `json:"min_notice_hours" db:"min_notice_hours"`
+	MaxReschedules        int        `json:"max_reschedules" db:"max_reschedules"`
+	LateCancellationFee   float64    `json:"late_cancellation_fee" db:"late_cancellation_fee"`
+	ForfeitPackageSession bool       `json:"forfeit_package_session" db:"forfeit_package_session"`
+	IsDefault             bool       `json:"is_default"`
+	UpdatedAt             *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+type SetPolicyRequest struct {
+	MinNoticeHours        *int     `json:"min_notice_hours" binding:"required,min=0"`
+	MaxReschedules        *int     `json:"max_reschedules" binding:"required,min=0"`
+	LateCancellationFee   *float64 `json:"late_cancellation_fee" binding:"required,min=0"`
+	ForfeitPackageSession *bool    `json:"forfeit_package_session" binding:"required"`
+}
+
+// CancellationOutcome is the consequence of cancelling under a policy.
+type CancellationOutcome struct {
+	Late           bool    `json:"late"`
+	Fee            float64 `json:"fee"`
+	ForfeitSession bool    `json:"forfeit_session"`
+}
@@ -0,0 +1,81 @@
+package policies
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PoliciesHandler struct {
+	service *PolicyService
+}
+
+func NewPoliciesHandler(service *PolicyService) *PoliciesHandler {
+	return &PoliciesHandler{service: service}
+}
+
+// GetServicePolicy godoc
+// @Summary Get a service's cancellation policy
+// @Description Get the notice window, reschedule limit and late-cancellation consequences of a service
+// @Tags policies
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {object} Policy
+// @Failure 500 {object} map[string]interface{}
+// @Router /services/{id}/policy [get]
+func (h *PoliciesHandler) GetServicePolicy(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	policy, err := h.service.GetPolicy(serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetServicePolicy godoc
+// @Summary Set a service's cancellation policy
+// @Description Replace the cancellation and rescheduling rules of a service
+// @Tags policies
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param policy body SetPolicyRequest true "Policy"
+// @Success 200 {object} Policy
+// @Failure 400 {object} map[string]interface{}
+// @Router /services/{id}/policy [put]
+func (h *PoliciesHandler) SetServicePolicy(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	var req SetPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.service.SetPolicy(serviceID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func (h *PoliciesHandler) ResetServicePolicy(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	err := h.service.ResetPolicy(serviceID)
+	if err != nil {
+		if err.Error() == "policy not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Policy not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
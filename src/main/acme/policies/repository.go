@@ -0,0 +1,85 @@
+package policies
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// GetPolicy returns the stored policy of a service, or nil when it has none.
+func (r *Repository) GetPolicy(serviceID string) (*Policy, error) {
+	policy := &Policy{}
+	query := `
+		SELECT service_id, min_notice_hours, max_reschedules, late_cancellation_fee, forfeit_package_session, updated_at
+		FROM service_policies WHERE service_id = $1`
+
+	err := r.db.QueryRow(query, serviceID).Scan(
+		&policy.ServiceID,
+		&policy.MinNoticeHours,
+		&policy.MaxReschedules,
+		&policy.LateCancellationFee,
+		&policy.ForfeitPackageSession,
+		&policy.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting service policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+func (r *Repository) SetPolicy(policy *Policy) error {
+	query := `
+		INSERT INTO service_policies (service_id, min_notice_hours, max_reschedules, late_cancellation_fee, forfeit_package_session)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (service_id) DO UPDATE SET
+			min_notice_hours = EXCLUDED.min_notice_hours,
+			max_reschedules = EXCLUDED.max_reschedules,
+			late_cancellation_fee = EXCLUDED.late_cancellation_fee,
+			forfeit_package_session = EXCLUDED.forfeit_package_session
+		RETURNING updated_at`
+
+	err := r.db.QueryRow(
+		query,
+		policy.ServiceID,
+		policy.MinNoticeHours,
+		policy.MaxReschedules,
+		policy.LateCancellationFee,
+		policy.ForfeitPackageSession,
+	).Scan(&policy.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error saving service policy: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeletePolicy(serviceID string) error {
+	result, err := r.db.Exec(`DELETE FROM service_policies WHERE service_id = $1`, serviceID)
+	if err != nil {
+		return fmt.Errorf("error deleting service policy: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("policy not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package policies
+
+import (
+	"acme/config"
+	"fmt"
+	"time"
+)
+
+type PolicyService struct {
+	repo   *Repository
+	config *config.Config
+}
+
+func NewService(repo *Repository, cfg *config.Config) *PolicyService {
+	return &PolicyService{repo: repo, config: cfg}
+}
+
+// GetPolicy returns the service's policy, falling back to the defaults.
+func (s *PolicyService) GetPolicy(serviceID string) (*Policy, error) {
+	policy, err := s.repo.GetPolicy(serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil {
+		policy = &Policy{
+			ServiceID:             serviceID,
+			MinNoticeHours:        s.config.Reliability.LateCancellationHours,
+			MaxReschedules:        s.config.Scheduling.MaxReschedules,
+			ForfeitPackageSession: true,
+			IsDefault:             true,
+		}
+	}
+
+	return policy, nil
+}
+
+func (s *PolicyService) SetPolicy(serviceID string, req SetPolicyRequest) (*Policy, error) {
+	policy := &Policy{
+		ServiceID:             serviceID,
+		MinNoticeHours:        *req.MinNoticeHours,
+		MaxReschedules:        *req.MaxReschedules,
+		LateCancellationFee:   *req.LateCancellationFee,
+		ForfeitPackageSession: *req.ForfeitPackageSession,
+	}
+
+	if err := s.repo.SetPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ResetPolicy removes the service's own policy so the defaults apply again.
+func (s *PolicyService) ResetPolicy(serviceID string) error {
+	return s.repo.DeletePolicy(serviceID)
+}
+
+func (p *Policy) isLate(startsAt, now time.Time) bool {
+	return startsAt.Sub(now) < time.Duration(p.MinNoticeHours)*time.Hour
+}
+
+// EvaluateCancellation decides the consequences of a client cancelling an
+// appointment that starts at startsAt.
+func (p *Policy) EvaluateCancellation(startsAt, now time.Time) CancellationOutcome {
+	if !p.isLate(startsAt, now) {
+		return CancellationOutcome{}
+	}
+
+	return CancellationOutcome{
+		Late:           true,
+		Fee:            p.LateCancellationFee,
+		ForfeitSession: p.ForfeitPackageSession,
+	}
+}
+
+// CheckReschedule reports whether a client may move an appointment that
+// starts at startsAt and has already been rescheduled rescheduleCount times.
+func (p *Policy) CheckReschedule(startsAt, now time.Time, rescheduleCount int) error {
+	if rescheduleCount >= p.MaxReschedules {
+		return fmt.Errorf("appointment has reached the maximum of %d reschedules", p.MaxReschedules)
+	}
+
+	if p.isLate(startsAt, now) {
+		return fmt.Errorf("appointments can only be rescheduled with at least %d hours notice", p.MinNoticeHours)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package resources
+
+import (
+	"time"
+)
+
+type Resource struct {
+	ID           string    `json:"id" db:"id"`
+	Name         string    `json:"name" db:"name"`
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	Capacity     int       `json:"capacity" db:"capacity"`
+	Description  *string   `json:"description" db:"description"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateResourceRequest struct {
+	Name         string  `json:"name" binding:"required"`
+	ResourceType string  `json:"resource_type" binding:"required"`
+	Capacity     int     `json:"capacity" binding:"required,min=1"`
+	Description  *string `json:"description"`
+}
+
+type UpdateResourceRequest struct {
+	Name         *string `json:"name"`
+	ResourceType *string `json:"resource_type"`
+	Capacity     *int    `json:"capacity"`
+	Description  *string `json:"description"`
+}
+
+type ResourceType string
+
+const (
+	ResourceTypeRoom      ResourceType = "room"
+	ResourceTypeEquipment ResourceType = "equipment"
+)
+
+func (t ResourceType) IsValid() bool {
+	switch t {
+	case ResourceTypeRoom, ResourceTypeEquipment:
+		return true
+	}
+	return false
+}
+
+// Requirement is a resource a service needs reserved for its whole duration.
+type Requirement struct {
+	ServiceID    string `json:"service_id" db:"service_id"`
+	ResourceID   string `json:"resource_id" db:"resource_id"`
+	ResourceName string `json:"resource_name"`
+	Quantity     int    `json:"quantity" db:"quantity"`
+	Capacity     int    `json:"capacity"`
+}
+
+type SetRequirementRequest struct {
+	ResourceID string `json:"resource_id" binding:"required"`
+	Quantity   int    `json:"quantity" binding:"required,min=1"`
+}
@@ -0,0 +1,220 @@
+package resources
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) CreateResource(resource *Resource) error {
+	query := `
+		INSERT INTO resources (name, resource_type, capacity, description)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRow(
+		query,
+		resource.Name,
+		resource.ResourceType,
+		resource.Capacity,
+		resource.Description,
+	).Scan(
+		&resource.ID,
+		&resource.CreatedAt,
+		&resource.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error creating resource: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetResourceByID(id string) (*Resource, error) {
+	resource := &Resource{}
+	query := `
+		SELECT id, name, resource_type, capacity, description, created_at, updated_at
+		FROM resources WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&resource.ID,
+		&resource.Name,
+		&resource.ResourceType,
+		&resource.Capacity,
+		&resource.Description,
+		&resource.CreatedAt,
+		&resource.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("resource not found")
+		}
+		return nil, fmt.Errorf("error getting resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+func (r *Repository) GetAllResources() ([]Resource, error) {
+	query := `
+		SELECT id, name, resource_type, capacity, description, created_at, updated_at
+		FROM resources ORDER BY name ASC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []Resource
+	for rows.Next() {
+		var resource Resource
+		err := rows.Scan(
+			&resource.ID,
+			&resource.Name,
+			&resource.ResourceType,
+			&resource.Capacity,
+			&resource.Description,
+			&resource.CreatedAt,
+			&resource.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning resource: %w", err)
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+func (r *Repository) UpdateResource(id string, updates UpdateResourceRequest) error {
+	setParts := []string{}
+	args := []interface{}{}
+	argIndex := 1
+
+	if updates.Name != nil {
+		setParts = append(setParts, fmt.Sprintf("name = $%d", argIndex))
+		args = append(args, *updates.Name)
+		argIndex++
+	}
+	if updates.ResourceType != nil {
+		setParts = append(setParts, fmt.Sprintf("resource_type = $%d", argIndex))
+		args = append(args, *updates.ResourceType)
+		argIndex++
+	}
+	if updates.Capacity != nil {
+		setParts = append(setParts, fmt.Sprintf("capacity = $%d", argIndex))
+		args = append(args, *updates.Capacity)
+		argIndex++
+	}
+	if updates.Description != nil {
+		setParts = append(setParts, fmt.Sprintf("description = $%d", argIndex))
+		args = append(args, updates.Description)
+		argIndex++
+	}
+
+	if len(setParts) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	query := fmt.Sprintf("UPDATE resources SET %s WHERE id = $%d",
+		strings.Join(setParts, ", "), argIndex)
+	args = append(args, id)
+
+	_, err := r.db.Exec(query, args...)
+	return err
+}
+
+func (r *Repository) DeleteResource(id string) error {
+	query := `DELETE FROM resources WHERE id = $1`
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("error deleting resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("resource not found")
+	}
+
+	return nil
+}
+
+func (r *Repository) GetRequirementsByService(serviceID string) ([]Requirement, error) {
+	query := `
+		SELECT sr.service_id, sr.resource_id, r.name, sr.quantity, r.capacity
+		FROM service_resources sr
+		JOIN resources r ON sr.resource_id = r.id
+		WHERE sr.service_id = $1
+		ORDER BY r.name ASC`
+
+	rows, err := r.db.Query(query, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying service resources: %w", err)
+	}
+	defer rows.Close()
+
+	var requirements []Requirement
+	for rows.Next() {
+		var requirement Requirement
+		err := rows.Scan(
+			&requirement.ServiceID,
+			&requirement.ResourceID,
+			&requirement.ResourceName,
+			&requirement.Quantity,
+			&requirement.Capacity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning service resource: %w", err)
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+func (r *Repository) SetRequirement(serviceID, resourceID string, quantity int) error {
+	query := `
+		INSERT INTO service_resources (service_id, resource_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (service_id, resource_id) DO UPDATE SET quantity = EXCLUDED.quantity`
+
+	if _, err := r.db.Exec(query, serviceID, resourceID, quantity); err != nil {
+		return fmt.Errorf("error setting service resource: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) RemoveRequirement(serviceID, resourceID string) error {
+	query := `DELETE FROM service_resources WHERE service_id = $1 AND resource_id = $2`
+	result, err := r.db.Exec(query, serviceID, resourceID)
+	if err != nil {
+		return fmt.Errorf("error removing service resource: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("requirement not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package resources
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResourcesHandler struct {
+	service *ResourceService
+}
+
+func NewResourcesHandler(service *ResourceService) *ResourcesHandler {
+	return &ResourcesHandler{service: service}
+}
+
+// CreateResource godoc
+// @Summary Create a bookable resource
+// @Description Create a room or piece of equipment that services can require
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param resource body CreateResourceRequest true "Resource data"
+// @Success 201 {object} Resource
+// @Failure 400 {object} map[string]interface{}
+// @Router /resources [post]
+func (h *ResourcesHandler) CreateResource(c *gin.Context) {
+	var req CreateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := h.service.CreateResource(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, resource)
+}
+
+// GetAllResources godoc
+// @Summary Get all resources
+// @Description Get a list of all rooms and equipment
+// @Tags resources
+// @Produce json
+// @Success 200 {array} Resource
+// @Failure 500 {object} map[string]interface{}
+// @Router /resources [get]
+func (h *ResourcesHandler) GetAllResources(c *gin.Context) {
+	resources, err := h.service.GetAllResources()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resources)
+}
+
+func (h *ResourcesHandler) GetResourceByID(c *gin.Context) {
+	id := c.Param("id")
+
+	resource, err := h.service.GetResourceByID(id)
+	if err != nil {
+		if err.Error() == "resource not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+func (h *ResourcesHandler) UpdateResource(c *gin.Context) {
+	id := c.Param("id")
+
+	var req UpdateResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := h.service.UpdateResource(id, req)
+	if err != nil {
+		if err.Error() == "resource not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resource)
+}
+
+func (h *ResourcesHandler) DeleteResource(c *gin.Context) {
+	id := c.Param("id")
+
+	err := h.service.DeleteResource(id)
+	if err != nil {
+		if err.Error() == "resource not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetServiceRequirements godoc
+// @Summary Get resources required by a service
+// @Description List the rooms and equipment reserved whenever a service is booked
+// @Tags resources
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} Requirement
+// @Failure 500 {object} map[string]interface{}
+// @Router /services/{id}/resources [get]
+func (h *ResourcesHandler) GetServiceRequirements(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	requirements, err := h.service.GetServiceRequirements(serviceID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, requirements)
+}
+
+func (h *ResourcesHandler) SetServiceRequirement(c *gin.Context) {
+	serviceID := c.Param("id")
+
+	var req SetRequirementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requirements, err := h.service.SetServiceRequirement(serviceID, req)
+	if err != nil {
+		if err.Error() == "resource not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, requirements)
+}
+
+func (h *ResourcesHandler) RemoveServiceRequirement(c *gin.Context) {
+	serviceID := c.Param("id")
+	resourceID := c.Param("resource_id")
+
+	err := h.service.RemoveServiceRequirement(serviceID, resourceID)
+	if err != nil {
+		if err.Error() == "requirement not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Requirement not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
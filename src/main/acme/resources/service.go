@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"fmt"
+)
+
+type ResourceService struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *ResourceService {
+	return &ResourceService{repo: repo}
+}
+
+func (s *ResourceService) CreateResource(req CreateResourceRequest) (*Resource, error) {
+	if !ResourceType(req.ResourceType).IsValid() {
+		return nil, fmt.Errorf("invalid resource_type: %s", req.ResourceType)
+	}
+
+	resource := &Resource{
+		Name:         req.Name,
+		ResourceType: req.ResourceType,
+		Capacity:     req.Capacity,
+		Description:  req.Description,
+	}
+
+	if err := s.repo.CreateResource(resource); err != nil {
+		return nil, fmt.Errorf("error creating resource: %w", err)
+	}
+
+	return resource, nil
+}
+
+func (s *ResourceService) GetResourceByID(id string) (*Resource, error) {
+	return s.repo.GetResourceByID(id)
+}
+
+func (s *ResourceService) GetAllResources() ([]Resource, error) {
+	return s.repo.GetAllResources()
+}
+
+func (s *ResourceService) UpdateResource(id string, req UpdateResourceRequest) (*Resource, error) {
+	if req.ResourceType != nil && !ResourceType(*req.ResourceType).IsValid() {
+		return nil, fmt.Errorf("invalid resource_type: %s", *req.ResourceType)
+	}
+
+	if req.Capacity != nil && *req.Capacity < 1 {
+		return nil, fmt.Errorf("capacity must be at least 1")
+	}
+
+	if err := s.repo.UpdateResource(id, req); err != nil {
+		return nil, fmt.Errorf("error updating resource: %w", err)
+	}
+
+	return s.repo.GetResourceByID(id)
+}
+
+func (s *ResourceService) DeleteResource(id string) error {
+	return s.repo.DeleteResource(id)
+}
+
+// GetServiceRequirements returns the resources that must be reserved to
+// perform a service.
+func (s *ResourceService) GetServiceRequirements(serviceID string) ([]Requirement, error) {
+	return s.repo.GetRequirementsByService(serviceID)
+}
+
+func (s *ResourceService) SetServiceRequirement(serviceID string, req SetRequirementRequest) ([]Requirement, error) {
+	resource, err := s.repo.GetResourceByID(req.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Quantity > resource.Capacity {
+		return nil, fmt.Errorf("quantity exceeds the capacity of %s (%d)", resource.Name, resource.Capacity)
+	}
+
+	if err := s.repo.SetRequirement(serviceID, req.ResourceID, req.Quantity); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRequirementsByService(serviceID)
+}
+
+func (s *ResourceService) RemoveServiceRequirement(serviceID, resourceID string) error {
+	return s.repo.RemoveRequirement(serviceID, resourceID)
+}
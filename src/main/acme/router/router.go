@@ -15,8 +15,8 @@ func SetupRoutes(r *gin.Engine, handlers *catalog.AppHandlers, cfg *config.Confi
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH, HEAD")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Accept, X-Requested-With, Access-Control-Request-Method, Access-Control-Request-Headers")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Accept, X-Requested-With, Access-Control-Request-Method, Access-Control-Request-Headers, Idempotency-Key, If-Match, If-None-Match, Last-Event-ID, X-Chatbot-Key")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, Content-Type, Idempotent-Replayed, ETag")
 		c.Header("Access-Control-Max-Age", "86400")
 		
 		// Handle preflight OPTIONS requests
@@ -30,6 +30,9 @@ func SetupRoutes(r *gin.Engine, handlers *catalog.AppHandlers, cfg *config.Confi
 
 	api := r.Group("/api/v1")
 	{
+		// Replay retried mutations that carry an Idempotency-Key header
+		api.Use(handlers.Idempotency.Handle)
+
 		// RENIEC validation endpoint (for chatbot flow)
 		reniec := api.Group("/reniec")
 		{
@@ -43,6 +46,19 @@ func SetupRoutes(r *gin.Engine, handlers *catalog.AppHandlers, cfg *config.Confi
 			clients.GET("/:id", handlers.IAM.GetClientByID)
 			clients.PUT("/:id", handlers.IAM.UpdateClient)
 			clients.GET("/dni/:dni", handlers.IAM.GetClientByDNI)
+			clients.POST("/:id/packages", handlers.Packages.PurchasePackage)
+			clients.GET("/:id/packages", handlers.Packages.GetClientPackages)
+			clients.GET("/:id/packages/:client_package_id/usage", handlers.Packages.GetUsageHistory)
+			clients.GET("/:id/reliability", handlers.Appointments.GetClientReliability)
+			clients.PUT("/:id/reliability/override", handlers.Appointments.GrantReliabilityOverride)
+			clients.DELETE("/:id/reliability/override", handlers.Appointments.RevokeReliabilityOverride)
+			clients.GET("/:id/notifications", handlers.Notifications.GetClientNotifications)
+			clients.GET("/:id/treatment-history", handlers.Treatments.GetTreatmentHistory)
+			clients.GET("/:id/health-profile", handlers.Health.GetHealthProfile)
+			clients.PUT("/:id/health-profile", handlers.Health.DeclareHealthProfile)
+			clients.GET("/:id/health-screening", handlers.Health.ScreenClient)
+			clients.POST("/:id/calendar-feed", handlers.Calendar.CreateClientFeed)
+			clients.DELETE("/:id/calendar-feed", handlers.Calendar.RevokeClientFeed)
 		}
 
 		services := api.Group("/services")
@@ -53,26 +69,138 @@ func SetupRoutes(r *gin.Engine, handlers *catalog.AppHandlers, cfg *config.Confi
 			services.PUT("/:id", handlers.Catalog.UpdateService)
 			services.DELETE("/:id", handlers.Catalog.DeleteService)
 			services.GET("/price-range", handlers.Catalog.GetServicesByPriceRange)
+			services.GET("/:id/employees", handlers.Employees.GetEmployeesByService)
+			services.GET("/:id/resources", handlers.Resources.GetServiceRequirements)
+			services.POST("/:id/resources", handlers.Resources.SetServiceRequirement)
+			services.DELETE("/:id/resources/:resource_id", handlers.Resources.RemoveServiceRequirement)
+			services.GET("/:id/policy", handlers.Policies.GetServicePolicy)
+			services.PUT("/:id/policy", handlers.Policies.SetServicePolicy)
+			services.DELETE("/:id/policy", handlers.Policies.ResetServicePolicy)
+			services.GET("/:id/contraindications", handlers.Health.GetServiceContraindications)
+			services.PUT("/:id/contraindications", handlers.Health.SetServiceContraindications)
+			services.GET("/:id/consent-templates", handlers.Consent.GetConsentTemplates)
+			services.POST("/:id/consent-templates", handlers.Consent.CreateConsentTemplate)
 		}
 
 		employees := api.Group("/employees")
 		{
 			employees.GET("", handlers.Employees.GetAllEmployees)
 			employees.GET("/:id", handlers.Employees.GetEmployeeByID)
+			employees.GET("/:id/services", handlers.Employees.GetEmployeeSkills)
+			employees.POST("/:id/services", handlers.Employees.AddEmployeeSkill)
+			employees.DELETE("/:id/services/:service_id", handlers.Employees.RemoveEmployeeSkill)
+			employees.GET("/:id/work-schedule", handlers.Employees.GetWorkSchedule)
+			employees.PUT("/:id/work-schedule", handlers.Employees.SetWorkSchedule)
+			employees.GET("/:id/agenda", handlers.Appointments.GetDayAgenda)
+			employees.GET("/:id/agenda/week", handlers.Appointments.GetWeekAgenda)
+			employees.POST("/:id/absence/preview", handlers.Appointments.PreviewAbsence)
+			employees.POST("/:id/absence", handlers.Appointments.ApplyAbsence)
+			employees.POST("/:id/calendar-feed", handlers.Calendar.CreateEmployeeFeed)
+			employees.DELETE("/:id/calendar-feed", handlers.Calendar.RevokeEmployeeFeed)
+		}
+
+		resourcesGroup := api.Group("/resources")
+		{
+			resourcesGroup.POST("", handlers.Resources.CreateResource)
+			resourcesGroup.GET("", handlers.Resources.GetAllResources)
+			resourcesGroup.GET("/:id", handlers.Resources.GetResourceByID)
+			resourcesGroup.PUT("/:id", handlers.Resources.UpdateResource)
+			resourcesGroup.DELETE("/:id", handlers.Resources.DeleteResource)
+		}
+
+		packagesGroup := api.Group("/packages")
+		{
+			packagesGroup.POST("", handlers.Packages.CreatePackage)
+			packagesGroup.GET("", handlers.Packages.GetAllPackages)
+			packagesGroup.GET("/:id", handlers.Packages.GetPackageByID)
+			packagesGroup.PUT("/:id", handlers.Packages.UpdatePackage)
 		}
 
 		appointmentsGroup := api.Group("/appointments")
 		{
 			appointmentsGroup.POST("", handlers.Appointments.CreateAppointment)
+			appointmentsGroup.GET("", handlers.Appointments.ListAppointments)
 			appointmentsGroup.GET("/:id", handlers.Appointments.GetAppointmentByID)
 			appointmentsGroup.GET("/:id/details", handlers.Appointments.GetAppointmentWithDetails)
-			appointmentsGroup.PUT("/:id", handlers.Appointments.UpdateAppointment)
 			appointmentsGroup.PUT("/:id/cancel", handlers.Appointments.CancelAppointment)
 			appointmentsGroup.PUT("/:id/cancel-by-client", handlers.Appointments.CancelAppointmentByClient)
 			appointmentsGroup.PUT("/:id/cancel-by-employee", handlers.Appointments.CancelAppointmentByEmployee)
 			appointmentsGroup.GET("/date-range", handlers.Appointments.GetAppointmentsByDateRange)
 			appointmentsGroup.GET("/client/:client_id", handlers.Appointments.GetAppointmentsByClient)
 			appointmentsGroup.GET("/availability", handlers.Appointments.CheckAvailability)
+			appointmentsGroup.GET("/slots", handlers.Appointments.FindAvailableSlots)
+			appointmentsGroup.POST("/series", handlers.Appointments.CreateSeries)
+			appointmentsGroup.POST("/series/preview", handlers.Appointments.PreviewSeries)
+			appointmentsGroup.PUT("/:id/series", handlers.Appointments.UpdateSeries)
+			appointmentsGroup.PUT("/:id/cancel-series", handlers.Appointments.CancelSeries)
+			appointmentsGroup.PUT("/:id/confirm", handlers.Appointments.ConfirmAppointment)
+			appointmentsGroup.PUT("/:id/check-in", handlers.Appointments.CheckInAppointment)
+			appointmentsGroup.PUT("/:id/start", handlers.Appointments.StartAppointment)
+			appointmentsGroup.PUT("/:id/complete", handlers.Appointments.CompleteAppointment)
+			appointmentsGroup.PUT("/:id/no-show", handlers.Appointments.MarkNoShow)
+			appointmentsGroup.GET("/:id/history", handlers.Appointments.GetStatusHistory)
+			appointmentsGroup.POST("/:id/reschedule", handlers.Appointments.RescheduleAppointment)
+			appointmentsGroup.GET("/:id/reschedules", handlers.Appointments.GetReschedules)
+			appointmentsGroup.GET("/:id/reminders", handlers.Appointments.GetReminders)
+			appointmentsGroup.POST("/:id/action-links", handlers.Appointments.CreateActionLinks)
+			appointmentsGroup.GET("/:id/treatment-record", handlers.Treatments.GetTreatmentRecord)
+			appointmentsGroup.PUT("/:id/treatment-record", handlers.Treatments.SaveTreatmentRecord)
+			appointmentsGroup.GET("/:id/consents", handlers.Consent.GetAppointmentConsents)
+			appointmentsGroup.POST("/:id/consents", handlers.Consent.SignConsent)
+			appointmentsGroup.GET("/:id/calendar.ics", handlers.Calendar.GetAppointmentCalendar)
+			appointmentsGroup.POST("/holds", handlers.Appointments.CreateHold)
+			appointmentsGroup.GET("/holds/:id", handlers.Appointments.GetHold)
+			appointmentsGroup.DELETE("/holds/:id", handlers.Appointments.ReleaseHold)
+			appointmentsGroup.POST("/holds/:id/confirm", handlers.Appointments.ConfirmHold)
+		}
+
+		waitlist := api.Group("/waitlist")
+		{
+			waitlist.POST("", handlers.Appointments.CreateWaitlistEntry)
+			waitlist.GET("", handlers.Appointments.GetWaitlist)
+			waitlist.GET("/:id", handlers.Appointments.GetWaitlistEntry)
+			waitlist.DELETE("/:id", handlers.Appointments.CancelWaitlistEntry)
+			waitlist.POST("/:id/accept", handlers.Appointments.AcceptWaitlistOffer)
+			waitlist.POST("/:id/decline", handlers.Appointments.DeclineWaitlistOffer)
+		}
+
+		sessions := api.Group("/sessions")
+		{
+			sessions.POST("", handlers.Appointments.CreateSession)
+			sessions.GET("", handlers.Appointments.GetSessions)
+			sessions.GET("/:id", handlers.Appointments.GetSession)
+			sessions.GET("/:id/roster", handlers.Appointments.GetSessionRoster)
+			sessions.POST("/:id/enroll", handlers.Appointments.EnrollInSession)
+			sessions.PUT("/:id/cancel", handlers.Appointments.CancelSession)
+		}
+
+		visits := api.Group("/visits")
+		{
+			visits.POST("", handlers.Appointments.CreateVisit)
+			visits.GET("/:id", handlers.Appointments.GetVisit)
+			visits.PUT("/:id/cancel", handlers.Appointments.CancelVisit)
+		}
+
+		consents := api.Group("/consents")
+		{
+			consents.GET("/:id/document", handlers.Consent.GetConsentDocument)
+		}
+
+		calendarFeeds := api.Group("/calendar-feeds")
+		{
+			calendarFeeds.GET("/:token", handlers.Calendar.GetFeed)
+		}
+
+		appointmentActions := api.Group("/appointment-actions")
+		{
+			appointmentActions.GET("/:token", handlers.Appointments.PreviewActionLink)
+			appointmentActions.POST("/:token/confirm", handlers.Appointments.ConfirmByLink)
+			appointmentActions.POST("/:token/cancel", handlers.Appointments.CancelByLink)
+		}
+
+		eventStreams := api.Group("/events")
+		{
+			eventStreams.GET("/appointments", handlers.Events.StreamAppointments)
 		}
 	}
 
@@ -0,0 +1,52 @@
+package treatments
+
+import (
+	"time"
+)
+
+// TreatmentRecord is what a specialist recorded about one session. It can
+// be edited by the attending specialist until it locks, 24 hours after the
+// appointment is completed.
+type TreatmentRecord struct {
+	ID               string            `json:"id" db:"id"`
+	AppointmentID    string            `json:"appointment_id" db:"appointment_id"`
+	ClientID         string            `json:"client_id" db:"client_id"`
+	ServiceID        string            `json:"service_id" db:"service_id"`
+	ServiceName      string            `json:"service_name"`
+	SpecialistID     string            `json:"specialist_id" db:"specialist_id"`
+	SpecialistName   string            `json:"specialist_name"`
+	AppointmentDate  time.Time         `json:"appointment_date"`
+	StartTime        string            `json:"start_time"`
+	ProductsUsed     []string          `json:"products_used" db:"products_used"`
+	SkinObservations *string           `json:"skin_observations" db:"skin_observations"`
+	DeviceSettings   map[string]string `json:"device_settings" db:"device_settings"`
+	Recommendations  *string           `json:"recommendations" db:"recommendations"`
+	Notes            *string           `json:"notes" db:"notes"`
+	CompletedAt      *time.Time        `json:"completed_at"`
+	LocksAt          *time.Time        `json:"locks_at"`
+	Locked           bool              `json:"locked"`
+	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// SaveRecordRequest writes the whole record. SpecialistID identifies who is
+// writing and must be the appointment's specialist.
+type SaveRecordRequest struct {
+	SpecialistID     string   `json:"specialist_id" binding:"required"`
+	ProductsUsed     []string `json:"products_used"`
+	SkinObservations *string  `json:"skin_observations"`
+	// DeviceSettings holds machine parameters, e.g. {"device": "radiofrequency", "power": "40W"}.
+	DeviceSettings  map[string]string `json:"device_settings"`
+	Recommendations *string           `json:"recommendations"`
+	Notes           *string           `json:"notes"`
+}
+
+// appointmentInfo is what a record needs to know about its appointment.
+type appointmentInfo struct {
+	ClientID    string
+	ServiceID   string
+	AttendedBy  *string
+	Status      string
+	CompletedAt *time.Time
+	Locked      bool
+}
@@ -0,0 +1,183 @@
+package treatments
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// completedAt finds when an appointment was completed; records lock
+// lockHours after that.
+const completedAt = `
+		(SELECT MAX(h.created_at) FROM appointment_status_history h
+		 WHERE h.appointment_id = a.id AND h.to_status = 'completed')`
+
+// GetAppointmentInfo loads the appointment a record belongs to and whether
+// its record is locked.
+func (r *Repository) GetAppointmentInfo(appointmentID string, lockHours int) (*appointmentInfo, error) {
+	info := &appointmentInfo{}
+	query := `
+		SELECT a.client_id, a.service_id, a.attended_by, a.status, c.completed_at,
+		       COALESCE(c.completed_at + make_interval(hours => $2) <= CURRENT_TIMESTAMP, FALSE)
+		FROM appointments a
+		CROSS JOIN LATERAL (SELECT` + completedAt + ` AS completed_at) c
+		WHERE a.id = $1`
+
+	err := r.db.QueryRow(query, appointmentID, lockHours).Scan(
+		&info.ClientID,
+		&info.ServiceID,
+		&info.AttendedBy,
+		&info.Status,
+		&info.CompletedAt,
+		&info.Locked,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("appointment not found")
+		}
+		return nil, fmt.Errorf("error getting appointment: %w", err)
+	}
+
+	return info, nil
+}
+
+// SaveRecord creates the appointment's record or replaces its contents.
+func (r *Repository) SaveRecord(record *TreatmentRecord) error {
+	settings, err := json.Marshal(record.DeviceSettings)
+	if err != nil {
+		return fmt.Errorf("error encoding device settings: %w", err)
+	}
+
+	query := `
+		INSERT INTO treatment_records (appointment_id, specialist_id, products_used, skin_observations,
+		                               device_settings, recommendations, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (appointment_id) DO UPDATE SET
+			specialist_id = EXCLUDED.specialist_id,
+			products_used = EXCLUDED.products_used,
+			skin_observations = EXCLUDED.skin_observations,
+			device_settings = EXCLUDED.device_settings,
+			recommendations = EXCLUDED.recommendations,
+			notes = EXCLUDED.notes
+		RETURNING id`
+
+	err = r.db.QueryRow(
+		query,
+		record.AppointmentID,
+		record.SpecialistID,
+		pq.Array(record.ProductsUsed),
+		record.SkinObservations,
+		settings,
+		record.Recommendations,
+		record.Notes,
+	).Scan(&record.ID)
+	if err != nil {
+		return fmt.Errorf("error saving treatment record: %w", err)
+	}
+
+	return nil
+}
+
+const recordColumns = `
+		t.id, t.appointment_id, a.client_id, a.service_id, s.name, t.specialist_id,
+		CONCAT(e.name, ' ', e.paternal_surname), a.appointment_date, a.start_time,
+		t.products_used, t.skin_observations, t.device_settings, t.recommendations, t.notes,
+		c.completed_at, c.completed_at + make_interval(hours => $2),
+		COALESCE(c.completed_at + make_interval(hours => $2) <= CURRENT_TIMESTAMP, FALSE),
+		t.created_at, t.updated_at`
+
+const recordFrom = `
+		FROM treatment_records t
+		JOIN appointments a ON t.appointment_id = a.id
+		JOIN services s ON a.service_id = s.id
+		JOIN employees e ON t.specialist_id = e.id
+		CROSS JOIN LATERAL (SELECT` + completedAt + ` AS completed_at) c`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRecord(row rowScanner, record *TreatmentRecord) error {
+	var settings []byte
+	err := row.Scan(
+		&record.ID,
+		&record.AppointmentID,
+		&record.ClientID,
+		&record.ServiceID,
+		&record.ServiceName,
+		&record.SpecialistID,
+		&record.SpecialistName,
+		&record.AppointmentDate,
+		&record.StartTime,
+		pq.Array(&record.ProductsUsed),
+		&record.SkinObservations,
+		&settings,
+		&record.Recommendations,
+		&record.Notes,
+		&record.CompletedAt,
+		&record.LocksAt,
+		&record.Locked,
+		&record.CreatedAt,
+		&record.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(settings) > 0 {
+		if err := json.Unmarshal(settings, &record.DeviceSettings); err != nil {
+			return fmt.Errorf("error decoding device settings: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) GetRecordByAppointment(appointmentID string, lockHours int) (*TreatmentRecord, error) {
+	record := &TreatmentRecord{}
+	query := `SELECT` + recordColumns + recordFrom + ` WHERE t.appointment_id = $1`
+
+	err := scanRecord(r.db.QueryRow(query, appointmentID, lockHours), record)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("treatment record not found")
+		}
+		return nil, fmt.Errorf("error getting treatment record: %w", err)
+	}
+
+	return record, nil
+}
+
+// GetClientHistory returns a client's records from the oldest session to
+// the newest.
+func (r *Repository) GetClientHistory(clientID string, lockHours int) ([]TreatmentRecord, error) {
+	query := `SELECT` + recordColumns + recordFrom + `
+		WHERE a.client_id = $1
+		ORDER BY a.appointment_date ASC, a.start_time ASC`
+
+	rows, err := r.db.Query(query, clientID, lockHours)
+	if err != nil {
+		return nil, fmt.Errorf("error querying treatment history: %w", err)
+	}
+	defer rows.Close()
+
+	records := []TreatmentRecord{}
+	for rows.Next() {
+		var record TreatmentRecord
+		if err := scanRecord(rows, &record); err != nil {
+			return nil, fmt.Errorf("error scanning treatment record: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
@@ -0,0 +1,70 @@
+package treatments
+
+import (
+	"fmt"
+)
+
+// lockHours is how long after completion a record can still be edited.
+const lockHours = 24
+
+type TreatmentService struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *TreatmentService {
+	return &TreatmentService{repo: repo}
+}
+
+// SaveRecord writes the record of an appointment that has started. Only the
+// attending specialist may write it, and not once it is locked.
+func (s *TreatmentService) SaveRecord(appointmentID string, req SaveRecordRequest) (*TreatmentRecord, error) {
+	info, err := s.repo.GetAppointmentInfo(appointmentID, lockHours)
+	if err != nil {
+		return nil, err
+	}
+
+	switch info.Status {
+	case "checked_in", "in_progress", "completed":
+	default:
+		return nil, fmt.Errorf("treatment records can only be written once the client has checked in")
+	}
+
+	if info.AttendedBy == nil || *info.AttendedBy != req.SpecialistID {
+		return nil, fmt.Errorf("only the attending specialist can edit the treatment record")
+	}
+
+	if info.Locked {
+		return nil, fmt.Errorf("treatment record is locked")
+	}
+
+	record := &TreatmentRecord{
+		AppointmentID:    appointmentID,
+		SpecialistID:     req.SpecialistID,
+		ProductsUsed:     req.ProductsUsed,
+		SkinObservations: req.SkinObservations,
+		DeviceSettings:   req.DeviceSettings,
+		Recommendations:  req.Recommendations,
+		Notes:            req.Notes,
+	}
+
+	if record.ProductsUsed == nil {
+		record.ProductsUsed = []string{}
+	}
+	if record.DeviceSettings == nil {
+		record.DeviceSettings = map[string]string{}
+	}
+
+	if err := s.repo.SaveRecord(record); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetRecordByAppointment(appointmentID, lockHours)
+}
+
+func (s *TreatmentService) GetRecord(appointmentID string) (*TreatmentRecord, error) {
+	return s.repo.GetRecordByAppointment(appointmentID, lockHours)
+}
+
+func (s *TreatmentService) GetClientHistory(clientID string) ([]TreatmentRecord, error) {
+	return s.repo.GetClientHistory(clientID, lockHours)
+}
@@ -0,0 +1,102 @@
+package treatments
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TreatmentsHandler struct {
+	service *TreatmentService
+}
+
+func NewTreatmentsHandler(service *TreatmentService) *TreatmentsHandler {
+	return &TreatmentsHandler{service: service}
+}
+
+// SaveTreatmentRecord godoc
+// @Summary Write an appointment's treatment record
+// @Description Record products used, skin observations, device settings, recommendations and notes; only the attending specialist can write it, until 24 hours after completion
+// @Tags treatments
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param record body SaveRecordRequest true "Treatment record"
+// @Success 200 {object} TreatmentRecord
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /appointments/{id}/treatment-record [put]
+func (h *TreatmentsHandler) SaveTreatmentRecord(c *gin.Context) {
+	appointmentID := c.Param("id")
+
+	var req SaveRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	record, err := h.service.SaveRecord(appointmentID, req)
+	if err != nil {
+		switch err.Error() {
+		case "appointment not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appointment not found"})
+		case "only the attending specialist can edit the treatment record":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "treatment record is locked":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// GetTreatmentRecord godoc
+// @Summary Get an appointment's treatment record
+// @Description Get the record of a session and whether it is locked
+// @Tags treatments
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} TreatmentRecord
+// @Failure 404 {object} map[string]interface{}
+// @Router /appointments/{id}/treatment-record [get]
+func (h *TreatmentsHandler) GetTreatmentRecord(c *gin.Context) {
+	appointmentID := c.Param("id")
+
+	record, err := h.service.GetRecord(appointmentID)
+	if err != nil {
+		if err.Error() == "treatment record not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Treatment record not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// GetTreatmentHistory godoc
+// @Summary Get a client's treatment history
+// @Description List a client's treatment records from the first session to the latest
+// @Tags treatments
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {array} TreatmentRecord
+// @Failure 500 {object} map[string]interface{}
+// @Router /clients/{id}/treatment-history [get]
+func (h *TreatmentsHandler) GetTreatmentHistory(c *gin.Context) {
+	clientID := c.Param("id")
+
+	records, err := h.service.GetClientHistory(clientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}